@@ -14,6 +14,7 @@ import (
 	"social_media/internal/service"
 	"social_media/internal/handler"
 	"social_media/pkg/jwt"
+	"social_media/pkg/linkpreview"
 	"social_media/router"
 )
 
@@ -55,6 +56,20 @@ func main() {
 	roomRepo := repository.NewRoomRepository(pool)
 	roomMembershipRepo := repository.NewRoomMembershipRepository(pool)
 	roomMessageRepo := repository.NewRoomMessageRepository(pool)
+	messageSearchRepo := repository.NewMessageSearchRepository(pool)
+	roomInviteRepo := repository.NewRoomInviteRepository(pool)
+	roomJoinRequestRepo := repository.NewRoomJoinRequestRepository(pool)
+	notificationRepo := repository.NewNotificationRepository(pool)
+	roomAuditRepo := repository.NewRoomAuditRepository(pool)
+	roomRestrictionRepo := repository.NewRoomRestrictionRepository(pool)
+	blockRepo := repository.NewBlockRepository(pool)
+	contactRepo := repository.NewContactRepository(pool)
+	chatStateRepo := repository.NewChatStateRepository(pool)
+	chatFolderRepo := repository.NewChatFolderRepository(pool)
+	pinRepo := repository.NewPinRepository(pool)
+	bookmarkRepo := repository.NewBookmarkRepository(pool)
+	linkPreviewRepo := repository.NewLinkPreviewRepository(pool)
+	pollRepo := repository.NewPollRepository(pool)
 
 	// Initialize the JWT Manager.
 	jwtManager := jwt.NewJWTManager(jwtSecret, time.Hour*24) // Token valid for 24 hours.
@@ -62,17 +77,72 @@ func main() {
 	// Initialize services.
 	authService := service.NewAuthService(userRepo, jwtManager)
 	profileService := service.NewProfileService(userRepo)
-	convoService := service.NewConversationService(convoRepo, messageRepo, userRepo)
-	roomService := service.NewRoomService(roomRepo, roomMembershipRepo, roomMessageRepo)
+	userService := service.NewUserService(userRepo, contactRepo, blockRepo)
+	convoService := service.NewConversationService(convoRepo, messageRepo, userRepo, blockRepo, contactRepo)
+	roomService := service.NewRoomService(roomRepo, roomMembershipRepo, roomMessageRepo, userRepo, roomAuditRepo, roomRestrictionRepo, contactRepo, notificationRepo, chatStateRepo)
+	searchService := service.NewSearchService(messageSearchRepo)
+	inviteService := service.NewInviteService(roomRepo, roomMembershipRepo, roomRestrictionRepo, roomInviteRepo, roomJoinRequestRepo, roomAuditRepo)
+	joinRequestService := service.NewJoinRequestService(roomRepo, roomMembershipRepo, roomRestrictionRepo, roomJoinRequestRepo, notificationRepo, chatStateRepo, roomService)
+	notificationService := service.NewNotificationService(notificationRepo)
+	auditService := service.NewAuditService(roomMembershipRepo, roomAuditRepo)
+	blockService := service.NewBlockService(userRepo, blockRepo)
+	contactService := service.NewContactService(userRepo, contactRepo, blockRepo)
+	chatService := service.NewChatService(convoRepo, roomRepo, roomMembershipRepo, roomRestrictionRepo, chatStateRepo, chatFolderRepo)
+	forwardService := service.NewForwardService(convoRepo, messageRepo, roomRepo, roomMembershipRepo, roomRestrictionRepo, roomMessageRepo, convoService, roomService)
+	pinService := service.NewPinService(convoRepo, messageRepo, roomRepo, roomMembershipRepo, roomRestrictionRepo, roomMessageRepo, roomAuditRepo, pinRepo)
+	bookmarkService := service.NewBookmarkService(convoRepo, messageRepo, roomRepo, roomMembershipRepo, roomRestrictionRepo, roomMessageRepo, bookmarkRepo)
+	linkPreviewService := service.NewLinkPreviewService(messageRepo, roomMessageRepo, linkPreviewRepo, linkpreview.NewFetcher())
+	pollService := service.NewPollService(roomRepo, roomMembershipRepo, roomRestrictionRepo, roomMessageRepo, pollRepo, roomService)
+
+	// Lift expired bans and mutes and close polls past their deadline in the background;
+	// both are also treated as expired when checked.
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			if lifted, err := roomService.ExpireRestrictions(); err != nil {
+				log.Printf("Failed to lift expired room restrictions: %v", err)
+			} else if lifted > 0 {
+				log.Printf("Lifted %d expired room restrictions", lifted)
+			}
+			if _, err := pollService.CloseExpired(); err != nil {
+				log.Printf("Failed to close expired polls: %v", err)
+			}
+		}
+	}()
+
+	// Generate previews for links in new messages in the background.
+	go func() {
+		ticker := time.NewTicker(2 * time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := linkPreviewService.GeneratePending(); err != nil {
+				log.Printf("Failed to generate link previews: %v", err)
+			}
+		}
+	}()
 
 	// Initialize handlers.
 	authHandler := handler.NewAuthHandler(authService)
 	profileHandler := handler.NewProfileHandler(profileService)
+	userHandler := handler.NewUserHandler(userService)
 	convoHandler := handler.NewConversationHandler(convoService)
 	roomHandler := handler.NewRoomHandler(roomService)
+	searchHandler := handler.NewSearchHandler(searchService)
+	inviteHandler := handler.NewInviteHandler(inviteService)
+	joinRequestHandler := handler.NewJoinRequestHandler(joinRequestService)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	auditHandler := handler.NewAuditHandler(auditService)
+	blockHandler := handler.NewBlockHandler(blockService)
+	contactHandler := handler.NewContactHandler(contactService)
+	chatHandler := handler.NewChatHandler(chatService)
+	pinHandler := handler.NewPinHandler(pinService)
+	forwardHandler := handler.NewForwardHandler(forwardService)
+	bookmarkHandler := handler.NewBookmarkHandler(bookmarkService)
+	pollHandler := handler.NewPollHandler(pollService)
 
 	// Setup the router with public and protected endpoints.
-	r := router.SetupRouter(authHandler, profileHandler, convoHandler, roomHandler, jwtManager)
+	r := router.SetupRouter(authHandler, profileHandler, userHandler, convoHandler, roomHandler, searchHandler, inviteHandler, joinRequestHandler, notificationHandler, auditHandler, blockHandler, contactHandler, chatHandler, pinHandler, forwardHandler, bookmarkHandler, pollHandler, jwtManager)
 
 	// Start the server.
 	log.Printf("Server starting on port %s...", appPort)
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditAction identifies the kind of moderation action recorded in a room's audit log.
+type AuditAction string
+
+const (
+	AuditBan               AuditAction = "ban"
+	AuditUnban             AuditAction = "unban"
+	AuditMute              AuditAction = "mute"
+	AuditUnmute            AuditAction = "unmute"
+	AuditRemove            AuditAction = "remove"
+	AuditPromote           AuditAction = "promote"
+	AuditDemote            AuditAction = "demote"
+	AuditUpdatePermissions AuditAction = "update_permissions"
+	AuditTransferOwnership AuditAction = "transfer_ownership"
+	AuditUpdateRoom        AuditAction = "update_room"
+	AuditDeleteMessage     AuditAction = "delete_message"
+	AuditPinMessage        AuditAction = "pin_message"
+	AuditUnpinMessage      AuditAction = "unpin_message"
+	AuditCreateInvite      AuditAction = "create_invite"
+	AuditRevokeInvite      AuditAction = "revoke_invite"
+)
+
+// RoomAuditEntry is an append-only record of a moderation action.
+type RoomAuditEntry struct {
+	ID        string          `json:"id"`
+	RoomID    string          `json:"room_id"`
+	ActorID   string          `json:"actor_id"`
+	Action    AuditAction     `json:"action"`
+	TargetID  *string         `json:"target_id,omitempty"` // user, message or invite the action applied to
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// RoomAuditFilter narrows an audit log query. Empty fields and nil times are ignored.
+type RoomAuditFilter struct {
+	ActorID string
+	Action  AuditAction
+	From    *time.Time // inclusive
+	To      *time.Time // exclusive
+	Limit   int
+	Offset  int
+}
+
+// RoomAuditRepository stores the moderation audit log. Entries are never updated or deleted.
+type RoomAuditRepository interface {
+	Append(entry *RoomAuditEntry) error
+	// Find returns the room's entries matching filter, newest first.
+	Find(roomID string, filter RoomAuditFilter) ([]*RoomAuditEntry, error)
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// UserBlock records that BlockerID no longer accepts direct messages from BlockedID.
+type UserBlock struct {
+	BlockerID string    `json:"blocker_id"`
+	BlockedID string    `json:"blocked_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BlockRepository defines persistence operations for user blocks.
+type BlockRepository interface {
+	// Block is idempotent; blocking an already blocked user is not an error.
+	Block(block *UserBlock) error
+	Unblock(blockerID, blockedID string) error
+	IsBlocked(blockerID, blockedID string) (bool, error)
+	// FindByBlocker returns the users blocked by blockerID, most recent first.
+	FindByBlocker(blockerID string) ([]*UserBlock, error)
+}
@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+// MessageSnapshot is a copy of a message taken when it was bookmarked.
+type MessageSnapshot struct {
+	SenderID      string         `json:"sender_id"`
+	Content       string         `json:"content"`
+	ForwardedFrom *ForwardOrigin `json:"forwarded_from,omitempty"`
+	SentAt        time.Time      `json:"sent_at"`
+}
+
+// Bookmark is a message a user saved to their "Saved messages". The snapshot
+// keeps the bookmark readable after the original is edited or deleted.
+type Bookmark struct {
+	ID        string          `json:"id"`
+	UserID    string          `json:"-"`
+	Source    ChatRef         `json:"source"`
+	MessageID string          `json:"message_id"`
+	Snapshot  MessageSnapshot `json:"snapshot"`
+	Note      string          `json:"note,omitempty"`
+	Tags      []string        `json:"tags"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// BookmarkFilter narrows a bookmark listing. Empty fields are ignored.
+type BookmarkFilter struct {
+	Tag        string
+	SourceKind ChatKind
+	SourceID   string
+	Limit      int
+	Offset     int
+}
+
+// BookmarkRepository defines persistence operations for bookmarks.
+type BookmarkRepository interface {
+	Create(bookmark *Bookmark) error
+	// Update replaces the note and tags.
+	Update(bookmark *Bookmark) error
+	Delete(bookmarkID string) error
+	FindByID(bookmarkID string) (*Bookmark, error)
+	// FindByMessage returns nil if the user has not bookmarked the message.
+	FindByMessage(userID string, source ChatRef, messageID string) (*Bookmark, error)
+	// FindByUser returns the user's bookmarks matching filter, newest first.
+	FindByUser(userID string, filter BookmarkFilter) ([]*Bookmark, error)
+}
@@ -0,0 +1,156 @@
+package domain
+
+import "time"
+
+// ChatKind identifies which table a chat lives in.
+type ChatKind string
+
+const (
+	ChatConversation ChatKind = "conversation"
+	ChatRoom         ChatKind = "room"
+)
+
+// Valid reports whether k is a known chat kind.
+func (k ChatKind) Valid() bool {
+	return k == ChatConversation || k == ChatRoom
+}
+
+// ChatRef points at a conversation or a room.
+type ChatRef struct {
+	Kind ChatKind `json:"kind"`
+	ID   string   `json:"id"`
+}
+
+// ChatCategory is the kind of chat folder rules include.
+type ChatCategory string
+
+const (
+	ChatCategoryDirect  ChatCategory = "direct"  // one-to-one conversations
+	ChatCategoryGroup   ChatCategory = "group"   // group conversations and group rooms
+	ChatCategoryChannel ChatCategory = "channel" // channel rooms
+)
+
+// MutedForever is the MutedUntil value of a chat muted with no end date.
+var MutedForever = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// ChatState is one user's organization of a single chat.
+type ChatState struct {
+	UserID     string     `json:"-"`
+	Chat       ChatRef    `json:"-"`
+	Archived   bool       `json:"archived"`
+	PinOrder   *int       `json:"pin_order,omitempty"`   // pinned chats are listed first, lowest order on top
+	MutedUntil *time.Time `json:"muted_until,omitempty"` // notifications are suppressed until then
+	LastReadAt *time.Time `json:"last_read_at,omitempty"`
+}
+
+// Muted reports whether the chat is muted at the given time.
+func (s *ChatState) Muted(now time.Time) bool {
+	return s.MutedUntil != nil && now.Before(*s.MutedUntil)
+}
+
+// ChatFacts are the properties of a chat that folder rules match against.
+type ChatFacts struct {
+	Chat     ChatRef
+	Category ChatCategory
+	Archived bool
+	Muted    bool
+	Unread   bool
+}
+
+// ChatFolderRules decide which chats a folder shows. Explicitly excluded chats are
+// never shown and explicitly included chats always are; other chats must be in an
+// included category and pass every exclude filter.
+type ChatFolderRules struct {
+	IncludeDirect   bool      `json:"include_direct"`
+	IncludeGroups   bool      `json:"include_groups"`
+	IncludeChannels bool      `json:"include_channels"`
+	ExcludeMuted    bool      `json:"exclude_muted"`
+	ExcludeRead     bool      `json:"exclude_read"`
+	ExcludeArchived bool      `json:"exclude_archived"`
+	IncludedChats   []ChatRef `json:"included_chats,omitempty"`
+	ExcludedChats   []ChatRef `json:"excluded_chats,omitempty"`
+}
+
+// Matches reports whether a chat belongs in the folder.
+func (r ChatFolderRules) Matches(chat ChatFacts) bool {
+	for _, ref := range r.ExcludedChats {
+		if ref == chat.Chat {
+			return false
+		}
+	}
+	for _, ref := range r.IncludedChats {
+		if ref == chat.Chat {
+			return true
+		}
+	}
+	switch chat.Category {
+	case ChatCategoryDirect:
+		if !r.IncludeDirect {
+			return false
+		}
+	case ChatCategoryGroup:
+		if !r.IncludeGroups {
+			return false
+		}
+	case ChatCategoryChannel:
+		if !r.IncludeChannels {
+			return false
+		}
+	}
+	return !(r.ExcludeMuted && chat.Muted) &&
+		!(r.ExcludeRead && !chat.Unread) &&
+		!(r.ExcludeArchived && chat.Archived)
+}
+
+// Empty reports whether the rules can never match a chat.
+func (r ChatFolderRules) Empty() bool {
+	return !r.IncludeDirect && !r.IncludeGroups && !r.IncludeChannels && len(r.IncludedChats) == 0
+}
+
+// ChatFolder is a user-defined filter over their chat list, e.g. "Unread groups".
+type ChatFolder struct {
+	ID        string          `json:"id"`
+	UserID    string          `json:"-"`
+	Name      string          `json:"name"`
+	Rules     ChatFolderRules `json:"rules"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// ConversationListItem is a conversation in the user's chat list along with their state for it.
+type ConversationListItem struct {
+	*Conversation
+	ChatState
+	Unread bool `json:"unread"`
+}
+
+// RoomListItem is a room in the user's chat list along with their state for it.
+type RoomListItem struct {
+	*Room
+	ChatState
+	Unread         bool `json:"unread"`
+	UnreadMentions int  `json:"unread_mentions"`
+}
+
+// ChatStateRepository defines persistence operations for per-user chat state.
+type ChatStateRepository interface {
+	// Get returns nil if the user has no state for the chat.
+	Get(userID string, chat ChatRef) (*ChatState, error)
+	// Save inserts or replaces the state.
+	Save(state *ChatState) error
+	FindByUser(userID string) ([]*ChatState, error)
+	// FindUnread returns the user's chats with messages from others newer than their last read time.
+	FindUnread(userID string) ([]ChatRef, error)
+	// FindMuted returns which of userIDs have the chat muted at now.
+	FindMuted(chat ChatRef, userIDs []string, now time.Time) ([]string, error)
+	// CountUnreadMentions returns, per room ID, how many mentions of the user arrived after their last read time.
+	CountUnreadMentions(userID string) (map[string]int, error)
+}
+
+// ChatFolderRepository defines persistence operations for chat folders.
+type ChatFolderRepository interface {
+	Create(folder *ChatFolder) error
+	Update(folder *ChatFolder) error
+	Delete(folderID string) error
+	FindByID(folderID string) (*ChatFolder, error)
+	FindByUser(userID string) ([]*ChatFolder, error)
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// phoneHashSalt is prepended to phone numbers before hashing. It must match
+// the users.phone_hash generated column and is shared with client apps.
+const phoneHashSalt = "social_media:"
+
+// HashPhone returns the salted SHA-256 hex digest clients send instead of a raw phone number.
+func HashPhone(phone string) string {
+	sum := sha256.Sum256([]byte(phoneHashSalt + phone))
+	return hex.EncodeToString(sum[:])
+}
+
+// Contact is a user saved to OwnerID's contact list.
+// Name and Username are the contact's own profile fields; DisplayName is the owner's label for them.
+type Contact struct {
+	OwnerID     string    `json:"-"`
+	UserID      string    `json:"user_id"`
+	DisplayName *string   `json:"display_name,omitempty"`
+	Name        string    `json:"name"`
+	Username    *string   `json:"username,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ImportedContact is a registered user matched during a phone-book import.
+// Exactly one of Phone and PhoneHash echoes the identifier the client sent.
+type ImportedContact struct {
+	Phone     string         `json:"phone,omitempty"`
+	PhoneHash string         `json:"phone_hash,omitempty"`
+	User      *PublicProfile `json:"user"`
+}
+
+// ContactRepository defines persistence operations for contact lists.
+type ContactRepository interface {
+	// Save adds the contact or updates its display name if it already exists.
+	Save(contact *Contact) error
+	Delete(ownerID, userID string) error
+	IsContact(ownerID, userID string) (bool, error)
+	// FindByOwner returns ownerID's contacts ordered by display name, falling back to the contact's name.
+	FindByOwner(ownerID string) ([]*Contact, error)
+}
@@ -2,21 +2,64 @@ package domain
 
 import "time"
 
-// Conversation represents a conversation between two users.
+// ConversationStatus distinguishes accepted conversations from pending message requests.
+type ConversationStatus string
+
+const (
+	ConversationActive ConversationStatus = "active"
+	// ConversationRequest is a first contact from a non-contact awaiting the recipient's decision.
+	ConversationRequest ConversationStatus = "request"
+)
+
+// ConversationKind distinguishes one-to-one conversations from small group chats.
+type ConversationKind string
+
+const (
+	ConversationDirect ConversationKind = "direct"
+	ConversationGroup  ConversationKind = "group"
+)
+
+// MaxGroupConversationParticipants caps group conversations; larger chats should be rooms.
+const MaxGroupConversationParticipants = 10
+
+// Conversation represents a direct conversation between two users or a group conversation.
+// Participant1 and Participant2 are only set for direct conversations, ordered lexicographically.
 type Conversation struct {
-	ID           string    `gorm:"type:uuid;primaryKey" json:"id"`
-	Participant1 string    `gorm:"type:uuid;not null" json:"participant1"`
-	Participant2 string    `gorm:"type:uuid;not null" json:"participant2"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID           string             `gorm:"type:uuid;primaryKey" json:"id"`
+	Kind         ConversationKind   `gorm:"not null;default:direct" json:"kind"`
+	Title        *string            `json:"title,omitempty"` // group conversations only
+	Participant1 string             `gorm:"type:uuid" json:"participant1,omitempty"`
+	Participant2 string             `gorm:"type:uuid" json:"participant2,omitempty"`
+	Participants []string           `gorm:"-" json:"participants"` // loaded from conversation_participants
+	Status       ConversationStatus `gorm:"not null;default:active" json:"status"`
+	InitiatorID  *string            `gorm:"type:uuid" json:"initiator_id,omitempty"` // who sent the first message; unset for older conversations
+	CreatedAt    time.Time          `json:"created_at"`
 }
 
-
+// HasParticipant reports whether userID takes part in the conversation.
+func (c *Conversation) HasParticipant(userID string) bool {
+	for _, p := range c.Participants {
+		if p == userID {
+			return true
+		}
+	}
+	return false
+}
 
 // ConversationRepository defines the methods for conversation persistence.
 type ConversationRepository interface {
+	// Create stores the conversation together with its Participants.
 	Create(convo *Conversation) error
+	AddParticipant(convoID, userID string) error
+	RemoveParticipant(convoID, userID string) error
+	UpdateStatus(convo *Conversation) error
+	Delete(id string) error
 	FindByParticipants(p1, p2 string) (*Conversation, error)
+	// FindByUser returns the user's conversations, including message requests they sent
+	// but not ones they received.
 	FindByUser(userID string) ([]*Conversation, error)
+	// FindRequests returns the pending message requests sent to userID, newest first.
+	FindRequests(userID string) ([]*Conversation, error)
 	FindByID(id string) (*Conversation, error)
 }
 
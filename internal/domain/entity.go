@@ -0,0 +1,105 @@
+package domain
+
+import "unicode"
+
+// EntityType identifies what a message entity marks.
+type EntityType string
+
+const (
+	EntityMention       EntityType = "mention"        // @username of a room member; UserID is set
+	EntityMentionAdmins EntityType = "mention_admins" // @admins
+	EntityMentionAll    EntityType = "mention_all"    // @all
+
+	EntityBold     EntityType = "bold"
+	EntityItalic   EntityType = "italic"
+	EntityCode     EntityType = "code" // inline monospace
+	EntityPre      EntityType = "pre"  // monospace block
+	EntityTextLink EntityType = "text_link"
+	EntitySpoiler  EntityType = "spoiler"
+)
+
+// IsFormatting reports whether the entity is rich-text formatting supplied by
+// the sender, as opposed to a mention the server resolves.
+func (t EntityType) IsFormatting() bool {
+	switch t {
+	case EntityBold, EntityItalic, EntityCode, EntityPre, EntityTextLink, EntitySpoiler:
+		return true
+	}
+	return false
+}
+
+// IsMonospace reports whether the entity shows its text verbatim, so it cannot
+// overlap other entities.
+func (t EntityType) IsMonospace() bool {
+	return t == EntityCode || t == EntityPre
+}
+
+// MessageEntity marks a span of message text. Offset and Length count UTF-16
+// code units, the way clients index strings.
+type MessageEntity struct {
+	Type   EntityType `json:"type"`
+	Offset int        `json:"offset"`
+	Length int        `json:"length"`
+	UserID *string    `json:"user_id,omitempty"` // mentioned user
+	URL    string     `json:"url,omitempty"`     // text_link target
+}
+
+// Mention keywords that address a group of room members rather than one user.
+const (
+	MentionAdmins = "@admins"
+	MentionAll    = "@all"
+)
+
+// MentionMatch is an @name occurrence found in message text.
+type MentionMatch struct {
+	Username string // including the leading '@'
+	Offset   int    // in UTF-16 code units
+	Length   int    // in UTF-16 code units
+}
+
+// FindMentions returns the @name occurrences in text. A name is made of letters,
+// digits and underscores, and the '@' must not follow one of those, so e-mail
+// addresses are not mentions.
+func FindMentions(text string) []MentionMatch {
+	var matches []MentionMatch
+	runes := []rune(text)
+	offset := 0
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '@' && (i == 0 || !isMentionRune(runes[i-1])) {
+			end := i + 1
+			for end < len(runes) && isMentionRune(runes[end]) {
+				end++
+			}
+			if end > i+1 {
+				length := utf16Len(runes[i:end])
+				matches = append(matches, MentionMatch{
+					Username: string(runes[i:end]),
+					Offset:   offset,
+					Length:   length,
+				})
+				offset += length
+				i = end - 1
+				continue
+			}
+		}
+		offset += utf16Len(runes[i : i+1])
+	}
+	return matches
+}
+
+func isMentionRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// utf16Len returns the length of runes in UTF-16 code units.
+func utf16Len(runes []rune) int {
+	n := 0
+	for _, r := range runes {
+		if r >= 0x10000 {
+			n += 2 // encoded as a surrogate pair
+		} else {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,71 @@
+package domain
+
+import "time"
+
+// RoomInvite is a shareable link that lets users join a room.
+type RoomInvite struct {
+	Token            string     `json:"token"`
+	RoomID           string     `json:"room_id"`
+	CreatorID        string     `json:"creator_id"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"` // nil never expires
+	MaxUses          *int       `json:"max_uses,omitempty"`   // nil is unlimited
+	UseCount         int        `json:"use_count"`
+	RequiresApproval bool       `json:"requires_approval"`
+	Revoked          bool       `json:"revoked"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// IsUsable reports whether the invite can still be redeemed at the given time.
+func (i *RoomInvite) IsUsable(now time.Time) bool {
+	if i.Revoked {
+		return false
+	}
+	if i.ExpiresAt != nil && !now.Before(*i.ExpiresAt) {
+		return false
+	}
+	if i.MaxUses != nil && i.UseCount >= *i.MaxUses {
+		return false
+	}
+	return true
+}
+
+// JoinRequestStatus defines the state of a join request.
+type JoinRequestStatus string
+
+const (
+	JoinRequestPending  JoinRequestStatus = "pending"
+	JoinRequestApproved JoinRequestStatus = "approved"
+	JoinRequestDeclined JoinRequestStatus = "declined"
+)
+
+// RoomJoinRequest is a user's request to join a room, decided by an owner or admin.
+type RoomJoinRequest struct {
+	ID          string            `json:"id"`
+	RoomID      string            `json:"room_id"`
+	UserID      string            `json:"user_id"`
+	InviteToken *string           `json:"invite_token,omitempty"`
+	Message     string            `json:"message"`
+	Status      JoinRequestStatus `json:"status"`
+	DecidedBy   *string           `json:"decided_by,omitempty"`
+	DecidedAt   *time.Time        `json:"decided_at,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+type RoomInviteRepository interface {
+	Create(invite *RoomInvite) error
+	FindByToken(token string) (*RoomInvite, error)
+	FindByRoom(roomID string) ([]*RoomInvite, error)
+	Revoke(token string) error
+	// Redeem atomically consumes one use of the invite. It returns false if the
+	// invite was revoked, expired or exhausted in the meantime.
+	Redeem(token string, now time.Time) (bool, error)
+}
+
+type RoomJoinRequestRepository interface {
+	Create(request *RoomJoinRequest) error
+	FindByID(id string) (*RoomJoinRequest, error)
+	FindPending(roomID, userID string) (*RoomJoinRequest, error)
+	FindPendingByRoom(roomID string) ([]*RoomJoinRequest, error)
+	// UpdateStatus records the decision on a request.
+	UpdateStatus(request *RoomJoinRequest) error
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// LinkPreview is the card shown under a message that contains a link, built from
+// the page's OpenGraph or HTML meta tags.
+type LinkPreview struct {
+	URL         string    `json:"url"`
+	Title       string    `json:"title,omitempty"`
+	Description string    `json:"description,omitempty"`
+	ImageURL    string    `json:"image_url,omitempty"`
+	SiteName    string    `json:"site_name,omitempty"`
+	FetchedAt   time.Time `json:"-"`
+}
+
+// IsEmpty reports whether the page had nothing worth showing. Failed fetches are
+// cached as empty previews so they are not retried on every message.
+func (p *LinkPreview) IsEmpty() bool {
+	return p.Title == "" && p.Description == "" && p.ImageURL == ""
+}
+
+// LinkPreviewRepository caches previews by URL.
+type LinkPreviewRepository interface {
+	FindByURL(url string) (*LinkPreview, error)
+	// Save inserts the preview or replaces the cached one for its URL.
+	Save(preview *LinkPreview) error
+}
+
+// MaxPreviewURLLength is the longest link, in bytes, that gets a preview. Longer
+// links are left without one.
+const MaxPreviewURLLength = 2048
+
+var linkPattern = regexp.MustCompile(`https?://[^\s<>"]+`)
+
+// PreviewURL returns the link a message should be previewed for: the first web
+// address in text, or else the first text link. It returns "" when there is none.
+// Links longer than MaxPreviewURLLength are skipped.
+func PreviewURL(text string, entities []MessageEntity) string {
+	for _, match := range linkPattern.FindAllString(text, -1) {
+		// Punctuation that ends a sentence is not part of the link.
+		if link := strings.TrimRight(match, ".,;:!?'\")]}"); isWebURL(link) {
+			return link
+		}
+	}
+	for _, entity := range entities {
+		if entity.Type == EntityTextLink && isWebURL(entity.URL) {
+			return entity.URL
+		}
+	}
+	return ""
+}
+
+func isWebURL(raw string) bool {
+	if len(raw) > MaxPreviewURLLength {
+		return false
+	}
+	link, err := url.Parse(raw)
+	return err == nil && (link.Scheme == "http" || link.Scheme == "https") && link.Host != ""
+}
@@ -2,17 +2,53 @@ package domain
 
 import "time"
 
+// MessageKind distinguishes user-written messages from service messages that
+// record chat events in the history.
+type MessageKind string
+
+const (
+	MessageKindText   MessageKind = "text"
+	MessageKindPinned MessageKind = "pinned" // ReferenceID is the pinned message
+	MessageKindPoll   MessageKind = "poll"   // room messages only; the content is the question
+)
+
+// IsService reports whether messages of this kind are generated by the server.
+func (k MessageKind) IsService() bool {
+	return k == MessageKindPinned
+}
+
+// ForwardOrigin attributes a forwarded message to where it was first posted.
+// Forwarding a forward keeps the original attribution.
+type ForwardOrigin struct {
+	SenderID  string    `json:"sender_id"`            // author of the original message
+	RoomID    *string   `json:"room_id,omitempty"`    // source channel, for linking back
+	MessageID *string   `json:"message_id,omitempty"` // the post in the source channel
+	Date      time.Time `json:"date"`                 // when the original was sent
+}
+
+// Forward is a copy of a message to post in another chat.
+type Forward struct {
+	Content  string
+	Entities []MessageEntity // formatting of the original
+	Origin   ForwardOrigin
+}
+
 // Message represents an individual message in a conversation.
 type Message struct {
-	ID             string    `gorm:"type:uuid;primaryKey" json:"id"`
-	ConversationID string    `gorm:"type:uuid;not null" json:"conversation_id"`
-	SenderID       string    `gorm:"type:uuid;not null" json:"sender_id"`
-	Content        string    `gorm:"type:text;not null" json:"content"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID             string          `gorm:"type:uuid;primaryKey" json:"id"`
+	ConversationID string          `gorm:"type:uuid;not null" json:"conversation_id"`
+	SenderID       string          `gorm:"type:uuid;not null" json:"sender_id"`
+	Content        string          `gorm:"type:text;not null" json:"content"`
+	Kind           MessageKind     `json:"kind"`
+	ReferenceID    *string         `json:"reference_id,omitempty"` // message a service message refers to
+	ForwardedFrom  *ForwardOrigin  `json:"forwarded_from,omitempty"`
+	Entities       []MessageEntity `json:"entities,omitempty"`
+	PreviewURL     string          `json:"-"` // link awaiting a preview
+	LinkPreview    *LinkPreview    `json:"link_preview,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
 }
 
-
 // MessageRepository defines the methods for message persistence.
 type MessageRepository interface {
 	Create(message *Message) error
@@ -20,4 +56,8 @@ type MessageRepository interface {
 	Delete(message *Message) error
 	FindByConversation(convoID string) ([]*Message, error)
 	FindByID(id string) (*Message, error)
-}
\ No newline at end of file
+	// FindPendingPreviews returns up to limit messages whose link preview has not been generated yet.
+	FindPendingPreviews(limit int) ([]*Message, error)
+	// SetLinkPreview stores the message's preview, nil when there is none, and marks it generated.
+	SetLinkPreview(messageID string, preview *LinkPreview) error
+}
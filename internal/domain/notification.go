@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// NotificationType defines the kind of event a notification reports.
+type NotificationType string
+
+const (
+	NotificationJoinRequestApproved NotificationType = "join_request_approved"
+	NotificationJoinRequestDeclined NotificationType = "join_request_declined"
+	NotificationMention             NotificationType = "mention" // ReferenceID is the room message
+)
+
+// Notification is an event delivered to a single user.
+type Notification struct {
+	ID          string           `json:"id"`
+	UserID      string           `json:"user_id"`
+	Type        NotificationType `json:"type"`
+	RoomID      *string          `json:"room_id,omitempty"`
+	ActorID     *string          `json:"actor_id,omitempty"`
+	ReferenceID *string          `json:"reference_id,omitempty"` // e.g. the join request or message ID
+	Read        bool             `json:"read"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+// NotificationRepository defines the methods for notification persistence.
+type NotificationRepository interface {
+	Create(notification *Notification) error
+	// CreateBatch stores the notifications in a single round trip.
+	CreateBatch(notifications []*Notification) error
+	FindByUser(userID string, unreadOnly bool, limit, offset int) ([]*Notification, error)
+	MarkRead(userID, notificationID string) error
+}
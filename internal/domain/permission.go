@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// AdminPermissions is the set of rights granted to a room admin.
+// Owners implicitly hold every permission; members and banned users hold none.
+type AdminPermissions uint32
+
+const (
+	PermChangeInfo AdminPermissions = 1 << iota
+	PermDeleteMessages
+	PermBanUsers
+	PermInviteUsers
+	PermPinMessages
+	PermManageAdmins
+	PermPostMessages // post in channels
+
+	AllAdminPermissions = PermChangeInfo | PermDeleteMessages | PermBanUsers | PermInviteUsers |
+		PermPinMessages | PermManageAdmins | PermPostMessages
+	// DefaultAdminPermissions are granted on promotion when none are specified.
+	DefaultAdminPermissions = AllAdminPermissions &^ PermManageAdmins
+)
+
+// adminPermissionNames is the JSON representation of each permission, in display order.
+var adminPermissionNames = []struct {
+	perm AdminPermissions
+	name string
+}{
+	{PermChangeInfo, "change_info"},
+	{PermDeleteMessages, "delete_messages"},
+	{PermBanUsers, "ban_users"},
+	{PermInviteUsers, "invite_users"},
+	{PermPinMessages, "pin_messages"},
+	{PermManageAdmins, "manage_admins"},
+	{PermPostMessages, "post_messages"},
+}
+
+// Has reports whether p includes every permission in perm.
+func (p AdminPermissions) Has(perm AdminPermissions) bool {
+	return p&perm == perm
+}
+
+// MarshalJSON encodes the set as a list of permission names.
+func (p AdminPermissions) MarshalJSON() ([]byte, error) {
+	names := []string{}
+	for _, entry := range adminPermissionNames {
+		if p.Has(entry.perm) {
+			names = append(names, entry.name)
+		}
+	}
+	return json.Marshal(names)
+}
+
+// UnmarshalJSON decodes a list of permission names.
+func (p *AdminPermissions) UnmarshalJSON(data []byte) error {
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return err
+	}
+	var perms AdminPermissions
+	for _, name := range names {
+		found := false
+		for _, entry := range adminPermissionNames {
+			if entry.name == name {
+				perms |= entry.perm
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown permission %q", name)
+		}
+	}
+	*p = perms
+	return nil
+}
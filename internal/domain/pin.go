@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// PinnedMessage is a message pinned to the top of a conversation or room,
+// together with the pinned message's sender and content.
+type PinnedMessage struct {
+	Chat      ChatRef   `json:"-"`
+	MessageID string    `json:"message_id"`
+	SenderID  string    `json:"sender_id"`
+	Content   string    `json:"content"`
+	PinnedBy  string    `json:"pinned_by"`
+	Position  int       `json:"position"` // lowest position is shown on top
+	PinnedAt  time.Time `json:"pinned_at"`
+}
+
+// PinRepository defines persistence operations for pinned messages.
+type PinRepository interface {
+	Add(pin *PinnedMessage) error
+	Remove(chat ChatRef, messageID string) error
+	// FindByChat returns the chat's pins ordered by position.
+	FindByChat(chat ChatRef) ([]*PinnedMessage, error)
+	// Reorder assigns positions in the order of messageIDs.
+	Reorder(chat ChatRef, messageIDs []string) error
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Limits on poll content.
+const (
+	MaxPollOptions       = 10
+	MaxPollQuestionRunes = 300
+	MaxPollOptionRunes   = 100
+)
+
+// Errors PollRepository.SetVotes returns when the poll no longer takes the vote.
+var (
+	ErrPollClosed   = errors.New("poll is closed")
+	ErrQuizAnswered = errors.New("quiz answers cannot be changed")
+)
+
+// PollOption is one answer of a poll, in the position voters refer to.
+type PollOption struct {
+	Text   string   `json:"text"`
+	Votes  int      `json:"votes"`
+	Voters []string `json:"voters,omitempty"` // public polls only
+}
+
+// Poll is the content of a MessageKindPoll room message; the message's content
+// is the question.
+type Poll struct {
+	MessageID      string       `json:"message_id"`
+	RoomID         string       `json:"room_id"`
+	Question       string       `json:"question"`
+	Options        []PollOption `json:"options"`
+	MultipleChoice bool         `json:"multiple_choice"`
+	Anonymous      bool         `json:"anonymous"` // voters are never revealed
+	Quiz           bool         `json:"quiz"`      // single choice with one correct answer
+	// CorrectOption is the quiz answer. It is only shown to users who voted, and
+	// to everyone once the poll is closed.
+	CorrectOption *int       `json:"correct_option,omitempty"`
+	TotalVoters   int        `json:"total_voters"`
+	CloseAt       *time.Time `json:"close_at,omitempty"`  // deadline set by the creator
+	ClosedAt      *time.Time `json:"closed_at,omitempty"` // when voting ended
+	MyVotes       []int      `json:"my_votes,omitempty"`  // the requesting user's options
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// IsClosed reports whether voting has ended at now, either explicitly or by
+// reaching the deadline.
+func (p *Poll) IsClosed(now time.Time) bool {
+	return p.ClosedAt != nil || (p.CloseAt != nil && !now.Before(*p.CloseAt))
+}
+
+// PollRepository stores polls and their votes. Polls are created along with
+// their message by RoomMessageRepository.Create.
+type PollRepository interface {
+	// FindByMessage returns the poll with its tallies, the votes of viewerID (if
+	// not empty) and, for public polls, the voters of each option.
+	FindByMessage(messageID, viewerID string) (*Poll, error)
+	// SetVotes replaces the user's votes, updating the tallies in the same
+	// transaction. No options retracts the vote. It returns ErrPollClosed if
+	// the poll has closed and ErrQuizAnswered if the user already answered a
+	// quiz, checked under the poll's lock.
+	SetVotes(messageID, userID string, options []int) error
+	Close(messageID string, at time.Time) error
+	// CloseExpired closes the polls whose deadline passed by now and returns their message IDs.
+	CloseExpired(now time.Time) ([]string, error)
+}
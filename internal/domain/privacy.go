@@ -0,0 +1,46 @@
+package domain
+
+// PrivacyAudience controls who a privacy setting applies to.
+type PrivacyAudience string
+
+const (
+	AudienceEveryone PrivacyAudience = "everyone"
+	AudienceContacts PrivacyAudience = "contacts"
+	AudienceNobody   PrivacyAudience = "nobody"
+)
+
+// Valid reports whether a is a known audience.
+func (a PrivacyAudience) Valid() bool {
+	switch a {
+	case AudienceEveryone, AudienceContacts, AudienceNobody:
+		return true
+	}
+	return false
+}
+
+// PrivacySettings holds a user's privacy preferences.
+type PrivacySettings struct {
+	PhoneVisibility PrivacyAudience `json:"phone_visibility"` // who sees the phone number on the profile
+	FindByPhone     PrivacyAudience `json:"find_by_phone"`    // who can look the user up by phone number
+	Messages        PrivacyAudience `json:"messages"`         // who can start a direct conversation
+	GroupInvites    PrivacyAudience `json:"group_invites"`    // who can add the user to groups and channels
+}
+
+// DefaultPrivacySettings returns the settings new users start with.
+func DefaultPrivacySettings() PrivacySettings {
+	return PrivacySettings{
+		PhoneVisibility: AudienceNobody,
+		FindByPhone:     AudienceEveryone,
+		Messages:        AudienceEveryone,
+		GroupInvites:    AudienceEveryone,
+	}
+}
+
+// PublicProfile is the view of a user shown to other users.
+// Phone is only set when the user's privacy settings allow the viewer to see it.
+type PublicProfile struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Username *string `json:"username,omitempty"`
+	Phone    *string `json:"phone,omitempty"`
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// RestrictionKind is the type of a room restriction.
+type RestrictionKind string
+
+const (
+	// RestrictionBan removes access to the room; the membership role is set to banned.
+	RestrictionBan RestrictionKind = "ban"
+	// RestrictionMute keeps read access but blocks sending messages.
+	RestrictionMute RestrictionKind = "mute"
+)
+
+// RoomRestriction is a ban or mute on a room member, optionally time-limited.
+// A user has at most one restriction of each kind per room.
+type RoomRestriction struct {
+	ID               string             `json:"id"`
+	RoomID           string             `json:"room_id"`
+	UserID           string             `json:"user_id"`
+	Kind             RestrictionKind    `json:"kind"`
+	Reason           string             `json:"reason"`
+	PriorRole        RoomMembershipRole `json:"prior_role"`        // restored when a ban is lifted
+	PriorPermissions AdminPermissions   `json:"prior_permissions"` // restored along with an admin prior role
+	ActorID          string             `json:"actor_id"`
+	ExpiresAt        *time.Time         `json:"expires_at,omitempty"` // nil means until lifted manually
+	CreatedAt        time.Time          `json:"created_at"`
+}
+
+// Expired reports whether the restriction has run out at the given time.
+func (r *RoomRestriction) Expired(now time.Time) bool {
+	return r.ExpiresAt != nil && !now.Before(*r.ExpiresAt)
+}
+
+// RoomRestrictionRepository stores bans and mutes.
+type RoomRestrictionRepository interface {
+	// Upsert creates the restriction or replaces the existing one of the same kind.
+	Upsert(restriction *RoomRestriction) error
+	// Find returns nil if the user has no restriction of that kind in the room.
+	Find(roomID, userID string, kind RestrictionKind) (*RoomRestriction, error)
+	Delete(roomID, userID string, kind RestrictionKind) error
+	// FindExpired returns up to limit restrictions that expired at or before now.
+	FindExpired(now time.Time, limit int) ([]*RoomRestriction, error)
+}
@@ -12,13 +12,29 @@ const (
 
 // Room represents a group or channel.
 type Room struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Username  *string   `json:"username,omitempty"`
-	Type      RoomType  `json:"type"` // "group" or "channel"
-	OwnerID   string    `json:"owner_id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Username      *string   `json:"username,omitempty"`
+	Type          RoomType  `json:"type"` // "group" or "channel"
+	Description   string    `json:"description"`
+	PhotoURL      string    `json:"photo_url"`
+	Rules         string    `json:"rules"`           // pinned rules/about text
+	JoinByRequest bool      `json:"join_by_request"` // non-members must be approved to join
+	OwnerID       string    `json:"owner_id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// IsPublic reports whether the room is listed in the directory and open to self-join.
+// A room is public when it has a username.
+func (r *Room) IsPublic() bool {
+	return r.Username != nil
+}
+
+// RoomDetails is the full room card: the room together with its member count.
+type RoomDetails struct {
+	Room
+	MemberCount int `json:"member_count"`
 }
 
 // RoomMembershipRole defines roles for room membership.
@@ -31,22 +47,78 @@ const (
 	RoleBanned RoomMembershipRole = "banned"
 )
 
+// Rank orders roles for moderation: owner > admin > member.
+// Banned users and non-members rank lowest.
+func (r RoomMembershipRole) Rank() int {
+	switch r {
+	case RoleOwner:
+		return 3
+	case RoleAdmin:
+		return 2
+	case RoleMember:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// roleTransitions is the membership role state machine. Ownership changes
+// hands only through a transfer, which demotes the previous owner to admin.
+var roleTransitions = map[RoomMembershipRole][]RoomMembershipRole{
+	RoleOwner:  {RoleAdmin},
+	RoleAdmin:  {RoleOwner, RoleMember, RoleBanned},
+	RoleMember: {RoleOwner, RoleAdmin, RoleBanned},
+	RoleBanned: {RoleMember},
+}
+
+// CanTransitionTo reports whether a membership may move from r to the given role.
+func (r RoomMembershipRole) CanTransitionTo(to RoomMembershipRole) bool {
+	for _, allowed := range roleTransitions[r] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
 // RoomMembership represents a user’s membership in a room.
 type RoomMembership struct {
-	RoomID    string              `json:"room_id"`
-	UserID    string              `json:"user_id"`
-	Role      RoomMembershipRole  `json:"role"`
-	CreatedAt time.Time           `json:"created_at"`
+	RoomID      string             `json:"room_id"`
+	UserID      string             `json:"user_id"`
+	Role        RoomMembershipRole `json:"role"`
+	Permissions AdminPermissions   `json:"permissions"`           // only meaningful for admins
+	PromotedBy  *string            `json:"promoted_by,omitempty"` // who made this member an admin
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+// Can reports whether the membership grants perm. Owners hold every permission.
+func (m *RoomMembership) Can(perm AdminPermissions) bool {
+	switch m.Role {
+	case RoleOwner:
+		return true
+	case RoleAdmin:
+		return m.Permissions.Has(perm)
+	default:
+		return false
+	}
 }
 
 // RoomMessage represents a message sent in a room.
 type RoomMessage struct {
-	ID        string    `json:"id"`
-	RoomID    string    `json:"room_id"`
-	SenderID  string    `json:"sender_id"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID            string          `json:"id"`
+	RoomID        string          `json:"room_id"`
+	SenderID      string          `json:"sender_id"`
+	Content       string          `json:"content"`
+	Kind          MessageKind     `json:"kind"`
+	ReferenceID   *string         `json:"reference_id,omitempty"` // message a service message refers to
+	ForwardedFrom *ForwardOrigin  `json:"forwarded_from,omitempty"`
+	Entities      []MessageEntity `json:"entities,omitempty"`
+	PreviewURL    string          `json:"-"` // link awaiting a preview
+	LinkPreview   *LinkPreview    `json:"link_preview,omitempty"`
+	Poll          *Poll           `json:"poll,omitempty"` // set for MessageKindPoll
+	Mentioned     []string        `json:"-"`              // users the message mentions, recorded on create
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
 }
 
 // Repository interfaces for room functionality.
@@ -56,21 +128,42 @@ type RoomRepository interface {
 	Delete(roomID string) error
 	FindByID(roomID string) (*Room, error)
 	FindByUsername(username string) (*Room, error)
+	// FindByMember returns the rooms the user belongs to, excluding ones they are banned from.
+	FindByMember(userID string) ([]*Room, error)
+	// SearchPublic returns public rooms (those with a username) matching query,
+	// with member counts. An empty query lists public rooms by size.
+	SearchPublic(query string, limit, offset int) ([]*RoomDetails, error)
+	// TransferOwnership atomically makes toUserID the owner and demotes
+	// fromUserID to an admin with every permission.
+	TransferOwnership(roomID, fromUserID, toUserID string) error
 }
 
 type RoomMembershipRepository interface {
 	AddMember(membership *RoomMembership) error
+	// UpdateMemberRole changes the role. Moving away from admin clears the permissions.
 	UpdateMemberRole(roomID, userID string, role RoomMembershipRole) error
+	UpdateMemberPermissions(roomID, userID string, permissions AdminPermissions) error
+	// SetAdmin makes the member an admin with the given permissions, recording who promoted them.
+	SetAdmin(roomID, userID string, permissions AdminPermissions, promotedBy string) error
 	RemoveMember(roomID, userID string) error
 	GetMembers(roomID string) ([]*RoomMembership, error)
 	IsUserBanned(roomID, userID string) (bool, error)
 	GetMemberRole(roomID, userID string) (RoomMembershipRole, error)
+	// GetMembership returns nil if the user is not in the room.
+	GetMembership(roomID, userID string) (*RoomMembership, error)
+	CountMembers(roomID string) (int, error)
 }
 
 type RoomMessageRepository interface {
+	// Create stores the message along with its poll and mentions in one transaction.
 	Create(message *RoomMessage) error
 	Update(message *RoomMessage) error
 	Delete(messageID string) error
-	FindByRoom(roomID string) ([]*RoomMessage, error)
+	// FindByRoom returns the room's history. Polls carry their tallies and the votes of viewerID.
+	FindByRoom(roomID, viewerID string) ([]*RoomMessage, error)
 	FindByID(messageID string) (*RoomMessage, error)
+	// FindPendingPreviews returns up to limit messages whose link preview has not been generated yet.
+	FindPendingPreviews(limit int) ([]*RoomMessage, error)
+	// SetLinkPreview stores the message's preview, nil when there is none, and marks it generated.
+	SetLinkPreview(messageID string, preview *LinkPreview) error
 }
@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// MessageSearchFilter narrows a full-text message search.
+// Empty string fields and nil times are ignored.
+type MessageSearchFilter struct {
+	Query          string
+	SenderID       string
+	ConversationID string
+	RoomID         string
+	From           *time.Time // inclusive
+	To             *time.Time // exclusive
+	Limit          int
+	Offset         int
+}
+
+// MessageSearchHit is a single search result from either a conversation or a room.
+// Exactly one of ConversationID and RoomID is set.
+type MessageSearchHit struct {
+	MessageID      string    `json:"message_id"`
+	ConversationID *string   `json:"conversation_id,omitempty"`
+	RoomID         *string   `json:"room_id,omitempty"`
+	SenderID       string    `json:"sender_id"`
+	Snippet        string    `json:"snippet"` // escaped HTML, matches wrapped in <mark></mark>
+	Rank           float32   `json:"rank"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// MessageSearchRepository defines full-text search over a user's readable messages.
+type MessageSearchRepository interface {
+	// Search only returns messages from conversations the user participates in
+	// and rooms where the user is a non-banned member, ordered by rank.
+	Search(userID string, filter MessageSearchFilter) ([]*MessageSearchHit, error)
+}
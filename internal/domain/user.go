@@ -4,20 +4,29 @@ import "time"
 
 // User represents the user entity. We use UUID as primary key.
 type User struct {
-	ID        string    `gorm:"type:uuid;primaryKey" json:"id"` // UUID string as primary key
-	Name      string    `gorm:"not null" json:"name"`
-	Phone     string    `gorm:"unique;not null" json:"phone"`
-	Username  *string   `gorm:"unique" json:"username,omitempty"` // optional at registration; unique when set
-	Password  string    `gorm:"not null" json:"-"`                // hashed password (do not return)
-	CreatedAt time.Time `json:"created_at"`
+	ID           string          `gorm:"type:uuid;primaryKey" json:"id"` // UUID string as primary key
+	Name         string          `gorm:"not null" json:"name"`
+	Phone        string          `gorm:"unique;not null" json:"phone"`
+	Username     *string         `gorm:"unique" json:"username,omitempty"`          // optional at registration; unique when set
+	Password     string          `gorm:"not null" json:"-"`                         // hashed password (do not return)
+	Discoverable bool            `gorm:"not null;default:true" json:"discoverable"` // listed in user directory search
+	Privacy      PrivacySettings `gorm:"embedded" json:"privacy"`
+	CreatedAt    time.Time       `json:"created_at"`
 }
 
 // UserRepository defines methods for user persistence.
 type UserRepository interface {
 	Create(user *User) error
 	FindByPhone(phone string) (*User, error)
+	// FindByPhones returns the users whose phone number is in phones or whose
+	// salted phone hash (see HashPhone) is in phoneHashes.
+	FindByPhones(phones, phoneHashes []string) ([]*User, error)
 	FindByUsername(username string) (*User, error)
 	FindByID(id string) (*User, error)
 	Update(user *User) error
 	Delete(user *User) error
+	// Search returns discoverable users whose username or name matches query by
+	// prefix or trigram similarity, best matches first. The searcher and users
+	// blocked by or blocking them are omitted.
+	Search(query, searcherID string, limit, offset int) ([]*User, error)
 }
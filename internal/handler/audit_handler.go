@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"social_media/internal/domain"
+	"social_media/internal/service"
+)
+
+type AuditHandler struct {
+	auditService service.AuditService
+}
+
+// NewAuditHandler creates a new AuditHandler.
+func NewAuditHandler(auditService service.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// GetAuditLog lists a room's moderation audit log.
+// Query parameters: actor_id, action, from and to (RFC3339), limit, offset.
+func (h *AuditHandler) GetAuditLog(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	filter := domain.RoomAuditFilter{
+		ActorID: c.Query("actor_id"),
+		Action:  domain.AuditAction(c.Query("action")),
+	}
+	var err error
+	if filter.From, err = parseTimeQuery(c, "from"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from date"})
+		return
+	}
+	if filter.To, err = parseTimeQuery(c, "to"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to date"})
+		return
+	}
+	filter.Limit, filter.Offset = pagination(c)
+
+	entries, err := h.auditService.GetAuditLog(c.Param("roomID"), userID.(string), filter)
+	if err != nil {
+		c.JSON(roomErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"social_media/internal/service"
+)
+
+type BlockHandler struct {
+	blockService service.BlockService
+}
+
+// NewBlockHandler creates a new BlockHandler.
+func NewBlockHandler(blockService service.BlockService) *BlockHandler {
+	return &BlockHandler{blockService: blockService}
+}
+
+// BlockUser blocks the user in the path from messaging the authenticated user.
+func (h *BlockHandler) BlockUser(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	if err := h.blockService.BlockUser(userID.(string), c.Param("userID")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "user blocked"})
+}
+
+// UnblockUser lifts a block.
+func (h *BlockHandler) UnblockUser(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	if err := h.blockService.UnblockUser(userID.(string), c.Param("userID")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "user unblocked"})
+}
+
+// ListBlocked returns the users blocked by the authenticated user.
+func (h *BlockHandler) ListBlocked(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	blocks, err := h.blockService.GetBlockedUsers(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, blocks)
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"social_media/internal/domain"
+	"social_media/internal/service"
+)
+
+type BookmarkHandler struct {
+	bookmarkService service.BookmarkService
+}
+
+// NewBookmarkHandler creates a new BookmarkHandler.
+func NewBookmarkHandler(bookmarkService service.BookmarkService) *BookmarkHandler {
+	return &BookmarkHandler{bookmarkService: bookmarkService}
+}
+
+// ListBookmarks returns the authenticated user's saved messages, newest first.
+// Query parameters: tag, source ("conversation" or "room"), source_id, limit, offset.
+func (h *BookmarkHandler) ListBookmarks(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	filter := domain.BookmarkFilter{
+		Tag:        c.Query("tag"),
+		SourceKind: domain.ChatKind(c.Query("source")),
+		SourceID:   c.Query("source_id"),
+	}
+	filter.Limit, filter.Offset = pagination(c)
+
+	bookmarks, err := h.bookmarkService.GetBookmarks(userID.(string), filter)
+	if err != nil {
+		c.JSON(bookmarkErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, bookmarks)
+}
+
+// AddBookmark saves a message the user can read.
+// Expected JSON: {"source": {"kind": "room", "id": "uuid"}, "message_id": "uuid", "note": "for later", "tags": ["recipes"]}
+func (h *BookmarkHandler) AddBookmark(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	var req struct {
+		Source    domain.ChatRef `json:"source" binding:"required"`
+		MessageID string         `json:"message_id" binding:"required"`
+		Note      string         `json:"note"`
+		Tags      []string       `json:"tags"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	bookmark, err := h.bookmarkService.AddBookmark(userID.(string), req.Source, req.MessageID, req.Note, req.Tags)
+	if err != nil {
+		c.JSON(bookmarkErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, bookmark)
+}
+
+// UpdateBookmark replaces a bookmark's note and tags.
+// Expected JSON: {"note": "for later", "tags": ["recipes"]}
+func (h *BookmarkHandler) UpdateBookmark(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	var req struct {
+		Note string   `json:"note"`
+		Tags []string `json:"tags"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	bookmark, err := h.bookmarkService.UpdateBookmark(userID.(string), c.Param("bookmarkID"), req.Note, req.Tags)
+	if err != nil {
+		c.JSON(bookmarkErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, bookmark)
+}
+
+// RemoveBookmark deletes a bookmark. The original message is not affected.
+func (h *BookmarkHandler) RemoveBookmark(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	if err := h.bookmarkService.RemoveBookmark(userID.(string), c.Param("bookmarkID")); err != nil {
+		c.JSON(bookmarkErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "bookmark removed"})
+}
+
+// bookmarkErrorStatus maps BookmarkService errors to HTTP status codes.
+func bookmarkErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrBanned):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrBookmarkNotFound), errors.Is(err, service.ErrChatNotFound), errors.Is(err, service.ErrMessageNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrAlreadyBookmarked):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"social_media/internal/domain"
+	"social_media/internal/service"
+)
+
+type ChatHandler struct {
+	chatService service.ChatService
+}
+
+// NewChatHandler creates a new ChatHandler.
+func NewChatHandler(chatService service.ChatService) *ChatHandler {
+	return &ChatHandler{chatService: chatService}
+}
+
+// ListConversations returns the authenticated user's conversations with their chat state.
+// Query parameters: folder (a folder ID), archived (true lists the archive; defaults to false).
+func (h *ChatHandler) ListConversations(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	filter, err := chatFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	convos, err := h.chatService.ListConversations(userID.(string), filter)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, convos)
+}
+
+// ListRooms returns the rooms the authenticated user belongs to with their chat state.
+// Query parameters: folder (a folder ID), archived (true lists the archive; defaults to false).
+func (h *ChatHandler) ListRooms(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	filter, err := chatFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rooms, err := h.chatService.ListRooms(userID.(string), filter)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rooms)
+}
+
+// UpdateChatState archives, pins or mutes a chat. Omitted fields are left unchanged.
+// The path holds the chat kind ("conversation" or "room") and its ID.
+// Expected JSON: {"archived": true, "pinned": false, "muted_until": "2025-01-01T00:00:00Z"}
+// muted_until may also be "forever", or "" to unmute.
+func (h *ChatHandler) UpdateChatState(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	var req struct {
+		Archived   *bool   `json:"archived"`
+		Pinned     *bool   `json:"pinned"`
+		MutedUntil *string `json:"muted_until"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	update := service.ChatStateUpdate{Archived: req.Archived, Pinned: req.Pinned}
+	if req.MutedUntil != nil {
+		var mutedUntil time.Time
+		switch *req.MutedUntil {
+		case "":
+		case "forever":
+			mutedUntil = domain.MutedForever
+		default:
+			parsed, err := time.Parse(time.RFC3339, *req.MutedUntil)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "muted_until must be an RFC 3339 timestamp, \"forever\" or \"\""})
+				return
+			}
+			mutedUntil = parsed
+		}
+		update.MutedUntil = &mutedUntil
+	}
+	state, err := h.chatService.UpdateChatState(userID.(string), chatRef(c), update)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, state)
+}
+
+// ReorderPinnedChats sets the order of the pinned chats.
+// Expected JSON: {"chats": [{"kind": "room", "id": "uuid"}, {"kind": "conversation", "id": "uuid"}]}
+func (h *ChatHandler) ReorderPinnedChats(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	var req struct {
+		Chats []domain.ChatRef `json:"chats" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.chatService.ReorderPinnedChats(userID.(string), req.Chats); err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "pinned chats reordered"})
+}
+
+// MarkRead marks a chat as read up to now.
+func (h *ChatHandler) MarkRead(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	if err := h.chatService.MarkRead(userID.(string), chatRef(c)); err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "chat marked as read"})
+}
+
+// ListFolders returns the authenticated user's chat folders.
+func (h *ChatHandler) ListFolders(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	folders, err := h.chatService.GetFolders(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, folders)
+}
+
+// folderRequest is the body for creating or replacing a chat folder.
+// Example: {"name": "Unread groups", "rules": {"include_groups": true, "exclude_read": true}}
+type folderRequest struct {
+	Name  string                 `json:"name" binding:"required"`
+	Rules domain.ChatFolderRules `json:"rules"`
+}
+
+// CreateFolder creates a chat folder.
+func (h *ChatHandler) CreateFolder(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	var req folderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	folder, err := h.chatService.CreateFolder(userID.(string), req.Name, req.Rules)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, folder)
+}
+
+// UpdateFolder replaces a chat folder's name and rules.
+func (h *ChatHandler) UpdateFolder(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	var req folderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	folder, err := h.chatService.UpdateFolder(userID.(string), c.Param("folderID"), req.Name, req.Rules)
+	if err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, folder)
+}
+
+// DeleteFolder deletes a chat folder. The chats in it are not affected.
+func (h *ChatHandler) DeleteFolder(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	if err := h.chatService.DeleteFolder(userID.(string), c.Param("folderID")); err != nil {
+		c.JSON(chatErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "folder deleted"})
+}
+
+// chatFilter reads the folder and archived query parameters.
+func chatFilter(c *gin.Context) (service.ChatFilter, error) {
+	filter := service.ChatFilter{FolderID: c.Query("folder")}
+	if raw := c.Query("archived"); raw != "" {
+		archived, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, errors.New("archived must be true or false")
+		}
+		filter.Archived = &archived
+	}
+	return filter, nil
+}
+
+// chatRef reads the chat kind and ID path parameters.
+func chatRef(c *gin.Context) domain.ChatRef {
+	return domain.ChatRef{Kind: domain.ChatKind(c.Param("kind")), ID: c.Param("chatID")}
+}
+
+// chatErrorStatus maps ChatService errors to HTTP status codes.
+func chatErrorStatus(err error) int {
+	if errors.Is(err, service.ErrChatNotFound) || errors.Is(err, service.ErrFolderNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"social_media/internal/service"
+)
+
+type ContactHandler struct {
+	contactService service.ContactService
+}
+
+// NewContactHandler creates a new ContactHandler.
+func NewContactHandler(contactService service.ContactService) *ContactHandler {
+	return &ContactHandler{contactService: contactService}
+}
+
+// SaveContact adds the user in the path to the authenticated user's contacts,
+// or renames them if they are already a contact.
+// Expected JSON: {"display_name": "Mom"}
+func (h *ContactHandler) SaveContact(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	var req struct {
+		DisplayName string `json:"display_name"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	contact, err := h.contactService.SaveContact(userID.(string), c.Param("userID"), req.DisplayName)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, contact)
+}
+
+// RemoveContact removes the user in the path from the authenticated user's contacts.
+func (h *ContactHandler) RemoveContact(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	if err := h.contactService.RemoveContact(userID.(string), c.Param("userID")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "contact removed"})
+}
+
+// ListContacts returns the authenticated user's contacts.
+func (h *ContactHandler) ListContacts(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	contacts, err := h.contactService.GetContacts(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, contacts)
+}
+
+// ImportContacts reports which phone-book entries belong to registered users.
+// Phone hashes are hex SHA-256 digests of the salted number (see domain.HashPhone).
+// Expected JSON: {"phones": ["1234567890"], "phone_hashes": ["9f86d0..."]}
+func (h *ContactHandler) ImportContacts(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	var req struct {
+		Phones      []string `json:"phones"`
+		PhoneHashes []string `json:"phone_hashes"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	matches, err := h.contactService.ImportContacts(userID.(string), req.Phones, req.PhoneHashes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, matches)
+}
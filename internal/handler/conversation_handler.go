@@ -1,10 +1,14 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"social_media/internal/domain"
 	"social_media/internal/service"
+	"social_media/pkg/markdown"
 )
 
 type ConversationHandler struct {
@@ -20,53 +24,60 @@ func NewConversationHandler(convoService service.ConversationService) *Conversat
 // Expected JSON:
 // {
 //    "recipient": "identifier", // either "1234567890" or "@johndoe"
-//    "content": "Hello, how are you?"
+//    "content": "Hello, how are you?",
+//    "entities": [{"type": "bold", "offset": 0, "length": 5}], // optional
+//    "parse_mode": "markdown" // optional, derives entities from markdown in content instead
 // }
 func (h *ConversationHandler) SendMessageEndpoint(c *gin.Context) {
 	var req struct {
-		Recipient string `json:"recipient" binding:"required"`
-		Content   string `json:"content" binding:"required"`
+		Recipient string                 `json:"recipient" binding:"required"`
+		Content   string                 `json:"content" binding:"required"`
+		Entities  []domain.MessageEntity `json:"entities"`
+		ParseMode string                 `json:"parse_mode"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	content, entities, err := messageBody(req.Content, req.ParseMode, req.Entities)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	// Retrieve senderID from the context (set by AuthMiddleware).
 	senderID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
 		return
 	}
-	message, err := h.convoService.SendMessage(senderID.(string), req.Recipient, req.Content)
+	message, err := h.convoService.SendMessage(senderID.(string), req.Recipient, content, entities)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		status := http.StatusBadRequest
+		if errors.Is(err, service.ErrForbidden) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, message)
 }
 
-// ListConversations returns all conversations for the authenticated user.
-func (h *ConversationHandler) ListConversations(c *gin.Context) {
+// GetMessages returns all messages for a specific conversation.
+// The conversation ID is taken from the URL parameter.
+func (h *ConversationHandler) GetMessages(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
 		return
 	}
-	convos, err := h.convoService.GetConversations(userID.(string))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	c.JSON(http.StatusOK, convos)
-}
-
-// GetMessages returns all messages for a specific conversation.
-// The conversation ID is taken from the URL parameter.
-func (h *ConversationHandler) GetMessages(c *gin.Context) {
 	convoID := c.Param("id")
-	messages, err := h.convoService.GetMessages(convoID)
+	messages, err := h.convoService.GetMessages(userID.(string), convoID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrChatNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, messages)
@@ -74,21 +85,29 @@ func (h *ConversationHandler) GetMessages(c *gin.Context) {
 
 // UpdateMessage allows the sender to update a message.
 // The message ID is taken from the URL parameter.
+// Expected JSON: {"content": "Hello", "entities": [...], "parse_mode": "markdown"}
 func (h *ConversationHandler) UpdateMessage(c *gin.Context) {
 	messageID := c.Param("id")
 	var req struct {
-		Content string `json:"content" binding:"required"`
+		Content   string                 `json:"content" binding:"required"`
+		Entities  []domain.MessageEntity `json:"entities"`
+		ParseMode string                 `json:"parse_mode"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	content, entities, err := messageBody(req.Content, req.ParseMode, req.Entities)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	senderID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
 		return
 	}
-	message, err := h.convoService.UpdateMessage(senderID.(string), messageID, req.Content)
+	message, err := h.convoService.UpdateMessage(senderID.(string), messageID, content, entities)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -111,3 +130,188 @@ func (h *ConversationHandler) DeleteMessage(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "message deleted"})
 }
+
+// ListMessageRequests returns first-contact conversations awaiting the authenticated user.
+func (h *ConversationHandler) ListMessageRequests(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	requests, err := h.convoService.GetMessageRequests(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, requests)
+}
+
+// AcceptMessageRequest moves a message request into the user's conversations.
+func (h *ConversationHandler) AcceptMessageRequest(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	convo, err := h.convoService.AcceptMessageRequest(userID.(string), c.Param("id"))
+	if err != nil {
+		c.JSON(messageRequestErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, convo)
+}
+
+// DeleteMessageRequest discards a message request.
+func (h *ConversationHandler) DeleteMessageRequest(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	if err := h.convoService.DeleteMessageRequest(userID.(string), c.Param("id")); err != nil {
+		c.JSON(messageRequestErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "message request deleted"})
+}
+
+// BlockMessageRequest discards a message request and blocks its sender.
+func (h *ConversationHandler) BlockMessageRequest(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	if err := h.convoService.BlockMessageRequest(userID.(string), c.Param("id")); err != nil {
+		c.JSON(messageRequestErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "sender blocked"})
+}
+
+// CreateGroupConversation starts a group conversation with the given users.
+// Expected JSON: {"title": "Weekend trip", "member_ids": ["uuid1", "uuid2"]}
+func (h *ConversationHandler) CreateGroupConversation(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	var req struct {
+		Title     string   `json:"title"`
+		MemberIDs []string `json:"member_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	convo, err := h.convoService.CreateGroupConversation(userID.(string), req.Title, req.MemberIDs)
+	if err != nil {
+		c.JSON(conversationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, convo)
+}
+
+// AddParticipant adds a user to a group conversation.
+// Expected JSON: {"user_id": "uuid"}
+func (h *ConversationHandler) AddParticipant(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	var req struct {
+		UserID string `json:"user_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.convoService.AddParticipant(c.Param("id"), userID.(string), req.UserID); err != nil {
+		c.JSON(conversationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "participant added"})
+}
+
+// LeaveConversation removes the authenticated user from a group conversation.
+func (h *ConversationHandler) LeaveConversation(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	if err := h.convoService.LeaveConversation(c.Param("id"), userID.(string)); err != nil {
+		c.JSON(conversationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "left conversation"})
+}
+
+// SendGroupMessage posts a message to a group conversation.
+// Expected JSON: {"content": "Hello everyone", "entities": [...], "parse_mode": "markdown"}
+func (h *ConversationHandler) SendGroupMessage(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	var req struct {
+		Content   string                 `json:"content" binding:"required"`
+		Entities  []domain.MessageEntity `json:"entities"`
+		ParseMode string                 `json:"parse_mode"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	content, entities, err := messageBody(req.Content, req.ParseMode, req.Entities)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	message, err := h.convoService.SendGroupMessage(userID.(string), c.Param("id"), content, entities)
+	if err != nil {
+		c.JSON(conversationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, message)
+}
+
+// conversationErrorStatus maps group conversation errors to HTTP status codes.
+func conversationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrConversationNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// messageRequestErrorStatus maps message request errors to HTTP status codes.
+func messageRequestErrorStatus(err error) int {
+	if errors.Is(err, service.ErrMessageRequestNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// messageBody returns the text and formatting entities of a message request. With
+// parse_mode "markdown" the entities are derived from markdown in the content.
+func messageBody(content, parseMode string, entities []domain.MessageEntity) (string, []domain.MessageEntity, error) {
+	switch parseMode {
+	case "":
+		return content, entities, nil
+	case "markdown":
+		if len(entities) > 0 {
+			return "", nil, errors.New("entities cannot be combined with parse_mode")
+		}
+		text, entities := markdown.Parse(content)
+		return text, entities, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported parse_mode %q", parseMode)
+	}
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"social_media/internal/domain"
+	"social_media/internal/service"
+)
+
+type ForwardHandler struct {
+	forwardService service.ForwardService
+}
+
+// NewForwardHandler creates a new ForwardHandler.
+func NewForwardHandler(forwardService service.ForwardService) *ForwardHandler {
+	return &ForwardHandler{forwardService: forwardService}
+}
+
+// forwardRequest names the source chat and the messages to forward from it.
+// Example: {"from": {"kind": "room", "id": "uuid"}, "message_ids": ["uuid", "uuid"]}
+type forwardRequest struct {
+	From       domain.ChatRef `json:"from" binding:"required"`
+	MessageIDs []string       `json:"message_ids" binding:"required"`
+}
+
+// ForwardToConversation forwards messages to the conversation in the path.
+func (h *ForwardHandler) ForwardToConversation(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	var req forwardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	messages, err := h.forwardService.ForwardToConversation(userID.(string), req.From, req.MessageIDs, c.Param("id"))
+	if err != nil {
+		c.JSON(forwardErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, messages)
+}
+
+// ForwardToRoom forwards messages to the room in the path.
+func (h *ForwardHandler) ForwardToRoom(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	var req forwardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	messages, err := h.forwardService.ForwardToRoom(userID.(string), req.From, req.MessageIDs, c.Param("roomID"))
+	if err != nil {
+		c.JSON(forwardErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, messages)
+}
+
+// forwardErrorStatus maps errors from reading the source or sending to the target to HTTP status codes.
+func forwardErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrForbidden), errors.Is(err, service.ErrBanned), errors.Is(err, service.ErrMuted):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrChatNotFound), errors.Is(err, service.ErrMessageNotFound),
+		errors.Is(err, service.ErrConversationNotFound), errors.Is(err, service.ErrRoomNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"social_media/internal/service"
+)
+
+type InviteHandler struct {
+	inviteService service.InviteService
+}
+
+// NewInviteHandler creates a new InviteHandler.
+func NewInviteHandler(inviteService service.InviteService) *InviteHandler {
+	return &InviteHandler{inviteService: inviteService}
+}
+
+type CreateInviteRequest struct {
+	ExpiresAt        *time.Time `json:"expires_at"` // optional, RFC3339
+	MaxUses          *int       `json:"max_uses"`   // optional
+	RequiresApproval bool       `json:"requires_approval"`
+}
+
+// CreateInvite creates an invite link for the room in the URL.
+func (h *InviteHandler) CreateInvite(c *gin.Context) {
+	roomID := c.Param("roomID")
+	var req CreateInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	requesterID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	invite, err := h.inviteService.CreateInvite(roomID, requesterID.(string), req.ExpiresAt, req.MaxUses, req.RequiresApproval)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, invite)
+}
+
+// ListInvites returns the invite links of the room in the URL.
+func (h *InviteHandler) ListInvites(c *gin.Context) {
+	roomID := c.Param("roomID")
+	requesterID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	invites, err := h.inviteService.ListInvites(roomID, requesterID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, invites)
+}
+
+// RevokeInvite disables an invite link.
+func (h *InviteHandler) RevokeInvite(c *gin.Context) {
+	roomID := c.Param("roomID")
+	token := c.Param("token")
+	requesterID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	if err := h.inviteService.RevokeInvite(roomID, requesterID.(string), token); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "invite revoked"})
+}
+
+// AcceptInvite joins the authenticated user through an invite link.
+// Links that require approval respond with 202 and the pending join request.
+func (h *InviteHandler) AcceptInvite(c *gin.Context) {
+	token := c.Param("token")
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	request, err := h.inviteService.AcceptInvite(token, userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if request != nil {
+		c.JSON(http.StatusAccepted, gin.H{"message": "join request submitted", "request": request})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "joined room"})
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"social_media/internal/service"
+)
+
+type JoinRequestHandler struct {
+	joinRequestService service.JoinRequestService
+}
+
+// NewJoinRequestHandler creates a new JoinRequestHandler.
+func NewJoinRequestHandler(joinRequestService service.JoinRequestService) *JoinRequestHandler {
+	return &JoinRequestHandler{joinRequestService: joinRequestService}
+}
+
+type JoinRoomRequest struct {
+	Message string `json:"message"` // optional note to the admins
+}
+
+// RequestToJoin submits a join request for the room in the URL.
+func (h *JoinRequestHandler) RequestToJoin(c *gin.Context) {
+	roomID := c.Param("roomID")
+	var req JoinRoomRequest
+	// The body is optional.
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	request, err := h.joinRequestService.RequestToJoin(roomID, userID.(string), req.Message)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, request)
+}
+
+// ListPending returns the pending join requests of the room in the URL.
+func (h *JoinRequestHandler) ListPending(c *gin.Context) {
+	roomID := c.Param("roomID")
+	requesterID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	requests, err := h.joinRequestService.ListPending(roomID, requesterID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, requests)
+}
+
+// Approve approves a pending join request.
+func (h *JoinRequestHandler) Approve(c *gin.Context) {
+	roomID := c.Param("roomID")
+	requestID := c.Param("requestID")
+	requesterID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	if err := h.joinRequestService.Approve(roomID, requesterID.(string), requestID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "join request approved"})
+}
+
+// Decline declines a pending join request.
+func (h *JoinRequestHandler) Decline(c *gin.Context) {
+	roomID := c.Param("roomID")
+	requestID := c.Param("requestID")
+	requesterID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	if err := h.joinRequestService.Decline(roomID, requesterID.(string), requestID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "join request declined"})
+}
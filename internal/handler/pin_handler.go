@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"social_media/internal/domain"
+	"social_media/internal/service"
+)
+
+// PinHandler serves the pinned messages of conversations (/conversations/:id/pins)
+// and rooms (/rooms/:roomID/pins).
+type PinHandler struct {
+	pinService service.PinService
+}
+
+// NewPinHandler creates a new PinHandler.
+func NewPinHandler(pinService service.PinService) *PinHandler {
+	return &PinHandler{pinService: pinService}
+}
+
+// ListPins returns the chat's pinned messages in order.
+func (h *PinHandler) ListPins(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	pins, err := h.pinService.GetPins(userID.(string), pinChat(c))
+	if err != nil {
+		c.JSON(pinErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, pins)
+}
+
+// PinMessage pins a message of the chat below the existing pins.
+// Expected JSON: {"message_id": "uuid"}
+func (h *PinHandler) PinMessage(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	var req struct {
+		MessageID string `json:"message_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	pin, err := h.pinService.PinMessage(userID.(string), pinChat(c), req.MessageID)
+	if err != nil {
+		c.JSON(pinErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, pin)
+}
+
+// UnpinMessage unpins the message in the path.
+func (h *PinHandler) UnpinMessage(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	if err := h.pinService.UnpinMessage(userID.(string), pinChat(c), c.Param("messageID")); err != nil {
+		c.JSON(pinErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "message unpinned"})
+}
+
+// ReorderPins sets the order of the chat's pinned messages.
+// Expected JSON: {"message_ids": ["uuid", "uuid"]}
+func (h *PinHandler) ReorderPins(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	var req struct {
+		MessageIDs []string `json:"message_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.pinService.ReorderPins(userID.(string), pinChat(c), req.MessageIDs); err != nil {
+		c.JSON(pinErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "pins reordered"})
+}
+
+// pinChat reads the chat from the room or conversation path parameter.
+func pinChat(c *gin.Context) domain.ChatRef {
+	if roomID := c.Param("roomID"); roomID != "" {
+		return domain.ChatRef{Kind: domain.ChatRoom, ID: roomID}
+	}
+	return domain.ChatRef{Kind: domain.ChatConversation, ID: c.Param("id")}
+}
+
+// pinErrorStatus maps PinService errors to HTTP status codes.
+func pinErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrForbidden), errors.Is(err, service.ErrBanned):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrChatNotFound), errors.Is(err, service.ErrMessageNotFound), errors.Is(err, service.ErrNotPinned):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrAlreadyPinned):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"social_media/internal/domain"
+	"social_media/internal/service"
+)
+
+// PollHandler serves the polls of rooms (/rooms/:roomID/polls).
+type PollHandler struct {
+	pollService service.PollService
+}
+
+// NewPollHandler creates a new PollHandler.
+func NewPollHandler(pollService service.PollService) *PollHandler {
+	return &PollHandler{pollService: pollService}
+}
+
+// CreatePoll posts a poll to the room. Polls are anonymous unless "anonymous" is false.
+// Expected JSON:
+// {
+//    "question": "Where do we meet?",
+//    "options": ["Park", "Cafe"],
+//    "multiple_choice": false,
+//    "anonymous": true,
+//    "quiz": false,
+//    "correct_option": 0, // quizzes only
+//    "close_at": "2025-01-01T18:00:00Z" // optional
+// }
+func (h *PollHandler) CreatePoll(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	var req struct {
+		Question       string     `json:"question" binding:"required"`
+		Options        []string   `json:"options" binding:"required"`
+		MultipleChoice bool       `json:"multiple_choice"`
+		Anonymous      *bool      `json:"anonymous"`
+		Quiz           bool       `json:"quiz"`
+		CorrectOption  *int       `json:"correct_option"`
+		CloseAt        *time.Time `json:"close_at"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	poll := &domain.Poll{
+		Question:       req.Question,
+		MultipleChoice: req.MultipleChoice,
+		Anonymous:      req.Anonymous == nil || *req.Anonymous,
+		Quiz:           req.Quiz,
+		CorrectOption:  req.CorrectOption,
+		CloseAt:        req.CloseAt,
+	}
+	for _, text := range req.Options {
+		poll.Options = append(poll.Options, domain.PollOption{Text: text})
+	}
+	message, err := h.pollService.CreatePoll(c.Param("roomID"), userID.(string), poll)
+	if err != nil {
+		c.JSON(pollErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, message)
+}
+
+// GetPoll returns a poll with its tallies and the user's votes.
+func (h *PollHandler) GetPoll(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	poll, err := h.pollService.GetPoll(c.Param("roomID"), c.Param("messageID"), userID.(string))
+	if err != nil {
+		c.JSON(pollErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, poll)
+}
+
+// Vote casts or changes the user's vote.
+// Expected JSON: {"options": [0, 2]}
+func (h *PollHandler) Vote(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	var req struct {
+		Options []int `json:"options" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	poll, err := h.pollService.Vote(c.Param("roomID"), c.Param("messageID"), userID.(string), req.Options)
+	if err != nil {
+		c.JSON(pollErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, poll)
+}
+
+// RetractVote removes the user's vote.
+func (h *PollHandler) RetractVote(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	poll, err := h.pollService.RetractVote(c.Param("roomID"), c.Param("messageID"), userID.(string))
+	if err != nil {
+		c.JSON(pollErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, poll)
+}
+
+// ClosePoll ends voting before the deadline.
+func (h *PollHandler) ClosePoll(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	poll, err := h.pollService.ClosePoll(c.Param("roomID"), c.Param("messageID"), userID.(string))
+	if err != nil {
+		c.JSON(pollErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, poll)
+}
+
+// StreamUpdates pushes the room's polls as server-sent "poll" events whenever
+// their tallies or state change, until the client disconnects.
+func (h *PollHandler) StreamUpdates(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	updates, cancel, err := h.pollService.Subscribe(c.Param("roomID"), userID.(string))
+	if err != nil {
+		c.JSON(pollErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	defer cancel()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case poll, ok := <-updates:
+			if !ok {
+				return false
+			}
+			c.SSEvent("poll", poll)
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// pollErrorStatus maps PollService errors to HTTP status codes.
+func pollErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrForbidden), errors.Is(err, service.ErrBanned), errors.Is(err, service.ErrMuted):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrPollNotFound), errors.Is(err, service.ErrRoomNotFound),
+		errors.Is(err, service.ErrNotRoomMember), errors.Is(err, service.ErrChatNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrPollClosed), errors.Is(err, service.ErrQuizAnswered):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"social_media/internal/domain"
 	"social_media/internal/service"
 )
 
@@ -59,6 +60,67 @@ func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
 	c.JSON(http.StatusOK, updatedUser)
 }
 
+// SetDiscoverability opts the user in to or out of the user directory.
+// Expected JSON: {"discoverable": false}
+func (h *ProfileHandler) SetDiscoverability(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req struct {
+		Discoverable *bool `json:"discoverable" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updatedUser, err := h.profileService.SetDiscoverable(userID.(string), *req.Discoverable)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedUser)
+}
+
+// UpdatePrivacy changes the user's privacy settings. Omitted fields are left unchanged.
+// Each field is one of "everyone", "contacts" or "nobody".
+// Expected JSON: {"phone_visibility": "nobody", "find_by_phone": "contacts", "messages": "everyone", "group_invites": "contacts"}
+func (h *ProfileHandler) UpdatePrivacy(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req struct {
+		PhoneVisibility *domain.PrivacyAudience `json:"phone_visibility"`
+		FindByPhone     *domain.PrivacyAudience `json:"find_by_phone"`
+		Messages        *domain.PrivacyAudience `json:"messages"`
+		GroupInvites    *domain.PrivacyAudience `json:"group_invites"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updatedUser, err := h.profileService.UpdatePrivacy(userID.(string), service.PrivacyUpdate{
+		PhoneVisibility: req.PhoneVisibility,
+		FindByPhone:     req.FindByPhone,
+		Messages:        req.Messages,
+		GroupInvites:    req.GroupInvites,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedUser.Privacy)
+}
+
 // DeleteProfile deletes the user account.
 func (h *ProfileHandler) DeleteProfile(c *gin.Context) {
 	userID, exists := c.Get("userID")
@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
-	"social_media/internal/domain"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"social_media/internal/domain"
 	"social_media/internal/service"
 )
 
@@ -35,16 +38,21 @@ func (h *RoomHandler) CreateRoom(c *gin.Context) {
 	roomType := domain.RoomType(req.Type) // Convert string to domain.RoomType
 	room, err := h.roomService.CreateRoom(userID.(string), req.Name, req.Username, roomType)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(roomErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusCreated, room)
 }
 
+// UpdateRoomRequest is a partial update; omitted fields are left unchanged.
 type UpdateRoomRequest struct {
-	RoomID      string `json:"room_id" binding:"required"`
-	NewName     string `json:"new_name" binding:"required"`
-	NewUsername string `json:"new_username"`
+	RoomID           string  `json:"room_id" binding:"required"`
+	NewName          *string `json:"new_name"`
+	NewUsername      *string `json:"new_username"`
+	NewDescription   *string `json:"new_description"`
+	NewPhotoURL      *string `json:"new_photo_url"`
+	NewRules         *string `json:"new_rules"`
+	NewJoinByRequest *bool   `json:"new_join_by_request"`
 }
 
 func (h *RoomHandler) UpdateRoom(c *gin.Context) {
@@ -58,9 +66,17 @@ func (h *RoomHandler) UpdateRoom(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
 		return
 	}
-	room, err := h.roomService.UpdateRoom(req.RoomID, userID.(string), req.NewName, req.NewUsername)
+	update := service.RoomUpdate{
+		Name:          req.NewName,
+		Username:      req.NewUsername,
+		Description:   req.NewDescription,
+		PhotoURL:      req.NewPhotoURL,
+		Rules:         req.NewRules,
+		JoinByRequest: req.NewJoinByRequest,
+	}
+	room, err := h.roomService.UpdateRoom(req.RoomID, userID.(string), update)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(roomErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, room)
@@ -82,7 +98,7 @@ func (h *RoomHandler) DeleteRoom(c *gin.Context) {
 		return
 	}
 	if err := h.roomService.DeleteRoom(req.RoomID, userID.(string)); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(roomErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "room deleted"})
@@ -105,7 +121,7 @@ func (h *RoomHandler) AddMember(c *gin.Context) {
 		return
 	}
 	if err := h.roomService.AddMember(req.RoomID, requesterID.(string), req.UserID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(roomErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "member added"})
@@ -128,15 +144,16 @@ func (h *RoomHandler) RemoveMember(c *gin.Context) {
 		return
 	}
 	if err := h.roomService.RemoveMember(req.RoomID, requesterID.(string), req.UserID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(roomErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "member removed"})
 }
 
 type PromoteMemberRequest struct {
-	RoomID string `json:"room_id" binding:"required"`
-	UserID string `json:"user_id" binding:"required"`
+	RoomID      string                   `json:"room_id" binding:"required"`
+	UserID      string                   `json:"user_id" binding:"required"`
+	Permissions *domain.AdminPermissions `json:"permissions"` // defaults to everything but manage_admins
 }
 
 func (h *RoomHandler) PromoteMember(c *gin.Context) {
@@ -150,18 +167,72 @@ func (h *RoomHandler) PromoteMember(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
 		return
 	}
-	if err := h.roomService.PromoteMember(req.RoomID, requesterID.(string), req.UserID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	permissions := domain.DefaultAdminPermissions
+	if req.Permissions != nil {
+		permissions = *req.Permissions
+	}
+	if err := h.roomService.PromoteMember(req.RoomID, requesterID.(string), req.UserID, permissions); err != nil {
+		c.JSON(roomErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "member promoted to admin"})
 }
 
-type BanMemberRequest struct {
+type DemoteMemberRequest struct {
 	RoomID string `json:"room_id" binding:"required"`
 	UserID string `json:"user_id" binding:"required"`
 }
 
+func (h *RoomHandler) DemoteMember(c *gin.Context) {
+	var req DemoteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	requesterID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	if err := h.roomService.DemoteMember(req.RoomID, requesterID.(string), req.UserID); err != nil {
+		c.JSON(roomErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "admin demoted to member"})
+}
+
+type SetAdminPermissionsRequest struct {
+	RoomID      string                  `json:"room_id" binding:"required"`
+	UserID      string                  `json:"user_id" binding:"required"`
+	Permissions domain.AdminPermissions `json:"permissions"`
+}
+
+func (h *RoomHandler) SetAdminPermissions(c *gin.Context) {
+	var req SetAdminPermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	requesterID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	if err := h.roomService.SetAdminPermissions(req.RoomID, requesterID.(string), req.UserID, req.Permissions); err != nil {
+		c.JSON(roomErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "admin permissions updated"})
+}
+
+// BanMemberRequest bans a member; omitting until makes the ban permanent.
+type BanMemberRequest struct {
+	RoomID string     `json:"room_id" binding:"required"`
+	UserID string     `json:"user_id" binding:"required"`
+	Reason string     `json:"reason"`
+	Until  *time.Time `json:"until"`
+}
+
 func (h *RoomHandler) BanMember(c *gin.Context) {
 	var req BanMemberRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -173,8 +244,8 @@ func (h *RoomHandler) BanMember(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
 		return
 	}
-	if err := h.roomService.BanMember(req.RoomID, requesterID.(string), req.UserID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := h.roomService.BanMember(req.RoomID, requesterID.(string), req.UserID, req.Reason, req.Until); err != nil {
+		c.JSON(roomErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "member banned"})
@@ -197,15 +268,66 @@ func (h *RoomHandler) UnbanMember(c *gin.Context) {
 		return
 	}
 	if err := h.roomService.UnbanMember(req.RoomID, requesterID.(string), req.UserID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(roomErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "member unbanned"})
 }
 
+// MuteMemberRequest mutes a member; omitting until mutes them until unmuted.
+type MuteMemberRequest struct {
+	RoomID string     `json:"room_id" binding:"required"`
+	UserID string     `json:"user_id" binding:"required"`
+	Reason string     `json:"reason"`
+	Until  *time.Time `json:"until"`
+}
+
+func (h *RoomHandler) MuteMember(c *gin.Context) {
+	var req MuteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	requesterID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	if err := h.roomService.MuteMember(req.RoomID, requesterID.(string), req.UserID, req.Reason, req.Until); err != nil {
+		c.JSON(roomErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "member muted"})
+}
+
+type UnmuteMemberRequest struct {
+	RoomID string `json:"room_id" binding:"required"`
+	UserID string `json:"user_id" binding:"required"`
+}
+
+func (h *RoomHandler) UnmuteMember(c *gin.Context) {
+	var req UnmuteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	requesterID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	if err := h.roomService.UnmuteMember(req.RoomID, requesterID.(string), req.UserID); err != nil {
+		c.JSON(roomErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "member unmuted"})
+}
+
 type SendRoomMessageRequest struct {
-	RoomID  string `json:"room_id" binding:"required"`
-	Content string `json:"content" binding:"required"`
+	RoomID    string                 `json:"room_id" binding:"required"`
+	Content   string                 `json:"content" binding:"required"`
+	Entities  []domain.MessageEntity `json:"entities"`
+	ParseMode string                 `json:"parse_mode"`
 }
 
 func (h *RoomHandler) SendMessage(c *gin.Context) {
@@ -214,14 +336,19 @@ func (h *RoomHandler) SendMessage(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	content, entities, err := messageBody(req.Content, req.ParseMode, req.Entities)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	senderID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
 		return
 	}
-	message, err := h.roomService.SendMessage(req.RoomID, senderID.(string), req.Content)
+	message, err := h.roomService.SendMessage(req.RoomID, senderID.(string), content, entities)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(roomErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, message)
@@ -244,19 +371,109 @@ func (h *RoomHandler) DeleteMessage(c *gin.Context) {
 		return
 	}
 	if err := h.roomService.DeleteMessage(req.RoomID, requesterID.(string), req.MessageID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(roomErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "message deleted"})
 }
 
-func (h *RoomHandler) GetMessages(c *gin.Context) {
+// GetRoom returns the full room card, including its member count. Private rooms
+// are only visible to their members.
+func (h *RoomHandler) GetRoom(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
 	roomID := c.Param("roomID")
-	messages, err := h.roomService.GetMessages(roomID)
+	room, err := h.roomService.GetRoom(roomID, userID.(string))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrRoomNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrBanned):
+			status = http.StatusForbidden
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, room)
+}
+
+// SearchRooms searches the public room directory.
+// Query parameters: q, limit, offset.
+func (h *RoomHandler) SearchRooms(c *gin.Context) {
+	limit, offset := pagination(c)
+	rooms, err := h.roomService.SearchPublicRooms(c.Query("q"), limit, offset)
+	if err != nil {
+		c.JSON(roomErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rooms)
+}
+
+// GetRoomByUsername returns a preview of a public room.
+func (h *RoomHandler) GetRoomByUsername(c *gin.Context) {
+	room, err := h.roomService.GetPublicRoom(c.Param("username"))
 	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, room)
+}
+
+// JoinRoom lets the authenticated user join a public room.
+func (h *RoomHandler) JoinRoom(c *gin.Context) {
+	roomID := c.Param("roomID")
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	if err := h.roomService.JoinRoom(roomID, userID.(string)); err != nil {
+		c.JSON(roomErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "joined room"})
+}
+
+type TransferOwnershipRequest struct {
+	NewOwnerID string `json:"new_owner_id" binding:"required"`
+	Password   string `json:"password" binding:"required"`
+}
+
+func (h *RoomHandler) TransferOwnership(c *gin.Context) {
+	var req TransferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	roomID := c.Param("roomID")
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	if err := h.roomService.TransferOwnership(roomID, userID.(string), req.NewOwnerID, req.Password); err != nil {
+		c.JSON(roomErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "ownership transferred"})
+}
+
+func (h *RoomHandler) GetMessages(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	roomID := c.Param("roomID")
+	messages, err := h.roomService.GetMessages(roomID, userID.(string))
+	if err != nil {
+		c.JSON(roomErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
 	c.JSON(http.StatusOK, messages)
 }
 
@@ -264,8 +481,23 @@ func (h *RoomHandler) GetMembers(c *gin.Context) {
 	roomID := c.Param("roomID")
 	members, err := h.roomService.GetMembers(roomID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(roomErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, members)
 }
+
+// roomErrorStatus maps RoomService errors to HTTP status codes.
+func roomErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrForbidden), errors.Is(err, service.ErrBanned), errors.Is(err, service.ErrMuted):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrRoomNotFound), errors.Is(err, service.ErrNotRoomMember),
+		errors.Is(err, service.ErrChatNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrInvalidRoleTransition), errors.Is(err, service.ErrOwnerMustTransfer):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"social_media/internal/domain"
+	"social_media/internal/service"
+)
+
+type SearchHandler struct {
+	searchService service.SearchService
+}
+
+// NewSearchHandler creates a new SearchHandler.
+func NewSearchHandler(searchService service.SearchService) *SearchHandler {
+	return &SearchHandler{searchService: searchService}
+}
+
+// SearchMessages searches the authenticated user's message history.
+// Query parameters: q (required), sender_id, conversation_id, room_id,
+// from and to (RFC3339), limit, offset.
+func (h *SearchHandler) SearchMessages(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	filter := domain.MessageSearchFilter{
+		Query:          c.Query("q"),
+		SenderID:       c.Query("sender_id"),
+		ConversationID: c.Query("conversation_id"),
+		RoomID:         c.Query("room_id"),
+	}
+	var err error
+	if filter.From, err = parseTimeQuery(c, "from"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from date"})
+		return
+	}
+	if filter.To, err = parseTimeQuery(c, "to"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to date"})
+		return
+	}
+	filter.Limit, filter.Offset = pagination(c)
+
+	hits, err := h.searchService.SearchMessages(userID.(string), filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, hits)
+}
+
+// parseTimeQuery reads an optional RFC3339 timestamp from the query string.
+func parseTimeQuery(c *gin.Context, key string) (*time.Time, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
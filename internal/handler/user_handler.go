@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"social_media/internal/service"
+)
+
+type UserHandler struct {
+	userService service.UserService
+}
+
+// NewUserHandler creates a new UserHandler.
+func NewUserHandler(userService service.UserService) *UserHandler {
+	return &UserHandler{userService: userService}
+}
+
+// SearchUsers searches the user directory.
+// Query parameters: q (required), limit, offset.
+func (h *UserHandler) SearchUsers(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	limit, offset := pagination(c)
+	users, err := h.userService.SearchUsers(userID.(string), c.Query("q"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Only expose public fields; phone numbers are never part of search results.
+	results := make([]gin.H, 0, len(users))
+	for _, user := range users {
+		results = append(results, gin.H{
+			"id":       user.ID,
+			"name":     user.Name,
+			"username": user.Username,
+		})
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// GetUser returns another user's public profile.
+// The phone number is included only if their privacy settings allow it.
+func (h *UserHandler) GetUser(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	profile, err := h.userService.GetPublicProfile(userID.(string), c.Param("userID"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, profile)
+}
+
+// pagination reads the limit and offset query parameters.
+// Missing or malformed values are returned as zero and normalized by the services.
+func pagination(c *gin.Context) (int, int) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+	return limit, offset
+}
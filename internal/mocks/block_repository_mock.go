@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+)
+
+type BlockRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *BlockRepositoryMock) Block(block *domain.UserBlock) error {
+	args := m.Called(block)
+	return args.Error(0)
+}
+
+func (m *BlockRepositoryMock) Unblock(blockerID, blockedID string) error {
+	args := m.Called(blockerID, blockedID)
+	return args.Error(0)
+}
+
+func (m *BlockRepositoryMock) IsBlocked(blockerID, blockedID string) (bool, error) {
+	args := m.Called(blockerID, blockedID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *BlockRepositoryMock) FindByBlocker(blockerID string) ([]*domain.UserBlock, error) {
+	args := m.Called(blockerID)
+	if blocks := args.Get(0); blocks != nil {
+		return blocks.([]*domain.UserBlock), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
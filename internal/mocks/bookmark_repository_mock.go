@@ -0,0 +1,49 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+)
+
+type BookmarkRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *BookmarkRepositoryMock) Create(bookmark *domain.Bookmark) error {
+	args := m.Called(bookmark)
+	return args.Error(0)
+}
+
+func (m *BookmarkRepositoryMock) Update(bookmark *domain.Bookmark) error {
+	args := m.Called(bookmark)
+	return args.Error(0)
+}
+
+func (m *BookmarkRepositoryMock) Delete(bookmarkID string) error {
+	args := m.Called(bookmarkID)
+	return args.Error(0)
+}
+
+func (m *BookmarkRepositoryMock) FindByID(bookmarkID string) (*domain.Bookmark, error) {
+	args := m.Called(bookmarkID)
+	if b := args.Get(0); b != nil {
+		return b.(*domain.Bookmark), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *BookmarkRepositoryMock) FindByMessage(userID string, source domain.ChatRef, messageID string) (*domain.Bookmark, error) {
+	args := m.Called(userID, source, messageID)
+	if b := args.Get(0); b != nil {
+		return b.(*domain.Bookmark), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *BookmarkRepositoryMock) FindByUser(userID string, filter domain.BookmarkFilter) ([]*domain.Bookmark, error) {
+	args := m.Called(userID, filter)
+	if bookmarks := args.Get(0); bookmarks != nil {
+		return bookmarks.([]*domain.Bookmark), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,41 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+)
+
+type ChatFolderRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *ChatFolderRepositoryMock) Create(folder *domain.ChatFolder) error {
+	args := m.Called(folder)
+	return args.Error(0)
+}
+
+func (m *ChatFolderRepositoryMock) Update(folder *domain.ChatFolder) error {
+	args := m.Called(folder)
+	return args.Error(0)
+}
+
+func (m *ChatFolderRepositoryMock) Delete(folderID string) error {
+	args := m.Called(folderID)
+	return args.Error(0)
+}
+
+func (m *ChatFolderRepositoryMock) FindByID(folderID string) (*domain.ChatFolder, error) {
+	args := m.Called(folderID)
+	if f := args.Get(0); f != nil {
+		return f.(*domain.ChatFolder), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ChatFolderRepositoryMock) FindByUser(userID string) ([]*domain.ChatFolder, error) {
+	args := m.Called(userID)
+	if folders := args.Get(0); folders != nil {
+		return folders.([]*domain.ChatFolder), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,57 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+)
+
+type ChatStateRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *ChatStateRepositoryMock) Get(userID string, chat domain.ChatRef) (*domain.ChatState, error) {
+	args := m.Called(userID, chat)
+	if s := args.Get(0); s != nil {
+		return s.(*domain.ChatState), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ChatStateRepositoryMock) Save(state *domain.ChatState) error {
+	args := m.Called(state)
+	return args.Error(0)
+}
+
+func (m *ChatStateRepositoryMock) FindByUser(userID string) ([]*domain.ChatState, error) {
+	args := m.Called(userID)
+	if states := args.Get(0); states != nil {
+		return states.([]*domain.ChatState), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ChatStateRepositoryMock) FindUnread(userID string) ([]domain.ChatRef, error) {
+	args := m.Called(userID)
+	if chats := args.Get(0); chats != nil {
+		return chats.([]domain.ChatRef), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ChatStateRepositoryMock) FindMuted(chat domain.ChatRef, userIDs []string, now time.Time) ([]string, error) {
+	args := m.Called(chat, userIDs, now)
+	if userIDs := args.Get(0); userIDs != nil {
+		return userIDs.([]string), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ChatStateRepositoryMock) CountUnreadMentions(userID string) (map[string]int, error) {
+	args := m.Called(userID)
+	if counts := args.Get(0); counts != nil {
+		return counts.(map[string]int), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
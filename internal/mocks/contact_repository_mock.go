@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+)
+
+type ContactRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *ContactRepositoryMock) Save(contact *domain.Contact) error {
+	args := m.Called(contact)
+	return args.Error(0)
+}
+
+func (m *ContactRepositoryMock) Delete(ownerID, userID string) error {
+	args := m.Called(ownerID, userID)
+	return args.Error(0)
+}
+
+func (m *ContactRepositoryMock) IsContact(ownerID, userID string) (bool, error) {
+	args := m.Called(ownerID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *ContactRepositoryMock) FindByOwner(ownerID string) ([]*domain.Contact, error) {
+	args := m.Called(ownerID)
+	if contacts := args.Get(0); contacts != nil {
+		return contacts.([]*domain.Contact), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
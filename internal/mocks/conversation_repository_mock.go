@@ -37,3 +37,31 @@ func (m *ConversationRepositoryMock) FindByID(id string) (*domain.Conversation,
 	}
 	return nil, args.Error(1)
 }
+
+func (m *ConversationRepositoryMock) UpdateStatus(convo *domain.Conversation) error {
+	args := m.Called(convo)
+	return args.Error(0)
+}
+
+func (m *ConversationRepositoryMock) Delete(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *ConversationRepositoryMock) FindRequests(userID string) ([]*domain.Conversation, error) {
+	args := m.Called(userID)
+	if convos := args.Get(0); convos != nil {
+		return convos.([]*domain.Conversation), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ConversationRepositoryMock) AddParticipant(convoID, userID string) error {
+	args := m.Called(convoID, userID)
+	return args.Error(0)
+}
+
+func (m *ConversationRepositoryMock) RemoveParticipant(convoID, userID string) error {
+	args := m.Called(convoID, userID)
+	return args.Error(0)
+}
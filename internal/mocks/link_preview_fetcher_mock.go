@@ -0,0 +1,20 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+)
+
+type LinkPreviewFetcherMock struct {
+	mock.Mock
+}
+
+func (m *LinkPreviewFetcherMock) Fetch(ctx context.Context, rawURL string) (*domain.LinkPreview, error) {
+	args := m.Called(ctx, rawURL)
+	if preview := args.Get(0); preview != nil {
+		return preview.(*domain.LinkPreview), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,23 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+)
+
+type LinkPreviewRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *LinkPreviewRepositoryMock) FindByURL(url string) (*domain.LinkPreview, error) {
+	args := m.Called(url)
+	if preview := args.Get(0); preview != nil {
+		return preview.(*domain.LinkPreview), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *LinkPreviewRepositoryMock) Save(preview *domain.LinkPreview) error {
+	args := m.Called(preview)
+	return args.Error(0)
+}
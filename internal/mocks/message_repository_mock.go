@@ -39,3 +39,16 @@ func (m *MessageRepositoryMock) FindByID(id string) (*domain.Message, error) {
 	}
 	return nil, args.Error(1)
 }
+
+func (m *MessageRepositoryMock) FindPendingPreviews(limit int) ([]*domain.Message, error) {
+	args := m.Called(limit)
+	if messages := args.Get(0); messages != nil {
+		return messages.([]*domain.Message), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MessageRepositoryMock) SetLinkPreview(messageID string, preview *domain.LinkPreview) error {
+	args := m.Called(messageID, preview)
+	return args.Error(0)
+}
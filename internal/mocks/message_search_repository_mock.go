@@ -0,0 +1,18 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+)
+
+type MessageSearchRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *MessageSearchRepositoryMock) Search(userID string, filter domain.MessageSearchFilter) ([]*domain.MessageSearchHit, error) {
+	args := m.Called(userID, filter)
+	if hits := args.Get(0); hits != nil {
+		return hits.([]*domain.MessageSearchHit), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
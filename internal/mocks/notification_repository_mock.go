@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+)
+
+type NotificationRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *NotificationRepositoryMock) Create(notification *domain.Notification) error {
+	args := m.Called(notification)
+	return args.Error(0)
+}
+
+func (m *NotificationRepositoryMock) CreateBatch(notifications []*domain.Notification) error {
+	args := m.Called(notifications)
+	return args.Error(0)
+}
+
+func (m *NotificationRepositoryMock) FindByUser(userID string, unreadOnly bool, limit, offset int) ([]*domain.Notification, error) {
+	args := m.Called(userID, unreadOnly, limit, offset)
+	if notifications := args.Get(0); notifications != nil {
+		return notifications.([]*domain.Notification), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *NotificationRepositoryMock) MarkRead(userID, notificationID string) error {
+	args := m.Called(userID, notificationID)
+	return args.Error(0)
+}
@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+)
+
+type PinRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *PinRepositoryMock) Add(pin *domain.PinnedMessage) error {
+	args := m.Called(pin)
+	return args.Error(0)
+}
+
+func (m *PinRepositoryMock) Remove(chat domain.ChatRef, messageID string) error {
+	args := m.Called(chat, messageID)
+	return args.Error(0)
+}
+
+func (m *PinRepositoryMock) FindByChat(chat domain.ChatRef) ([]*domain.PinnedMessage, error) {
+	args := m.Called(chat)
+	if pins := args.Get(0); pins != nil {
+		return pins.([]*domain.PinnedMessage), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *PinRepositoryMock) Reorder(chat domain.ChatRef, messageIDs []string) error {
+	args := m.Called(chat, messageIDs)
+	return args.Error(0)
+}
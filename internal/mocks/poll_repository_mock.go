@@ -0,0 +1,38 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+)
+
+type PollRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *PollRepositoryMock) FindByMessage(messageID, viewerID string) (*domain.Poll, error) {
+	args := m.Called(messageID, viewerID)
+	if poll := args.Get(0); poll != nil {
+		return poll.(*domain.Poll), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *PollRepositoryMock) SetVotes(messageID, userID string, options []int) error {
+	args := m.Called(messageID, userID, options)
+	return args.Error(0)
+}
+
+func (m *PollRepositoryMock) Close(messageID string, at time.Time) error {
+	args := m.Called(messageID, at)
+	return args.Error(0)
+}
+
+func (m *PollRepositoryMock) CloseExpired(now time.Time) ([]string, error) {
+	args := m.Called(now)
+	if messageIDs := args.Get(0); messageIDs != nil {
+		return messageIDs.([]string), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
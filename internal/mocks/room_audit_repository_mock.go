@@ -0,0 +1,23 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+)
+
+type RoomAuditRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *RoomAuditRepositoryMock) Append(entry *domain.RoomAuditEntry) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func (m *RoomAuditRepositoryMock) Find(roomID string, filter domain.RoomAuditFilter) ([]*domain.RoomAuditEntry, error) {
+	args := m.Called(roomID, filter)
+	if entries := args.Get(0); entries != nil {
+		return entries.([]*domain.RoomAuditEntry), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
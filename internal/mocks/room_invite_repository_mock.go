@@ -0,0 +1,43 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+)
+
+type RoomInviteRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *RoomInviteRepositoryMock) Create(invite *domain.RoomInvite) error {
+	args := m.Called(invite)
+	return args.Error(0)
+}
+
+func (m *RoomInviteRepositoryMock) FindByToken(token string) (*domain.RoomInvite, error) {
+	args := m.Called(token)
+	if i := args.Get(0); i != nil {
+		return i.(*domain.RoomInvite), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *RoomInviteRepositoryMock) FindByRoom(roomID string) ([]*domain.RoomInvite, error) {
+	args := m.Called(roomID)
+	if invites := args.Get(0); invites != nil {
+		return invites.([]*domain.RoomInvite), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *RoomInviteRepositoryMock) Revoke(token string) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *RoomInviteRepositoryMock) Redeem(token string, now time.Time) (bool, error) {
+	args := m.Called(token, now)
+	return args.Bool(0), args.Error(1)
+}
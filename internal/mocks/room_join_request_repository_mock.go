@@ -0,0 +1,44 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+)
+
+type RoomJoinRequestRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *RoomJoinRequestRepositoryMock) Create(request *domain.RoomJoinRequest) error {
+	args := m.Called(request)
+	return args.Error(0)
+}
+
+func (m *RoomJoinRequestRepositoryMock) FindByID(id string) (*domain.RoomJoinRequest, error) {
+	args := m.Called(id)
+	if r := args.Get(0); r != nil {
+		return r.(*domain.RoomJoinRequest), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *RoomJoinRequestRepositoryMock) FindPending(roomID, userID string) (*domain.RoomJoinRequest, error) {
+	args := m.Called(roomID, userID)
+	if r := args.Get(0); r != nil {
+		return r.(*domain.RoomJoinRequest), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *RoomJoinRequestRepositoryMock) FindPendingByRoom(roomID string) ([]*domain.RoomJoinRequest, error) {
+	args := m.Called(roomID)
+	if requests := args.Get(0); requests != nil {
+		return requests.([]*domain.RoomJoinRequest), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *RoomJoinRequestRepositoryMock) UpdateStatus(request *domain.RoomJoinRequest) error {
+	args := m.Called(request)
+	return args.Error(0)
+}
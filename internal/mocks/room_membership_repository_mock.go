@@ -19,6 +19,16 @@ func (m *RoomMembershipRepositoryMock) UpdateMemberRole(roomID, userID string, r
 	return args.Error(0)
 }
 
+func (m *RoomMembershipRepositoryMock) UpdateMemberPermissions(roomID, userID string, permissions domain.AdminPermissions) error {
+	args := m.Called(roomID, userID, permissions)
+	return args.Error(0)
+}
+
+func (m *RoomMembershipRepositoryMock) SetAdmin(roomID, userID string, permissions domain.AdminPermissions, promotedBy string) error {
+	args := m.Called(roomID, userID, permissions, promotedBy)
+	return args.Error(0)
+}
+
 func (m *RoomMembershipRepositoryMock) RemoveMember(roomID, userID string) error {
 	args := m.Called(roomID, userID)
 	return args.Error(0)
@@ -41,3 +51,16 @@ func (m *RoomMembershipRepositoryMock) GetMemberRole(roomID, userID string) (dom
 	args := m.Called(roomID, userID)
 	return args.Get(0).(domain.RoomMembershipRole), args.Error(1)
 }
+
+func (m *RoomMembershipRepositoryMock) GetMembership(roomID, userID string) (*domain.RoomMembership, error) {
+	args := m.Called(roomID, userID)
+	if membership := args.Get(0); membership != nil {
+		return membership.(*domain.RoomMembership), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *RoomMembershipRepositoryMock) CountMembers(roomID string) (int, error) {
+	args := m.Called(roomID)
+	return args.Int(0), args.Error(1)
+}
@@ -24,8 +24,8 @@ func (m *RoomMessageRepositoryMock) Delete(messageID string) error {
 	return args.Error(0)
 }
 
-func (m *RoomMessageRepositoryMock) FindByRoom(roomID string) ([]*domain.RoomMessage, error) {
-	args := m.Called(roomID)
+func (m *RoomMessageRepositoryMock) FindByRoom(roomID, viewerID string) ([]*domain.RoomMessage, error) {
+	args := m.Called(roomID, viewerID)
 	if messages := args.Get(0); messages != nil {
 		return messages.([]*domain.RoomMessage), args.Error(1)
 	}
@@ -39,3 +39,16 @@ func (m *RoomMessageRepositoryMock) FindByID(messageID string) (*domain.RoomMess
 	}
 	return nil, args.Error(1)
 }
+
+func (m *RoomMessageRepositoryMock) FindPendingPreviews(limit int) ([]*domain.RoomMessage, error) {
+	args := m.Called(limit)
+	if messages := args.Get(0); messages != nil {
+		return messages.([]*domain.RoomMessage), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *RoomMessageRepositoryMock) SetLinkPreview(messageID string, preview *domain.LinkPreview) error {
+	args := m.Called(messageID, preview)
+	return args.Error(0)
+}
@@ -39,3 +39,24 @@ func (m *RoomRepositoryMock) FindByUsername(username string) (*domain.Room, erro
 	}
 	return nil, args.Error(1)
 }
+
+func (m *RoomRepositoryMock) FindByMember(userID string) ([]*domain.Room, error) {
+	args := m.Called(userID)
+	if rooms := args.Get(0); rooms != nil {
+		return rooms.([]*domain.Room), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *RoomRepositoryMock) SearchPublic(query string, limit, offset int) ([]*domain.RoomDetails, error) {
+	args := m.Called(query, limit, offset)
+	if rooms := args.Get(0); rooms != nil {
+		return rooms.([]*domain.RoomDetails), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *RoomRepositoryMock) TransferOwnership(roomID, fromUserID, toUserID string) error {
+	args := m.Called(roomID, fromUserID, toUserID)
+	return args.Error(0)
+}
@@ -0,0 +1,38 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+)
+
+type RoomRestrictionRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *RoomRestrictionRepositoryMock) Upsert(restriction *domain.RoomRestriction) error {
+	args := m.Called(restriction)
+	return args.Error(0)
+}
+
+func (m *RoomRestrictionRepositoryMock) Find(roomID, userID string, kind domain.RestrictionKind) (*domain.RoomRestriction, error) {
+	args := m.Called(roomID, userID, kind)
+	if restriction := args.Get(0); restriction != nil {
+		return restriction.(*domain.RoomRestriction), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *RoomRestrictionRepositoryMock) Delete(roomID, userID string, kind domain.RestrictionKind) error {
+	args := m.Called(roomID, userID, kind)
+	return args.Error(0)
+}
+
+func (m *RoomRestrictionRepositoryMock) FindExpired(now time.Time, limit int) ([]*domain.RoomRestriction, error) {
+	args := m.Called(now, limit)
+	if restrictions := args.Get(0); restrictions != nil {
+		return restrictions.([]*domain.RoomRestriction), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -22,6 +22,14 @@ func (m *UserRepositoryMock) FindByPhone(phone string) (*domain.User, error) {
 	return nil, args.Error(1)
 }
 
+func (m *UserRepositoryMock) FindByPhones(phones, phoneHashes []string) ([]*domain.User, error) {
+	args := m.Called(phones, phoneHashes)
+	if users := args.Get(0); users != nil {
+		return users.([]*domain.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *UserRepositoryMock) FindByUsername(username string) (*domain.User, error) {
 	args := m.Called(username)
 	if u := args.Get(0); u != nil {
@@ -47,3 +55,11 @@ func (m *UserRepositoryMock) Delete(user *domain.User) error {
 	args := m.Called(user)
 	return args.Error(0)
 }
+
+func (m *UserRepositoryMock) Search(query, searcherID string, limit, offset int) ([]*domain.User, error) {
+	args := m.Called(query, searcherID, limit, offset)
+	if users := args.Get(0); users != nil {
+		return users.([]*domain.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"social_media/internal/domain"
+)
+
+type blockRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewBlockRepository(pool *pgxpool.Pool) domain.BlockRepository {
+	return &blockRepository{pool: pool}
+}
+
+func (r *blockRepository) Block(block *domain.UserBlock) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO user_blocks (blocker_id, blocked_id, created_at) VALUES ($1, $2, $3)
+	          ON CONFLICT (blocker_id, blocked_id) DO NOTHING`
+	_, err := r.pool.Exec(ctx, query, block.BlockerID, block.BlockedID, block.CreatedAt)
+	return err
+}
+
+func (r *blockRepository) Unblock(blockerID, blockedID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2`
+	_, err := r.pool.Exec(ctx, query, blockerID, blockedID)
+	return err
+}
+
+func (r *blockRepository) IsBlocked(blockerID, blockedID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT EXISTS (SELECT 1 FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2)`
+	var blocked bool
+	if err := r.pool.QueryRow(ctx, query, blockerID, blockedID).Scan(&blocked); err != nil {
+		return false, err
+	}
+	return blocked, nil
+}
+
+func (r *blockRepository) FindByBlocker(blockerID string) ([]*domain.UserBlock, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT blocker_id, blocked_id, created_at FROM user_blocks
+	          WHERE blocker_id = $1 ORDER BY created_at DESC`
+	rows, err := r.pool.Query(ctx, query, blockerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blocks []*domain.UserBlock
+	for rows.Next() {
+		var b domain.UserBlock
+		if err := rows.Scan(&b.BlockerID, &b.BlockedID, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, &b)
+	}
+	return blocks, rows.Err()
+}
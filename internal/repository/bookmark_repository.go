@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"social_media/internal/domain"
+)
+
+// bookmarkColumns is the column list scanBookmark expects.
+const bookmarkColumns = `id, user_id, source_kind, source_chat_id, message_id, sender_id, content,
+	forwarded_from, sent_at, note, tags, created_at`
+
+type bookmarkRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewBookmarkRepository(pool *pgxpool.Pool) domain.BookmarkRepository {
+	return &bookmarkRepository{pool: pool}
+}
+
+func (r *bookmarkRepository) Create(bookmark *domain.Bookmark) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	forwardedFrom, err := encodeForwardOrigin(bookmark.Snapshot.ForwardedFrom)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO bookmarks (id, user_id, source_kind, source_chat_id, message_id, sender_id, content,
+	              forwarded_from, sent_at, note, tags, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+	_, err = r.pool.Exec(ctx, query, bookmark.ID, bookmark.UserID, bookmark.Source.Kind, bookmark.Source.ID,
+		bookmark.MessageID, bookmark.Snapshot.SenderID, bookmark.Snapshot.Content, forwardedFrom,
+		bookmark.Snapshot.SentAt, bookmark.Note, bookmark.Tags, bookmark.CreatedAt)
+	return err
+}
+
+func (r *bookmarkRepository) Update(bookmark *domain.Bookmark) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `UPDATE bookmarks SET note = $1, tags = $2 WHERE id = $3`
+	_, err := r.pool.Exec(ctx, query, bookmark.Note, bookmark.Tags, bookmark.ID)
+	return err
+}
+
+func (r *bookmarkRepository) Delete(bookmarkID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `DELETE FROM bookmarks WHERE id = $1`
+	_, err := r.pool.Exec(ctx, query, bookmarkID)
+	return err
+}
+
+func (r *bookmarkRepository) FindByID(bookmarkID string) (*domain.Bookmark, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + bookmarkColumns + ` FROM bookmarks WHERE id = $1`
+	bookmark, err := scanBookmark(r.pool.QueryRow(ctx, query, bookmarkID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return bookmark, err
+}
+
+func (r *bookmarkRepository) FindByMessage(userID string, source domain.ChatRef, messageID string) (*domain.Bookmark, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + bookmarkColumns + ` FROM bookmarks
+	          WHERE user_id = $1 AND source_kind = $2 AND message_id = $3`
+	bookmark, err := scanBookmark(r.pool.QueryRow(ctx, query, userID, source.Kind, messageID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return bookmark, err
+}
+
+func (r *bookmarkRepository) FindByUser(userID string, filter domain.BookmarkFilter) ([]*domain.Bookmark, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + bookmarkColumns + ` FROM bookmarks
+	          WHERE user_id = $1
+	            AND ($2 = '' OR $2 = ANY(tags))
+	            AND ($3 = '' OR source_kind = $3)
+	            AND ($4 = '' OR source_chat_id::text = $4)
+	          ORDER BY created_at DESC
+	          LIMIT $5 OFFSET $6`
+	rows, err := r.pool.Query(ctx, query, userID, filter.Tag, string(filter.SourceKind), filter.SourceID,
+		filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookmarks []*domain.Bookmark
+	for rows.Next() {
+		bookmark, err := scanBookmark(rows)
+		if err != nil {
+			return nil, err
+		}
+		bookmarks = append(bookmarks, bookmark)
+	}
+	return bookmarks, rows.Err()
+}
+
+func scanBookmark(row pgx.Row) (*domain.Bookmark, error) {
+	var b domain.Bookmark
+	var forwardedFrom []byte
+	err := row.Scan(&b.ID, &b.UserID, &b.Source.Kind, &b.Source.ID, &b.MessageID, &b.Snapshot.SenderID,
+		&b.Snapshot.Content, &forwardedFrom, &b.Snapshot.SentAt, &b.Note, &b.Tags, &b.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if b.Snapshot.ForwardedFrom, err = decodeForwardOrigin(forwardedFrom); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
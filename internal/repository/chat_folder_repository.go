@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"social_media/internal/domain"
+)
+
+type chatFolderRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewChatFolderRepository(pool *pgxpool.Pool) domain.ChatFolderRepository {
+	return &chatFolderRepository{pool: pool}
+}
+
+func (r *chatFolderRepository) Create(folder *domain.ChatFolder) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rules, err := json.Marshal(folder.Rules)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO chat_folders (id, user_id, name, rules, created_at) VALUES ($1, $2, $3, $4, $5)`
+	_, err = r.pool.Exec(ctx, query, folder.ID, folder.UserID, folder.Name, rules, folder.CreatedAt)
+	return err
+}
+
+func (r *chatFolderRepository) Update(folder *domain.ChatFolder) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rules, err := json.Marshal(folder.Rules)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE chat_folders SET name = $1, rules = $2 WHERE id = $3`
+	_, err = r.pool.Exec(ctx, query, folder.Name, rules, folder.ID)
+	return err
+}
+
+func (r *chatFolderRepository) Delete(folderID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `DELETE FROM chat_folders WHERE id = $1`
+	_, err := r.pool.Exec(ctx, query, folderID)
+	return err
+}
+
+func (r *chatFolderRepository) FindByID(folderID string) (*domain.ChatFolder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT id, user_id, name, rules, created_at FROM chat_folders WHERE id = $1`
+	folder, err := scanChatFolder(r.pool.QueryRow(ctx, query, folderID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return folder, err
+}
+
+func (r *chatFolderRepository) FindByUser(userID string) ([]*domain.ChatFolder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT id, user_id, name, rules, created_at FROM chat_folders
+	          WHERE user_id = $1 ORDER BY created_at ASC`
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var folders []*domain.ChatFolder
+	for rows.Next() {
+		folder, err := scanChatFolder(rows)
+		if err != nil {
+			return nil, err
+		}
+		folders = append(folders, folder)
+	}
+	return folders, rows.Err()
+}
+
+// scanChatFolder reads a chat_folders row, decoding the rules document.
+func scanChatFolder(row pgx.Row) (*domain.ChatFolder, error) {
+	var folder domain.ChatFolder
+	var rules []byte
+	if err := row.Scan(&folder.ID, &folder.UserID, &folder.Name, &rules, &folder.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(rules, &folder.Rules); err != nil {
+		return nil, err
+	}
+	return &folder, nil
+}
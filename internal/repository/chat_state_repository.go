@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"social_media/internal/domain"
+)
+
+type chatStateRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewChatStateRepository(pool *pgxpool.Pool) domain.ChatStateRepository {
+	return &chatStateRepository{pool: pool}
+}
+
+func (r *chatStateRepository) Get(userID string, chat domain.ChatRef) (*domain.ChatState, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT user_id, chat_kind, chat_id, archived, pin_order, muted_until, last_read_at
+	          FROM chat_states WHERE user_id = $1 AND chat_kind = $2 AND chat_id = $3`
+	state, err := scanChatState(r.pool.QueryRow(ctx, query, userID, chat.Kind, chat.ID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return state, err
+}
+
+func (r *chatStateRepository) Save(state *domain.ChatState) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO chat_states (user_id, chat_kind, chat_id, archived, pin_order, muted_until, last_read_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7)
+	          ON CONFLICT (user_id, chat_kind, chat_id) DO UPDATE
+	          SET archived = EXCLUDED.archived, pin_order = EXCLUDED.pin_order,
+	              muted_until = EXCLUDED.muted_until, last_read_at = EXCLUDED.last_read_at`
+	_, err := r.pool.Exec(ctx, query, state.UserID, state.Chat.Kind, state.Chat.ID, state.Archived,
+		state.PinOrder, state.MutedUntil, state.LastReadAt)
+	return err
+}
+
+func (r *chatStateRepository) FindByUser(userID string) ([]*domain.ChatState, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT user_id, chat_kind, chat_id, archived, pin_order, muted_until, last_read_at
+	          FROM chat_states WHERE user_id = $1`
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var states []*domain.ChatState
+	for rows.Next() {
+		state, err := scanChatState(rows)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+	return states, rows.Err()
+}
+
+func (r *chatStateRepository) FindUnread(userID string) ([]domain.ChatRef, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT 'conversation', cp.conversation_id::text
+	          FROM conversation_participants cp
+	          LEFT JOIN chat_states s ON s.user_id = cp.user_id AND s.chat_kind = 'conversation' AND s.chat_id = cp.conversation_id
+	          WHERE cp.user_id = $1
+	            AND EXISTS (SELECT 1 FROM messages m
+	                        WHERE m.conversation_id = cp.conversation_id AND m.sender_id <> $1
+	                          AND (s.last_read_at IS NULL OR m.created_at > s.last_read_at))
+	          UNION ALL
+	          SELECT 'room', rm.room_id::text
+	          FROM room_memberships rm
+	          LEFT JOIN chat_states s ON s.user_id = rm.user_id AND s.chat_kind = 'room' AND s.chat_id = rm.room_id
+	          WHERE rm.user_id = $1 AND rm.role <> 'banned'
+	            AND EXISTS (SELECT 1 FROM room_messages m
+	                        WHERE m.room_id = rm.room_id AND m.sender_id <> $1
+	                          AND (s.last_read_at IS NULL OR m.created_at > s.last_read_at))`
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chats []domain.ChatRef
+	for rows.Next() {
+		var chat domain.ChatRef
+		if err := rows.Scan(&chat.Kind, &chat.ID); err != nil {
+			return nil, err
+		}
+		chats = append(chats, chat)
+	}
+	return chats, rows.Err()
+}
+
+func (r *chatStateRepository) FindMuted(chat domain.ChatRef, userIDs []string, now time.Time) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT user_id::text FROM chat_states
+	          WHERE chat_kind = $1 AND chat_id = $2 AND user_id = ANY($3::uuid[]) AND muted_until > $4`
+	rows, err := r.pool.Query(ctx, query, chat.Kind, chat.ID, userIDs, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var muted []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		muted = append(muted, userID)
+	}
+	return muted, rows.Err()
+}
+
+func (r *chatStateRepository) CountUnreadMentions(userID string) (map[string]int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT m.room_id::text, COUNT(*)
+	          FROM room_mentions m
+	          LEFT JOIN chat_states s ON s.user_id = m.user_id AND s.chat_kind = 'room' AND s.chat_id = m.room_id
+	          WHERE m.user_id = $1 AND (s.last_read_at IS NULL OR m.created_at > s.last_read_at)
+	          GROUP BY m.room_id`
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var roomID string
+		var count int
+		if err := rows.Scan(&roomID, &count); err != nil {
+			return nil, err
+		}
+		counts[roomID] = count
+	}
+	return counts, rows.Err()
+}
+
+// scanChatState reads a chat_states row.
+func scanChatState(row pgx.Row) (*domain.ChatState, error) {
+	var state domain.ChatState
+	err := row.Scan(&state.UserID, &state.Chat.Kind, &state.Chat.ID, &state.Archived,
+		&state.PinOrder, &state.MutedUntil, &state.LastReadAt)
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
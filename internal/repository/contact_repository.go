@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"social_media/internal/domain"
+)
+
+type contactRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewContactRepository(pool *pgxpool.Pool) domain.ContactRepository {
+	return &contactRepository{pool: pool}
+}
+
+func (r *contactRepository) Save(contact *domain.Contact) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO contacts (owner_id, user_id, display_name, created_at) VALUES ($1, $2, $3, $4)
+	          ON CONFLICT (owner_id, user_id) DO UPDATE SET display_name = EXCLUDED.display_name`
+	_, err := r.pool.Exec(ctx, query, contact.OwnerID, contact.UserID, contact.DisplayName, contact.CreatedAt)
+	return err
+}
+
+func (r *contactRepository) Delete(ownerID, userID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `DELETE FROM contacts WHERE owner_id = $1 AND user_id = $2`
+	_, err := r.pool.Exec(ctx, query, ownerID, userID)
+	return err
+}
+
+func (r *contactRepository) IsContact(ownerID, userID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT EXISTS (SELECT 1 FROM contacts WHERE owner_id = $1 AND user_id = $2)`
+	var found bool
+	if err := r.pool.QueryRow(ctx, query, ownerID, userID).Scan(&found); err != nil {
+		return false, err
+	}
+	return found, nil
+}
+
+func (r *contactRepository) FindByOwner(ownerID string) ([]*domain.Contact, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT c.owner_id, c.user_id, c.display_name, u.name, u.username, c.created_at
+	          FROM contacts c JOIN users u ON u.id = c.user_id
+	          WHERE c.owner_id = $1
+	          ORDER BY COALESCE(c.display_name, u.name) ASC`
+	rows, err := r.pool.Query(ctx, query, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contacts []*domain.Contact
+	for rows.Next() {
+		var c domain.Contact
+		if err := rows.Scan(&c.OwnerID, &c.UserID, &c.DisplayName, &c.Name, &c.Username, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, &c)
+	}
+	return contacts, rows.Err()
+}
@@ -10,6 +10,13 @@ import (
 	"social_media/internal/domain"
 )
 
+// conversationColumns lists the conversations columns in the order scanConversation reads them.
+// Group conversations have no participant1/participant2; they are read as empty strings.
+const conversationColumns = `id, kind, title, COALESCE(participant1::text, ''), COALESCE(participant2::text, ''),
+	ARRAY(SELECT p.user_id::text FROM conversation_participants p
+	      WHERE p.conversation_id = conversations.id ORDER BY p.joined_at, p.user_id),
+	status, initiator_id, created_at`
+
 type conversationRepository struct {
 	pool *pgxpool.Pool
 }
@@ -22,9 +29,63 @@ func (r *conversationRepository) Create(convo *domain.Conversation) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `INSERT INTO conversations (id, participant1, participant2, created_at)
-			  VALUES ($1, $2, $3, $4)`
-	_, err := r.pool.Exec(ctx, query, convo.ID, convo.Participant1, convo.Participant2, convo.CreatedAt)
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `INSERT INTO conversations (id, kind, title, participant1, participant2, status, initiator_id, created_at)
+			  VALUES ($1, $2, $3, NULLIF($4, '')::uuid, NULLIF($5, '')::uuid, $6, $7, $8)`
+	_, err = tx.Exec(ctx, query, convo.ID, convo.Kind, convo.Title, convo.Participant1, convo.Participant2,
+		convo.Status, convo.InitiatorID, convo.CreatedAt)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `INSERT INTO conversation_participants (conversation_id, user_id, joined_at)
+	                       SELECT $1, unnest($2::uuid[]), $3`,
+		convo.ID, convo.Participants, convo.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *conversationRepository) AddParticipant(convoID, userID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO conversation_participants (conversation_id, user_id, joined_at) VALUES ($1, $2, $3)
+	          ON CONFLICT (conversation_id, user_id) DO NOTHING`
+	_, err := r.pool.Exec(ctx, query, convoID, userID, time.Now())
+	return err
+}
+
+func (r *conversationRepository) RemoveParticipant(convoID, userID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `DELETE FROM conversation_participants WHERE conversation_id = $1 AND user_id = $2`
+	_, err := r.pool.Exec(ctx, query, convoID, userID)
+	return err
+}
+
+func (r *conversationRepository) UpdateStatus(convo *domain.Conversation) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `UPDATE conversations SET status = $1 WHERE id = $2`
+	_, err := r.pool.Exec(ctx, query, convo.Status, convo.ID)
+	return err
+}
+
+func (r *conversationRepository) Delete(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Messages are removed by the ON DELETE CASCADE foreign key.
+	query := `DELETE FROM conversations WHERE id = $1`
+	_, err := r.pool.Exec(ctx, query, id)
 	return err
 }
 
@@ -32,27 +93,49 @@ func (r *conversationRepository) FindByParticipants(p1, p2 string) (*domain.Conv
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `SELECT id, participant1, participant2, created_at FROM conversations
+	query := `SELECT ` + conversationColumns + ` FROM conversations
 			  WHERE participant1 = $1 AND participant2 = $2`
-	row := r.pool.QueryRow(ctx, query, p1, p2)
-	var convo domain.Conversation
-	err := row.Scan(&convo.ID, &convo.Participant1, &convo.Participant2, &convo.CreatedAt)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, nil
-		}
-		return nil, err
+	convo, err := scanConversation(r.pool.QueryRow(ctx, query, p1, p2))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
 	}
-	return &convo, nil
+	return convo, err
 }
 
 func (r *conversationRepository) FindByUser(userID string) ([]*domain.Conversation, error) {
+	query := `SELECT ` + conversationColumns + ` FROM conversations
+			  WHERE id IN (SELECT conversation_id FROM conversation_participants WHERE user_id = $1)
+			  AND (status = 'active' OR initiator_id = $1)
+			  ORDER BY created_at DESC`
+	return r.findMany(query, userID)
+}
+
+func (r *conversationRepository) FindRequests(userID string) ([]*domain.Conversation, error) {
+	query := `SELECT ` + conversationColumns + ` FROM conversations
+			  WHERE id IN (SELECT conversation_id FROM conversation_participants WHERE user_id = $1)
+			  AND status = 'request' AND initiator_id <> $1
+			  ORDER BY created_at DESC`
+	return r.findMany(query, userID)
+}
+
+func (r *conversationRepository) FindByID(id string) (*domain.Conversation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + conversationColumns + ` FROM conversations WHERE id = $1`
+	convo, err := scanConversation(r.pool.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return convo, err
+}
+
+// findMany runs a query selecting conversationColumns and collects the rows.
+func (r *conversationRepository) findMany(query string, args ...interface{}) ([]*domain.Conversation, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `SELECT id, participant1, participant2, created_at FROM conversations
-			  WHERE participant1 = $1 OR participant2 = $1 ORDER BY created_at DESC`
-	rows, err := r.pool.Query(ctx, query, userID)
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -60,30 +143,22 @@ func (r *conversationRepository) FindByUser(userID string) ([]*domain.Conversati
 
 	var convos []*domain.Conversation
 	for rows.Next() {
-		var convo domain.Conversation
-		err := rows.Scan(&convo.ID, &convo.Participant1, &convo.Participant2, &convo.CreatedAt)
+		convo, err := scanConversation(rows)
 		if err != nil {
 			return nil, err
 		}
-		convos = append(convos, &convo)
+		convos = append(convos, convo)
 	}
-	return convos, nil
+	return convos, rows.Err()
 }
 
-func (r *conversationRepository) FindByID(id string) (*domain.Conversation, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	query := `SELECT id, participant1, participant2, created_at FROM conversations WHERE id = $1`
-	row := r.pool.QueryRow(ctx, query, id)
+// scanConversation reads a row selected with conversationColumns.
+func scanConversation(row pgx.Row) (*domain.Conversation, error) {
 	var convo domain.Conversation
-	err := row.Scan(&convo.ID, &convo.Participant1, &convo.Participant2, &convo.CreatedAt)
+	err := row.Scan(&convo.ID, &convo.Kind, &convo.Title, &convo.Participant1, &convo.Participant2, &convo.Participants,
+		&convo.Status, &convo.InitiatorID, &convo.CreatedAt)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, nil
-		}
 		return nil, err
 	}
 	return &convo, nil
 }
-
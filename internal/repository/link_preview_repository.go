@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"social_media/internal/domain"
+)
+
+type linkPreviewRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLinkPreviewRepository(pool *pgxpool.Pool) domain.LinkPreviewRepository {
+	return &linkPreviewRepository{pool: pool}
+}
+
+func (r *linkPreviewRepository) FindByURL(url string) (*domain.LinkPreview, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT url, title, description, image_url, site_name, fetched_at FROM link_previews WHERE url = $1`
+	var preview domain.LinkPreview
+	err := r.pool.QueryRow(ctx, query, url).Scan(&preview.URL, &preview.Title, &preview.Description,
+		&preview.ImageURL, &preview.SiteName, &preview.FetchedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &preview, nil
+}
+
+func (r *linkPreviewRepository) Save(preview *domain.LinkPreview) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO link_previews (url, title, description, image_url, site_name, fetched_at)
+	          VALUES ($1, $2, $3, $4, $5, $6)
+	          ON CONFLICT (url) DO UPDATE SET title = EXCLUDED.title, description = EXCLUDED.description,
+	              image_url = EXCLUDED.image_url, site_name = EXCLUDED.site_name, fetched_at = EXCLUDED.fetched_at`
+	_, err := r.pool.Exec(ctx, query, preview.URL, preview.Title, preview.Description,
+		preview.ImageURL, preview.SiteName, preview.FetchedAt)
+	return err
+}
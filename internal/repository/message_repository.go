@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"time"
 
@@ -10,6 +11,85 @@ import (
 	"social_media/internal/domain"
 )
 
+// messageColumns is the column list scanMessage expects.
+const messageColumns = `id, conversation_id, sender_id, content, kind, reference_id, forwarded_from, entities,
+	COALESCE(preview_url, ''), link_preview, created_at, updated_at`
+
+func scanMessage(row pgx.Row) (*domain.Message, error) {
+	var message domain.Message
+	var forwardedFrom, entities, linkPreview []byte
+	err := row.Scan(&message.ID, &message.ConversationID, &message.SenderID, &message.Content,
+		&message.Kind, &message.ReferenceID, &forwardedFrom, &entities,
+		&message.PreviewURL, &linkPreview, &message.CreatedAt, &message.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if message.ForwardedFrom, err = decodeForwardOrigin(forwardedFrom); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(entities, &message.Entities); err != nil {
+		return nil, err
+	}
+	if message.LinkPreview, err = decodeLinkPreview(linkPreview); err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// encodeForwardOrigin returns the forwarded_from document, or nil (SQL NULL) for original messages.
+func encodeForwardOrigin(origin *domain.ForwardOrigin) ([]byte, error) {
+	if origin == nil {
+		return nil, nil
+	}
+	return json.Marshal(origin)
+}
+
+// encodeEntities returns the entities document; messages without entities store an empty array.
+func encodeEntities(entities []domain.MessageEntity) ([]byte, error) {
+	if entities == nil {
+		entities = []domain.MessageEntity{}
+	}
+	return json.Marshal(entities)
+}
+
+// encodeLinkPreview returns the link_preview document, or nil (SQL NULL) when there is no preview.
+func encodeLinkPreview(preview *domain.LinkPreview) ([]byte, error) {
+	if preview == nil {
+		return nil, nil
+	}
+	return json.Marshal(preview)
+}
+
+func decodeLinkPreview(raw []byte) (*domain.LinkPreview, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	var preview domain.LinkPreview
+	if err := json.Unmarshal(raw, &preview); err != nil {
+		return nil, err
+	}
+	return &preview, nil
+}
+
+// nullIfEmpty maps "" to SQL NULL.
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func decodeForwardOrigin(raw []byte) (*domain.ForwardOrigin, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	var origin domain.ForwardOrigin
+	if err := json.Unmarshal(raw, &origin); err != nil {
+		return nil, err
+	}
+	return &origin, nil
+}
+
 type messageRepository struct {
 	pool *pgxpool.Pool
 }
@@ -22,10 +102,19 @@ func (r *messageRepository) Create(message *domain.Message) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `INSERT INTO messages (id, conversation_id, sender_id, content, created_at, updated_at)
-			  VALUES ($1, $2, $3, $4, $5, $6)`
-	_, err := r.pool.Exec(ctx, query,
-		message.ID, message.ConversationID, message.SenderID, message.Content, message.CreatedAt, message.UpdatedAt)
+	forwardedFrom, err := encodeForwardOrigin(message.ForwardedFrom)
+	if err != nil {
+		return err
+	}
+	entities, err := encodeEntities(message.Entities)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO messages (id, conversation_id, sender_id, content, kind, reference_id, forwarded_from, entities, preview_url, created_at, updated_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	_, err = r.pool.Exec(ctx, query,
+		message.ID, message.ConversationID, message.SenderID, message.Content, message.Kind, message.ReferenceID,
+		forwardedFrom, entities, nullIfEmpty(message.PreviewURL), message.CreatedAt, message.UpdatedAt)
 	return err
 }
 
@@ -33,8 +122,18 @@ func (r *messageRepository) Update(message *domain.Message) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `UPDATE messages SET content = $1, updated_at = $2 WHERE id = $3`
-	_, err := r.pool.Exec(ctx, query, message.Content, message.UpdatedAt, message.ID)
+	entities, err := encodeEntities(message.Entities)
+	if err != nil {
+		return err
+	}
+	linkPreview, err := encodeLinkPreview(message.LinkPreview)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE messages SET content = $1, entities = $2, preview_url = $3, link_preview = $4, updated_at = $5
+			  WHERE id = $6`
+	_, err = r.pool.Exec(ctx, query, message.Content, entities, nullIfEmpty(message.PreviewURL),
+		linkPreview, message.UpdatedAt, message.ID)
 	return err
 }
 
@@ -51,7 +150,7 @@ func (r *messageRepository) FindByConversation(convoID string) ([]*domain.Messag
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `SELECT id, conversation_id, sender_id, content, created_at, updated_at FROM messages
+	query := `SELECT ` + messageColumns + ` FROM messages
 			  WHERE conversation_id = $1 ORDER BY created_at ASC`
 	rows, err := r.pool.Query(ctx, query, convoID)
 	if err != nil {
@@ -61,12 +160,11 @@ func (r *messageRepository) FindByConversation(convoID string) ([]*domain.Messag
 
 	var messages []*domain.Message
 	for rows.Next() {
-		var message domain.Message
-		err := rows.Scan(&message.ID, &message.ConversationID, &message.SenderID, &message.Content, &message.CreatedAt, &message.UpdatedAt)
+		message, err := scanMessage(rows)
 		if err != nil {
 			return nil, err
 		}
-		messages = append(messages, &message)
+		messages = append(messages, message)
 	}
 	return messages, nil
 }
@@ -75,15 +173,49 @@ func (r *messageRepository) FindByID(id string) (*domain.Message, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `SELECT id, conversation_id, sender_id, content, created_at, updated_at FROM messages WHERE id = $1`
-	row := r.pool.QueryRow(ctx, query, id)
-	var message domain.Message
-	err := row.Scan(&message.ID, &message.ConversationID, &message.SenderID, &message.Content, &message.CreatedAt, &message.UpdatedAt)
+	query := `SELECT ` + messageColumns + ` FROM messages WHERE id = $1`
+	message, err := scanMessage(r.pool.QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, err
 	}
-	return &message, nil
+	return message, nil
+}
+
+func (r *messageRepository) FindPendingPreviews(limit int) ([]*domain.Message, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + messageColumns + ` FROM messages
+			  WHERE preview_url IS NOT NULL ORDER BY created_at ASC LIMIT $1`
+	rows, err := r.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []*domain.Message
+	for rows.Next() {
+		message, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+func (r *messageRepository) SetLinkPreview(messageID string, preview *domain.LinkPreview) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	linkPreview, err := encodeLinkPreview(preview)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE messages SET preview_url = NULL, link_preview = $1 WHERE id = $2`
+	_, err = r.pool.Exec(ctx, query, linkPreview, messageID)
+	return err
 }
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"social_media/internal/domain"
+)
+
+type messageSearchRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewMessageSearchRepository(pool *pgxpool.Pool) domain.MessageSearchRepository {
+	return &messageSearchRepository{pool: pool}
+}
+
+func (r *messageSearchRepository) Search(userID string, filter domain.MessageSearchFilter) ([]*domain.MessageSearchHit, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Both branches are restricted to chats the user can read. A conversation
+	// filter excludes all rooms and a room filter excludes all conversations.
+	// The content is HTML-escaped before highlighting, so the only markup in a
+	// snippet is the <mark> tags.
+	query := `WITH q AS (SELECT websearch_to_tsquery('simple', $2) AS query)
+	          SELECT hits.id, hits.conversation_id, hits.room_id, hits.sender_id,
+	                 ts_headline('simple',
+	                             replace(replace(replace(replace(replace(hits.content,
+	                                 '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;'),
+	                             q.query,
+	                             'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5'),
+	                 hits.rank, hits.created_at
+	          FROM (
+	              SELECT m.id, m.conversation_id::text AS conversation_id, NULL::text AS room_id,
+	                     m.sender_id, m.content, ts_rank(m.content_tsv, q.query) AS rank, m.created_at
+	              FROM messages m
+	              JOIN conversation_participants cp ON cp.conversation_id = m.conversation_id AND cp.user_id = $1
+	              CROSS JOIN q
+	              WHERE m.content_tsv @@ q.query
+	                AND ($3 = '' OR m.sender_id::text = $3)
+	                AND ($4 = '' OR m.conversation_id::text = $4)
+	                AND $5 = ''
+	                AND ($6::timestamptz IS NULL OR m.created_at >= $6)
+	                AND ($7::timestamptz IS NULL OR m.created_at < $7)
+	              UNION ALL
+	              SELECT rm.id, NULL::text, rm.room_id::text,
+	                     rm.sender_id, rm.content, ts_rank(rm.content_tsv, q.query), rm.created_at
+	              FROM room_messages rm
+	              JOIN room_memberships rmb ON rmb.room_id = rm.room_id AND rmb.user_id = $1 AND rmb.role <> 'banned'
+	              CROSS JOIN q
+	              WHERE rm.content_tsv @@ q.query
+	                AND ($3 = '' OR rm.sender_id::text = $3)
+	                AND $4 = ''
+	                AND ($5 = '' OR rm.room_id::text = $5)
+	                AND ($6::timestamptz IS NULL OR rm.created_at >= $6)
+	                AND ($7::timestamptz IS NULL OR rm.created_at < $7)
+	          ) hits
+	          CROSS JOIN q
+	          ORDER BY hits.rank DESC, hits.created_at DESC
+	          LIMIT $8 OFFSET $9`
+	rows, err := r.pool.Query(ctx, query, userID, filter.Query,
+		filter.SenderID, filter.ConversationID, filter.RoomID, filter.From, filter.To,
+		filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []*domain.MessageSearchHit
+	for rows.Next() {
+		var hit domain.MessageSearchHit
+		err := rows.Scan(&hit.MessageID, &hit.ConversationID, &hit.RoomID, &hit.SenderID,
+			&hit.Snippet, &hit.Rank, &hit.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, &hit)
+	}
+	return hits, rows.Err()
+}
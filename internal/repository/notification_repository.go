@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"social_media/internal/domain"
+)
+
+type notificationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewNotificationRepository(pool *pgxpool.Pool) domain.NotificationRepository {
+	return &notificationRepository{pool: pool}
+}
+
+func (r *notificationRepository) Create(notification *domain.Notification) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO notifications (id, user_id, type, room_id, actor_id, reference_id, read, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	_, err := r.pool.Exec(ctx, query, notification.ID, notification.UserID, notification.Type, notification.RoomID,
+		notification.ActorID, notification.ReferenceID, notification.Read, notification.CreatedAt)
+	return err
+}
+
+func (r *notificationRepository) CreateBatch(notifications []*domain.Notification) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO notifications (id, user_id, type, room_id, actor_id, reference_id, read, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	batch := &pgx.Batch{}
+	for _, notification := range notifications {
+		batch.Queue(query, notification.ID, notification.UserID, notification.Type, notification.RoomID,
+			notification.ActorID, notification.ReferenceID, notification.Read, notification.CreatedAt)
+	}
+	results := r.pool.SendBatch(ctx, batch)
+	defer results.Close()
+	for range notifications {
+		if _, err := results.Exec(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *notificationRepository) FindByUser(userID string, unreadOnly bool, limit, offset int) ([]*domain.Notification, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT id, user_id, type, room_id, actor_id, reference_id, read, created_at FROM notifications
+	          WHERE user_id = $1 AND (NOT $2 OR NOT read)
+	          ORDER BY created_at DESC LIMIT $3 OFFSET $4`
+	rows, err := r.pool.Query(ctx, query, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []*domain.Notification
+	for rows.Next() {
+		var n domain.Notification
+		err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.RoomID, &n.ActorID, &n.ReferenceID, &n.Read, &n.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, &n)
+	}
+	return notifications, rows.Err()
+}
+
+func (r *notificationRepository) MarkRead(userID, notificationID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `UPDATE notifications SET read = TRUE WHERE id = $1 AND user_id = $2`
+	cmdTag, err := r.pool.Exec(ctx, query, notificationID, userID)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return errors.New("notification not found")
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"social_media/internal/domain"
+)
+
+// pinTables names the tables holding one chat kind's pins and messages.
+type pinTables struct {
+	pins, chatColumn, messages string
+}
+
+func pinTablesFor(chat domain.ChatRef) (pinTables, error) {
+	switch chat.Kind {
+	case domain.ChatConversation:
+		return pinTables{pins: "conversation_pinned_messages", chatColumn: "conversation_id", messages: "messages"}, nil
+	case domain.ChatRoom:
+		return pinTables{pins: "room_pinned_messages", chatColumn: "room_id", messages: "room_messages"}, nil
+	default:
+		return pinTables{}, fmt.Errorf("unknown chat kind %q", chat.Kind)
+	}
+}
+
+type pinRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPinRepository(pool *pgxpool.Pool) domain.PinRepository {
+	return &pinRepository{pool: pool}
+}
+
+func (r *pinRepository) Add(pin *domain.PinnedMessage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t, err := pinTablesFor(pin.Chat)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`INSERT INTO %s (%s, message_id, pinned_by, position, pinned_at)
+	          VALUES ($1, $2, $3, $4, $5)`, t.pins, t.chatColumn)
+	_, err = r.pool.Exec(ctx, query, pin.Chat.ID, pin.MessageID, pin.PinnedBy, pin.Position, pin.PinnedAt)
+	return err
+}
+
+func (r *pinRepository) Remove(chat domain.ChatRef, messageID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t, err := pinTablesFor(chat)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`DELETE FROM %s WHERE %s = $1 AND message_id = $2`, t.pins, t.chatColumn)
+	_, err = r.pool.Exec(ctx, query, chat.ID, messageID)
+	return err
+}
+
+func (r *pinRepository) FindByChat(chat domain.ChatRef) ([]*domain.PinnedMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t, err := pinTablesFor(chat)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(`SELECT p.message_id, m.sender_id, m.content, p.pinned_by, p.position, p.pinned_at
+	          FROM %s p JOIN %s m ON m.id = p.message_id
+	          WHERE p.%s = $1 ORDER BY p.position, p.pinned_at`, t.pins, t.messages, t.chatColumn)
+	rows, err := r.pool.Query(ctx, query, chat.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pins []*domain.PinnedMessage
+	for rows.Next() {
+		pin := &domain.PinnedMessage{Chat: chat}
+		if err := rows.Scan(&pin.MessageID, &pin.SenderID, &pin.Content, &pin.PinnedBy, &pin.Position, &pin.PinnedAt); err != nil {
+			return nil, err
+		}
+		pins = append(pins, pin)
+	}
+	return pins, rows.Err()
+}
+
+func (r *pinRepository) Reorder(chat domain.ChatRef, messageIDs []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t, err := pinTablesFor(chat)
+	if err != nil {
+		return err
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := fmt.Sprintf(`UPDATE %s SET position = $1 WHERE %s = $2 AND message_id = $3`, t.pins, t.chatColumn)
+	for position, messageID := range messageIDs {
+		if _, err := tx.Exec(ctx, query, position, chat.ID, messageID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"social_media/internal/domain"
+)
+
+// pollColumns is the column list scanPoll expects.
+const pollColumns = `message_id, room_id, question, multiple_choice, anonymous, quiz, correct_option,
+	total_voters, close_at, closed_at, created_at`
+
+func scanPoll(row pgx.Row) (*domain.Poll, error) {
+	var poll domain.Poll
+	err := row.Scan(&poll.MessageID, &poll.RoomID, &poll.Question, &poll.MultipleChoice, &poll.Anonymous,
+		&poll.Quiz, &poll.CorrectOption, &poll.TotalVoters, &poll.CloseAt, &poll.ClosedAt, &poll.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &poll, nil
+}
+
+// insertPoll stores the poll of a new message inside the message's transaction.
+func insertPoll(ctx context.Context, tx pgx.Tx, poll *domain.Poll) error {
+	query := `INSERT INTO polls (message_id, room_id, question, multiple_choice, anonymous, quiz, correct_option, close_at, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	_, err := tx.Exec(ctx, query, poll.MessageID, poll.RoomID, poll.Question, poll.MultipleChoice, poll.Anonymous,
+		poll.Quiz, poll.CorrectOption, poll.CloseAt, poll.CreatedAt)
+	if err != nil {
+		return err
+	}
+	for position, option := range poll.Options {
+		query := `INSERT INTO poll_options (message_id, position, text) VALUES ($1, $2, $3)`
+		if _, err := tx.Exec(ctx, query, poll.MessageID, position, option.Text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadPolls returns the polls of messageIDs with their tallies, keyed by message ID.
+func loadPolls(ctx context.Context, pool *pgxpool.Pool, messageIDs []string) (map[string]*domain.Poll, error) {
+	polls := make(map[string]*domain.Poll, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return polls, nil
+	}
+	rows, err := pool.Query(ctx, `SELECT `+pollColumns+` FROM polls WHERE message_id = ANY($1)`, messageIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		poll, err := scanPoll(rows)
+		if err != nil {
+			return nil, err
+		}
+		polls[poll.MessageID] = poll
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = pool.Query(ctx, `SELECT message_id, text, votes FROM poll_options
+	                             WHERE message_id = ANY($1) ORDER BY message_id, position`, messageIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var messageID string
+		var option domain.PollOption
+		if err := rows.Scan(&messageID, &option.Text, &option.Votes); err != nil {
+			return nil, err
+		}
+		if poll := polls[messageID]; poll != nil {
+			poll.Options = append(poll.Options, option)
+		}
+	}
+	return polls, rows.Err()
+}
+
+// loadVotesOf sets MyVotes on polls, keyed by message ID, to the options userID voted for.
+func loadVotesOf(ctx context.Context, pool *pgxpool.Pool, polls map[string]*domain.Poll, userID string) error {
+	if len(polls) == 0 {
+		return nil
+	}
+	messageIDs := make([]string, 0, len(polls))
+	for messageID := range polls {
+		messageIDs = append(messageIDs, messageID)
+	}
+	rows, err := pool.Query(ctx, `SELECT message_id, position FROM poll_votes
+	                              WHERE message_id = ANY($1) AND user_id = $2
+	                              ORDER BY voted_at, position`, messageIDs, userID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var messageID string
+		var position int
+		if err := rows.Scan(&messageID, &position); err != nil {
+			return err
+		}
+		if poll := polls[messageID]; poll != nil && position >= 0 && position < len(poll.Options) {
+			poll.MyVotes = append(poll.MyVotes, position)
+		}
+	}
+	return rows.Err()
+}
+
+type pollRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPollRepository(pool *pgxpool.Pool) domain.PollRepository {
+	return &pollRepository{pool: pool}
+}
+
+func (r *pollRepository) FindByMessage(messageID, viewerID string) (*domain.Poll, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	polls, err := loadPolls(ctx, r.pool, []string{messageID})
+	if err != nil {
+		return nil, err
+	}
+	poll := polls[messageID]
+	if poll == nil {
+		return nil, nil
+	}
+
+	query := `SELECT user_id, position FROM poll_votes WHERE message_id = $1 ORDER BY voted_at, position`
+	rows, err := r.pool.Query(ctx, query, messageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var userID string
+		var position int
+		if err := rows.Scan(&userID, &position); err != nil {
+			return nil, err
+		}
+		if position < 0 || position >= len(poll.Options) {
+			continue
+		}
+		if userID == viewerID {
+			poll.MyVotes = append(poll.MyVotes, position)
+		}
+		if !poll.Anonymous {
+			poll.Options[position].Voters = append(poll.Options[position].Voters, userID)
+		}
+	}
+	return poll, rows.Err()
+}
+
+func (r *pollRepository) SetVotes(messageID, userID string, options []int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the poll so concurrent votes and closing are applied one at a time,
+	// then check it still takes the vote.
+	var poll domain.Poll
+	query := `SELECT quiz, close_at, closed_at FROM polls WHERE message_id = $1 FOR UPDATE`
+	if err := tx.QueryRow(ctx, query, messageID).Scan(&poll.Quiz, &poll.CloseAt, &poll.ClosedAt); err != nil {
+		return err
+	}
+	if poll.IsClosed(time.Now()) {
+		return domain.ErrPollClosed
+	}
+	rows, err := tx.Query(ctx, `DELETE FROM poll_votes WHERE message_id = $1 AND user_id = $2 RETURNING position`, messageID, userID)
+	if err != nil {
+		return err
+	}
+	var previous []int
+	for rows.Next() {
+		var position int
+		if err := rows.Scan(&position); err != nil {
+			rows.Close()
+			return err
+		}
+		previous = append(previous, position)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if poll.Quiz && len(previous) > 0 {
+		return domain.ErrQuizAnswered
+	}
+
+	query = `UPDATE poll_options SET votes = votes - 1 WHERE message_id = $1 AND position = ANY($2)`
+	if _, err := tx.Exec(ctx, query, messageID, previous); err != nil {
+		return err
+	}
+	query = `INSERT INTO poll_votes (message_id, user_id, position, voted_at)
+	         SELECT $1, $2, p, $4 FROM unnest($3::int[]) AS p`
+	if _, err := tx.Exec(ctx, query, messageID, userID, options, time.Now()); err != nil {
+		return err
+	}
+	query = `UPDATE poll_options SET votes = votes + 1 WHERE message_id = $1 AND position = ANY($2)`
+	if _, err := tx.Exec(ctx, query, messageID, options); err != nil {
+		return err
+	}
+	voters := 0
+	if len(previous) == 0 && len(options) > 0 {
+		voters = 1
+	} else if len(previous) > 0 && len(options) == 0 {
+		voters = -1
+	}
+	if voters != 0 {
+		query = `UPDATE polls SET total_voters = total_voters + $1 WHERE message_id = $2`
+		if _, err := tx.Exec(ctx, query, voters, messageID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *pollRepository) Close(messageID string, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `UPDATE polls SET closed_at = $1 WHERE message_id = $2 AND closed_at IS NULL`
+	_, err := r.pool.Exec(ctx, query, at, messageID)
+	return err
+}
+
+func (r *pollRepository) CloseExpired(now time.Time) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `UPDATE polls SET closed_at = close_at
+	          WHERE closed_at IS NULL AND close_at <= $1
+	          RETURNING message_id`
+	rows, err := r.pool.Query(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messageIDs []string
+	for rows.Next() {
+		var messageID string
+		if err := rows.Scan(&messageID); err != nil {
+			return nil, err
+		}
+		messageIDs = append(messageIDs, messageID)
+	}
+	return messageIDs, rows.Err()
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"social_media/internal/domain"
+)
+
+type roomAuditRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRoomAuditRepository(pool *pgxpool.Pool) domain.RoomAuditRepository {
+	return &roomAuditRepository{pool: pool}
+}
+
+func (r *roomAuditRepository) Append(entry *domain.RoomAuditEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Snapshots are passed as []byte so that a missing snapshot is stored as NULL.
+	query := `INSERT INTO room_audit_log (id, room_id, actor_id, action, target_id, before, after, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	_, err := r.pool.Exec(ctx, query, entry.ID, entry.RoomID, entry.ActorID, entry.Action, entry.TargetID,
+		[]byte(entry.Before), []byte(entry.After), entry.CreatedAt)
+	return err
+}
+
+func (r *roomAuditRepository) Find(roomID string, filter domain.RoomAuditFilter) ([]*domain.RoomAuditEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT id, room_id, actor_id, action, target_id, before, after, created_at
+	          FROM room_audit_log
+	          WHERE room_id = $1
+	            AND ($2 = '' OR actor_id::text = $2)
+	            AND ($3 = '' OR action = $3)
+	            AND ($4::timestamptz IS NULL OR created_at >= $4)
+	            AND ($5::timestamptz IS NULL OR created_at < $5)
+	          ORDER BY created_at DESC
+	          LIMIT $6 OFFSET $7`
+	rows, err := r.pool.Query(ctx, query, roomID, filter.ActorID, string(filter.Action), filter.From, filter.To,
+		filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*domain.RoomAuditEntry
+	for rows.Next() {
+		var e domain.RoomAuditEntry
+		var before, after []byte
+		err := rows.Scan(&e.ID, &e.RoomID, &e.ActorID, &e.Action, &e.TargetID, &before, &after, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		e.Before, e.After = before, after
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
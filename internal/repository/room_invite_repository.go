@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"social_media/internal/domain"
+)
+
+type roomInviteRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRoomInviteRepository(pool *pgxpool.Pool) domain.RoomInviteRepository {
+	return &roomInviteRepository{pool: pool}
+}
+
+func (r *roomInviteRepository) Create(invite *domain.RoomInvite) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO room_invites (token, room_id, creator_id, expires_at, max_uses, use_count, requires_approval, revoked, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	_, err := r.pool.Exec(ctx, query, invite.Token, invite.RoomID, invite.CreatorID, invite.ExpiresAt, invite.MaxUses,
+		invite.UseCount, invite.RequiresApproval, invite.Revoked, invite.CreatedAt)
+	return err
+}
+
+func (r *roomInviteRepository) FindByToken(token string) (*domain.RoomInvite, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT token, room_id, creator_id, expires_at, max_uses, use_count, requires_approval, revoked, created_at
+	          FROM room_invites WHERE token = $1`
+	row := r.pool.QueryRow(ctx, query, token)
+	var invite domain.RoomInvite
+	err := row.Scan(&invite.Token, &invite.RoomID, &invite.CreatorID, &invite.ExpiresAt, &invite.MaxUses,
+		&invite.UseCount, &invite.RequiresApproval, &invite.Revoked, &invite.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &invite, nil
+}
+
+func (r *roomInviteRepository) FindByRoom(roomID string) ([]*domain.RoomInvite, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT token, room_id, creator_id, expires_at, max_uses, use_count, requires_approval, revoked, created_at
+	          FROM room_invites WHERE room_id = $1 ORDER BY created_at DESC`
+	rows, err := r.pool.Query(ctx, query, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invites []*domain.RoomInvite
+	for rows.Next() {
+		var invite domain.RoomInvite
+		err := rows.Scan(&invite.Token, &invite.RoomID, &invite.CreatorID, &invite.ExpiresAt, &invite.MaxUses,
+			&invite.UseCount, &invite.RequiresApproval, &invite.Revoked, &invite.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		invites = append(invites, &invite)
+	}
+	return invites, rows.Err()
+}
+
+func (r *roomInviteRepository) Revoke(token string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `UPDATE room_invites SET revoked = TRUE WHERE token = $1`
+	_, err := r.pool.Exec(ctx, query, token)
+	return err
+}
+
+func (r *roomInviteRepository) Redeem(token string, now time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The usability checks are repeated here so concurrent redemptions cannot exceed max_uses.
+	query := `UPDATE room_invites SET use_count = use_count + 1
+	          WHERE token = $1 AND NOT revoked
+	            AND (expires_at IS NULL OR expires_at > $2)
+	            AND (max_uses IS NULL OR use_count < max_uses)`
+	cmdTag, err := r.pool.Exec(ctx, query, token, now)
+	if err != nil {
+		return false, err
+	}
+	return cmdTag.RowsAffected() == 1, nil
+}
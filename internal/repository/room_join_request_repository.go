@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"social_media/internal/domain"
+)
+
+type roomJoinRequestRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRoomJoinRequestRepository(pool *pgxpool.Pool) domain.RoomJoinRequestRepository {
+	return &roomJoinRequestRepository{pool: pool}
+}
+
+func (r *roomJoinRequestRepository) Create(request *domain.RoomJoinRequest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO room_join_requests (id, room_id, user_id, invite_token, message, status, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := r.pool.Exec(ctx, query, request.ID, request.RoomID, request.UserID, request.InviteToken,
+		request.Message, request.Status, request.CreatedAt)
+	return err
+}
+
+func (r *roomJoinRequestRepository) FindByID(id string) (*domain.RoomJoinRequest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT id, room_id, user_id, invite_token, message, status, decided_by, decided_at, created_at
+	          FROM room_join_requests WHERE id = $1`
+	row := r.pool.QueryRow(ctx, query, id)
+	var request domain.RoomJoinRequest
+	err := row.Scan(&request.ID, &request.RoomID, &request.UserID, &request.InviteToken,
+		&request.Message, &request.Status, &request.DecidedBy, &request.DecidedAt, &request.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *roomJoinRequestRepository) FindPending(roomID, userID string) (*domain.RoomJoinRequest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT id, room_id, user_id, invite_token, message, status, decided_by, decided_at, created_at
+	          FROM room_join_requests WHERE room_id = $1 AND user_id = $2 AND status = $3`
+	row := r.pool.QueryRow(ctx, query, roomID, userID, domain.JoinRequestPending)
+	var request domain.RoomJoinRequest
+	err := row.Scan(&request.ID, &request.RoomID, &request.UserID, &request.InviteToken,
+		&request.Message, &request.Status, &request.DecidedBy, &request.DecidedAt, &request.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *roomJoinRequestRepository) FindPendingByRoom(roomID string) ([]*domain.RoomJoinRequest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT id, room_id, user_id, invite_token, message, status, decided_by, decided_at, created_at
+	          FROM room_join_requests WHERE room_id = $1 AND status = $2 ORDER BY created_at ASC`
+	rows, err := r.pool.Query(ctx, query, roomID, domain.JoinRequestPending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []*domain.RoomJoinRequest
+	for rows.Next() {
+		var request domain.RoomJoinRequest
+		err := rows.Scan(&request.ID, &request.RoomID, &request.UserID, &request.InviteToken,
+			&request.Message, &request.Status, &request.DecidedBy, &request.DecidedAt, &request.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, &request)
+	}
+	return requests, rows.Err()
+}
+
+func (r *roomJoinRequestRepository) UpdateStatus(request *domain.RoomJoinRequest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `UPDATE room_join_requests SET status = $1, decided_by = $2, decided_at = $3 WHERE id = $4`
+	_, err := r.pool.Exec(ctx, query, request.Status, request.DecidedBy, request.DecidedAt, request.ID)
+	return err
+}
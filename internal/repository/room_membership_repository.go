@@ -22,9 +22,10 @@ func (r *roomMembershipRepository) AddMember(membership *domain.RoomMembership)
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `INSERT INTO room_memberships (room_id, user_id, role, created_at)
-	          VALUES ($1, $2, $3, $4)`
-	_, err := r.pool.Exec(ctx, query, membership.RoomID, membership.UserID, membership.Role, membership.CreatedAt)
+	query := `INSERT INTO room_memberships (room_id, user_id, role, permissions, created_at)
+	          VALUES ($1, $2, $3, $4, $5)`
+	_, err := r.pool.Exec(ctx, query,
+		membership.RoomID, membership.UserID, membership.Role, membership.Permissions, membership.CreatedAt)
 	return err
 }
 
@@ -32,7 +33,11 @@ func (r *roomMembershipRepository) UpdateMemberRole(roomID, userID string, role
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `UPDATE room_memberships SET role = $1 WHERE room_id = $2 AND user_id = $3`
+	query := `UPDATE room_memberships
+	          SET role = $1,
+	              permissions = CASE WHEN $1 = 'admin' THEN permissions ELSE 0 END,
+	              promoted_by = CASE WHEN $1 = 'admin' THEN promoted_by ELSE NULL END
+	          WHERE room_id = $2 AND user_id = $3`
 	cmdTag, err := r.pool.Exec(ctx, query, role, roomID, userID)
 	if err != nil {
 		return err
@@ -43,6 +48,36 @@ func (r *roomMembershipRepository) UpdateMemberRole(roomID, userID string, role
 	return nil
 }
 
+func (r *roomMembershipRepository) UpdateMemberPermissions(roomID, userID string, permissions domain.AdminPermissions) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `UPDATE room_memberships SET permissions = $1 WHERE room_id = $2 AND user_id = $3`
+	cmdTag, err := r.pool.Exec(ctx, query, permissions, roomID, userID)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return errors.New("membership not found")
+	}
+	return nil
+}
+
+func (r *roomMembershipRepository) SetAdmin(roomID, userID string, permissions domain.AdminPermissions, promotedBy string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `UPDATE room_memberships SET role = $1, permissions = $2, promoted_by = $3 WHERE room_id = $4 AND user_id = $5`
+	cmdTag, err := r.pool.Exec(ctx, query, domain.RoleAdmin, permissions, promotedBy, roomID, userID)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return errors.New("membership not found")
+	}
+	return nil
+}
+
 func (r *roomMembershipRepository) RemoveMember(roomID, userID string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -56,7 +91,7 @@ func (r *roomMembershipRepository) GetMembers(roomID string) ([]*domain.RoomMemb
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `SELECT room_id, user_id, role, created_at FROM room_memberships WHERE room_id = $1`
+	query := `SELECT room_id, user_id, role, permissions, promoted_by, created_at FROM room_memberships WHERE room_id = $1`
 	rows, err := r.pool.Query(ctx, query, roomID)
 	if err != nil {
 		return nil, err
@@ -66,7 +101,7 @@ func (r *roomMembershipRepository) GetMembers(roomID string) ([]*domain.RoomMemb
 	var memberships []*domain.RoomMembership
 	for rows.Next() {
 		var m domain.RoomMembership
-		err := rows.Scan(&m.RoomID, &m.UserID, &m.Role, &m.CreatedAt)
+		err := rows.Scan(&m.RoomID, &m.UserID, &m.Role, &m.Permissions, &m.PromotedBy, &m.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -108,3 +143,33 @@ func (r *roomMembershipRepository) GetMemberRole(roomID, userID string) (domain.
 	}
 	return domain.RoomMembershipRole(role), nil
 }
+
+func (r *roomMembershipRepository) GetMembership(roomID, userID string) (*domain.RoomMembership, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT room_id, user_id, role, permissions, promoted_by, created_at
+	          FROM room_memberships WHERE room_id = $1 AND user_id = $2`
+	row := r.pool.QueryRow(ctx, query, roomID, userID)
+	var m domain.RoomMembership
+	err := row.Scan(&m.RoomID, &m.UserID, &m.Role, &m.Permissions, &m.PromotedBy, &m.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *roomMembershipRepository) CountMembers(roomID string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT COUNT(*) FROM room_memberships WHERE room_id = $1 AND role <> $2`
+	var count int
+	if err := r.pool.QueryRow(ctx, query, roomID, domain.RoleBanned).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
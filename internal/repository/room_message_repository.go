@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"time"
 
@@ -10,6 +11,31 @@ import (
 	"social_media/internal/domain"
 )
 
+// roomMessageColumns is the column list scanRoomMessage expects.
+const roomMessageColumns = `id, room_id, sender_id, content, kind, reference_id, forwarded_from, entities,
+	COALESCE(preview_url, ''), link_preview, created_at, updated_at`
+
+func scanRoomMessage(row pgx.Row) (*domain.RoomMessage, error) {
+	var message domain.RoomMessage
+	var forwardedFrom, entities, linkPreview []byte
+	err := row.Scan(&message.ID, &message.RoomID, &message.SenderID, &message.Content,
+		&message.Kind, &message.ReferenceID, &forwardedFrom, &entities,
+		&message.PreviewURL, &linkPreview, &message.CreatedAt, &message.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if message.ForwardedFrom, err = decodeForwardOrigin(forwardedFrom); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(entities, &message.Entities); err != nil {
+		return nil, err
+	}
+	if message.LinkPreview, err = decodeLinkPreview(linkPreview); err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
 type roomMessageRepository struct {
 	pool *pgxpool.Pool
 }
@@ -22,10 +48,65 @@ func (r *roomMessageRepository) Create(message *domain.RoomMessage) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `INSERT INTO room_messages (id, room_id, sender_id, content, created_at, updated_at)
-	          VALUES ($1, $2, $3, $4, $5, $6)`
-	_, err := r.pool.Exec(ctx, query, message.ID, message.RoomID, message.SenderID, message.Content, message.CreatedAt, message.UpdatedAt)
-	return err
+	forwardedFrom, err := encodeForwardOrigin(message.ForwardedFrom)
+	if err != nil {
+		return err
+	}
+	entities, err := encodeEntities(message.Entities)
+	if err != nil {
+		return err
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `INSERT INTO room_messages (id, room_id, sender_id, content, kind, reference_id, forwarded_from, entities, preview_url, created_at, updated_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	_, err = tx.Exec(ctx, query, message.ID, message.RoomID, message.SenderID, message.Content,
+		message.Kind, message.ReferenceID, forwardedFrom, entities, nullIfEmpty(message.PreviewURL), message.CreatedAt, message.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if message.Poll != nil {
+		if err := insertPoll(ctx, tx, message.Poll); err != nil {
+			return err
+		}
+	}
+	if len(message.Mentioned) > 0 {
+		query := `INSERT INTO room_mentions (message_id, room_id, user_id, created_at)
+		          SELECT $1, $2, u, $4 FROM unnest($3::uuid[]) AS u
+		          ON CONFLICT DO NOTHING`
+		if _, err := tx.Exec(ctx, query, message.ID, message.RoomID, message.Mentioned, message.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// attachPolls loads the polls of the poll messages among messages, with the
+// votes of viewerID when it is not empty.
+func (r *roomMessageRepository) attachPolls(ctx context.Context, viewerID string, messages ...*domain.RoomMessage) error {
+	var messageIDs []string
+	for _, message := range messages {
+		if message.Kind == domain.MessageKindPoll {
+			messageIDs = append(messageIDs, message.ID)
+		}
+	}
+	polls, err := loadPolls(ctx, r.pool, messageIDs)
+	if err != nil {
+		return err
+	}
+	if viewerID != "" {
+		if err := loadVotesOf(ctx, r.pool, polls, viewerID); err != nil {
+			return err
+		}
+	}
+	for _, message := range messages {
+		message.Poll = polls[message.ID]
+	}
+	return nil
 }
 
 func (r *roomMessageRepository) Update(message *domain.RoomMessage) error {
@@ -46,11 +127,11 @@ func (r *roomMessageRepository) Delete(messageID string) error {
 	return err
 }
 
-func (r *roomMessageRepository) FindByRoom(roomID string) ([]*domain.RoomMessage, error) {
+func (r *roomMessageRepository) FindByRoom(roomID, viewerID string) ([]*domain.RoomMessage, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `SELECT id, room_id, sender_id, content, created_at, updated_at FROM room_messages
+	query := `SELECT ` + roomMessageColumns + ` FROM room_messages
 	          WHERE room_id = $1 ORDER BY created_at ASC`
 	rows, err := r.pool.Query(ctx, query, roomID)
 	if err != nil {
@@ -60,12 +141,14 @@ func (r *roomMessageRepository) FindByRoom(roomID string) ([]*domain.RoomMessage
 
 	var messages []*domain.RoomMessage
 	for rows.Next() {
-		var message domain.RoomMessage
-		err := rows.Scan(&message.ID, &message.RoomID, &message.SenderID, &message.Content, &message.CreatedAt, &message.UpdatedAt)
+		message, err := scanRoomMessage(rows)
 		if err != nil {
 			return nil, err
 		}
-		messages = append(messages, &message)
+		messages = append(messages, message)
+	}
+	if err := r.attachPolls(ctx, viewerID, messages...); err != nil {
+		return nil, err
 	}
 	return messages, nil
 }
@@ -74,15 +157,52 @@ func (r *roomMessageRepository) FindByID(messageID string) (*domain.RoomMessage,
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `SELECT id, room_id, sender_id, content, created_at, updated_at FROM room_messages WHERE id = $1`
-	row := r.pool.QueryRow(ctx, query, messageID)
-	var message domain.RoomMessage
-	err := row.Scan(&message.ID, &message.RoomID, &message.SenderID, &message.Content, &message.CreatedAt, &message.UpdatedAt)
+	query := `SELECT ` + roomMessageColumns + ` FROM room_messages WHERE id = $1`
+	message, err := scanRoomMessage(r.pool.QueryRow(ctx, query, messageID))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, err
 	}
-	return &message, nil
+	if err := r.attachPolls(ctx, "", message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+func (r *roomMessageRepository) FindPendingPreviews(limit int) ([]*domain.RoomMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + roomMessageColumns + ` FROM room_messages
+	          WHERE preview_url IS NOT NULL ORDER BY created_at ASC LIMIT $1`
+	rows, err := r.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []*domain.RoomMessage
+	for rows.Next() {
+		message, err := scanRoomMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+func (r *roomMessageRepository) SetLinkPreview(messageID string, preview *domain.LinkPreview) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	linkPreview, err := encodeLinkPreview(preview)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE room_messages SET preview_url = NULL, link_preview = $1 WHERE id = $2`
+	_, err = r.pool.Exec(ctx, query, linkPreview, messageID)
+	return err
 }
@@ -22,10 +22,11 @@ func (r *roomRepository) Create(room *domain.Room) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `INSERT INTO rooms (id, name, username, type, owner_id, created_at, updated_at)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	query := `INSERT INTO rooms (id, name, username, type, description, photo_url, rules, join_by_request, owner_id, created_at, updated_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
 	_, err := r.pool.Exec(ctx, query,
-		room.ID, room.Name, room.Username, room.Type, room.OwnerID, room.CreatedAt, room.UpdatedAt)
+		room.ID, room.Name, room.Username, room.Type, room.Description, room.PhotoURL, room.Rules, room.JoinByRequest,
+		room.OwnerID, room.CreatedAt, room.UpdatedAt)
 	return err
 }
 
@@ -33,8 +34,11 @@ func (r *roomRepository) Update(room *domain.Room) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `UPDATE rooms SET name = $1, username = $2, updated_at = $3 WHERE id = $4`
-	_, err := r.pool.Exec(ctx, query, room.Name, room.Username, room.UpdatedAt, room.ID)
+	query := `UPDATE rooms SET name = $1, username = $2, description = $3, photo_url = $4, rules = $5,
+	          join_by_request = $6, updated_at = $7
+	          WHERE id = $8`
+	_, err := r.pool.Exec(ctx, query,
+		room.Name, room.Username, room.Description, room.PhotoURL, room.Rules, room.JoinByRequest, room.UpdatedAt, room.ID)
 	return err
 }
 
@@ -51,10 +55,11 @@ func (r *roomRepository) FindByID(roomID string) (*domain.Room, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `SELECT id, name, username, type, owner_id, created_at, updated_at FROM rooms WHERE id = $1`
+	query := `SELECT id, name, username, type, description, photo_url, rules, join_by_request, owner_id, created_at, updated_at FROM rooms WHERE id = $1`
 	row := r.pool.QueryRow(ctx, query, roomID)
 	var room domain.Room
-	err := row.Scan(&room.ID, &room.Name, &room.Username, &room.Type, &room.OwnerID, &room.CreatedAt, &room.UpdatedAt)
+	err := row.Scan(&room.ID, &room.Name, &room.Username, &room.Type, &room.Description, &room.PhotoURL, &room.Rules,
+		&room.JoinByRequest, &room.OwnerID, &room.CreatedAt, &room.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -68,10 +73,11 @@ func (r *roomRepository) FindByUsername(username string) (*domain.Room, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `SELECT id, name, username, type, owner_id, created_at, updated_at FROM rooms WHERE username = $1`
+	query := `SELECT id, name, username, type, description, photo_url, rules, join_by_request, owner_id, created_at, updated_at FROM rooms WHERE username = $1`
 	row := r.pool.QueryRow(ctx, query, username)
 	var room domain.Room
-	err := row.Scan(&room.ID, &room.Name, &room.Username, &room.Type, &room.OwnerID, &room.CreatedAt, &room.UpdatedAt)
+	err := row.Scan(&room.ID, &room.Name, &room.Username, &room.Type, &room.Description, &room.PhotoURL, &room.Rules,
+		&room.JoinByRequest, &room.OwnerID, &room.CreatedAt, &room.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -80,3 +86,98 @@ func (r *roomRepository) FindByUsername(username string) (*domain.Room, error) {
 	}
 	return &room, nil
 }
+
+func (r *roomRepository) SearchPublic(query string, limit, offset int) ([]*domain.RoomDetails, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	prefix := escapeLike(query) + "%"
+	sqlQuery := `SELECT r.id, r.name, r.username, r.type, r.description, r.photo_url, r.rules,
+	                    r.join_by_request, r.owner_id, r.created_at, r.updated_at,
+	                    (SELECT COUNT(*) FROM room_memberships m WHERE m.room_id = r.id AND m.role <> 'banned') AS member_count
+	             FROM rooms r
+	             WHERE r.username IS NOT NULL
+	               AND ($1 = '' OR r.username ILIKE $2 OR r.name ILIKE $2 OR r.username % $1 OR r.name % $1)
+	             ORDER BY CASE WHEN $1 = '' THEN 0 ELSE GREATEST(similarity(r.username, $1), similarity(r.name, $1)) END DESC,
+	                      member_count DESC, r.name ASC
+	             LIMIT $3 OFFSET $4`
+	rows, err := r.pool.Query(ctx, sqlQuery, query, prefix, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rooms []*domain.RoomDetails
+	for rows.Next() {
+		var room domain.RoomDetails
+		err := rows.Scan(&room.ID, &room.Name, &room.Username, &room.Type, &room.Description, &room.PhotoURL, &room.Rules,
+			&room.JoinByRequest, &room.OwnerID, &room.CreatedAt, &room.UpdatedAt, &room.MemberCount)
+		if err != nil {
+			return nil, err
+		}
+		rooms = append(rooms, &room)
+	}
+	return rooms, rows.Err()
+}
+
+func (r *roomRepository) FindByMember(userID string) ([]*domain.Room, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT r.id, r.name, r.username, r.type, r.description, r.photo_url, r.rules, r.join_by_request, r.owner_id, r.created_at, r.updated_at
+	          FROM rooms r JOIN room_memberships m ON m.room_id = r.id
+	          WHERE m.user_id = $1 AND m.role <> 'banned'
+	          ORDER BY r.updated_at DESC`
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rooms []*domain.Room
+	for rows.Next() {
+		var room domain.Room
+		err := rows.Scan(&room.ID, &room.Name, &room.Username, &room.Type, &room.Description, &room.PhotoURL, &room.Rules,
+			&room.JoinByRequest, &room.OwnerID, &room.CreatedAt, &room.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		rooms = append(rooms, &room)
+	}
+	return rooms, rows.Err()
+}
+
+func (r *roomRepository) TransferOwnership(roomID, fromUserID, toUserID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	cmdTag, err := tx.Exec(ctx, `UPDATE rooms SET owner_id = $1, updated_at = $2 WHERE id = $3 AND owner_id = $4`,
+		toUserID, time.Now(), roomID, fromUserID)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return errors.New("room owner changed")
+	}
+	_, err = tx.Exec(ctx, `UPDATE room_memberships SET role = $1, permissions = $2, promoted_by = $3 WHERE room_id = $4 AND user_id = $5`,
+		domain.RoleAdmin, domain.AllAdminPermissions, toUserID, roomID, fromUserID)
+	if err != nil {
+		return err
+	}
+	cmdTag, err = tx.Exec(ctx, `UPDATE room_memberships SET role = $1, permissions = 0, promoted_by = NULL
+	                            WHERE room_id = $2 AND user_id = $3 AND role <> $4`,
+		domain.RoleOwner, roomID, toUserID, domain.RoleBanned)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return errors.New("membership not found")
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"social_media/internal/domain"
+)
+
+type roomRestrictionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRoomRestrictionRepository(pool *pgxpool.Pool) domain.RoomRestrictionRepository {
+	return &roomRestrictionRepository{pool: pool}
+}
+
+func (r *roomRestrictionRepository) Upsert(restriction *domain.RoomRestriction) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO room_restrictions (id, room_id, user_id, kind, reason, prior_role, prior_permissions, actor_id, expires_at, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	          ON CONFLICT (room_id, user_id, kind) DO UPDATE
+	          SET reason = EXCLUDED.reason, actor_id = EXCLUDED.actor_id,
+	              expires_at = EXCLUDED.expires_at, created_at = EXCLUDED.created_at`
+	_, err := r.pool.Exec(ctx, query, restriction.ID, restriction.RoomID, restriction.UserID, restriction.Kind,
+		restriction.Reason, restriction.PriorRole, restriction.PriorPermissions, restriction.ActorID,
+		restriction.ExpiresAt, restriction.CreatedAt)
+	return err
+}
+
+func (r *roomRestrictionRepository) Find(roomID, userID string, kind domain.RestrictionKind) (*domain.RoomRestriction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT id, room_id, user_id, kind, reason, prior_role, prior_permissions, actor_id, expires_at, created_at
+	          FROM room_restrictions WHERE room_id = $1 AND user_id = $2 AND kind = $3`
+	row := r.pool.QueryRow(ctx, query, roomID, userID, kind)
+	restriction, err := scanRestriction(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return restriction, nil
+}
+
+func (r *roomRestrictionRepository) Delete(roomID, userID string, kind domain.RestrictionKind) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `DELETE FROM room_restrictions WHERE room_id = $1 AND user_id = $2 AND kind = $3`
+	_, err := r.pool.Exec(ctx, query, roomID, userID, kind)
+	return err
+}
+
+func (r *roomRestrictionRepository) FindExpired(now time.Time, limit int) ([]*domain.RoomRestriction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT id, room_id, user_id, kind, reason, prior_role, prior_permissions, actor_id, expires_at, created_at
+	          FROM room_restrictions WHERE expires_at <= $1
+	          ORDER BY expires_at LIMIT $2`
+	rows, err := r.pool.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var restrictions []*domain.RoomRestriction
+	for rows.Next() {
+		restriction, err := scanRestriction(rows)
+		if err != nil {
+			return nil, err
+		}
+		restrictions = append(restrictions, restriction)
+	}
+	return restrictions, rows.Err()
+}
+
+func scanRestriction(row pgx.Row) (*domain.RoomRestriction, error) {
+	var rs domain.RoomRestriction
+	err := row.Scan(&rs.ID, &rs.RoomID, &rs.UserID, &rs.Kind, &rs.Reason, &rs.PriorRole, &rs.PriorPermissions,
+		&rs.ActorID, &rs.ExpiresAt, &rs.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &rs, nil
+}
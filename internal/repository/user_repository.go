@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v4"
@@ -10,6 +11,10 @@ import (
 	"social_media/internal/domain"
 )
 
+// userColumns lists the users columns in the order scanUser reads them.
+const userColumns = `id, name, phone, username, password, discoverable,
+	phone_visibility, find_by_phone, message_privacy, group_invite_privacy, created_at`
+
 type userRepository struct {
 	pool *pgxpool.Pool
 }
@@ -22,10 +27,12 @@ func (r *userRepository) Create(user *domain.User) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `INSERT INTO users (id, name, phone, username, password, created_at)
-			  VALUES ($1, $2, $3, $4, $5, $6)`
+	query := `INSERT INTO users (` + userColumns + `)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
 	_, err := r.pool.Exec(ctx, query,
-		user.ID, user.Name, user.Phone, user.Username, user.Password, user.CreatedAt)
+		user.ID, user.Name, user.Phone, user.Username, user.Password, user.Discoverable,
+		user.Privacy.PhoneVisibility, user.Privacy.FindByPhone, user.Privacy.Messages, user.Privacy.GroupInvites,
+		user.CreatedAt)
 	return err
 }
 
@@ -33,59 +40,70 @@ func (r *userRepository) FindByPhone(phone string) (*domain.User, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `SELECT id, name, phone, username, password, created_at FROM users WHERE phone = $1`
-	row := r.pool.QueryRow(ctx, query, phone)
-	var user domain.User
-	err := row.Scan(&user.ID, &user.Name, &user.Phone, &user.Username, &user.Password, &user.CreatedAt)
+	query := `SELECT ` + userColumns + ` FROM users WHERE phone = $1`
+	user, err := scanUser(r.pool.QueryRow(ctx, query, phone))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return user, err
+}
+
+func (r *userRepository) FindByPhones(phones, phoneHashes []string) ([]*domain.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + userColumns + ` FROM users WHERE phone = ANY($1) OR phone_hash = ANY($2)`
+	rows, err := r.pool.Query(ctx, query, phones, phoneHashes)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, nil
-		}
 		return nil, err
 	}
-	return &user, nil
+	defer rows.Close()
+
+	var users []*domain.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
 }
 
 func (r *userRepository) FindByUsername(username string) (*domain.User, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `SELECT id, name, phone, username, password, created_at FROM users WHERE username = $1`
-	row := r.pool.QueryRow(ctx, query, username)
-	var user domain.User
-	err := row.Scan(&user.ID, &user.Name, &user.Phone, &user.Username, &user.Password, &user.CreatedAt)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, nil
-		}
-		return nil, err
+	query := `SELECT ` + userColumns + ` FROM users WHERE username = $1`
+	user, err := scanUser(r.pool.QueryRow(ctx, query, username))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
 	}
-	return &user, nil
+	return user, err
 }
 
 func (r *userRepository) FindByID(id string) (*domain.User, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `SELECT id, name, phone, username, password, created_at FROM users WHERE id = $1`
-	row := r.pool.QueryRow(ctx, query, id)
-	var user domain.User
-	err := row.Scan(&user.ID, &user.Name, &user.Phone, &user.Username, &user.Password, &user.CreatedAt)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, nil
-		}
-		return nil, err
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	user, err := scanUser(r.pool.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
 	}
-	return &user, nil
+	return user, err
 }
 
 func (r *userRepository) Update(user *domain.User) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `UPDATE users SET name = $1, phone = $2, username = $3, password = $4 WHERE id = $5`
-	_, err := r.pool.Exec(ctx, query, user.Name, user.Phone, user.Username, user.Password, user.ID)
+	query := `UPDATE users SET name = $1, phone = $2, username = $3, password = $4, discoverable = $5,
+			  phone_visibility = $6, find_by_phone = $7, message_privacy = $8, group_invite_privacy = $9
+			  WHERE id = $10`
+	_, err := r.pool.Exec(ctx, query, user.Name, user.Phone, user.Username, user.Password, user.Discoverable,
+		user.Privacy.PhoneVisibility, user.Privacy.FindByPhone, user.Privacy.Messages, user.Privacy.GroupInvites,
+		user.ID)
 	return err
 }
 
@@ -97,3 +115,52 @@ func (r *userRepository) Delete(user *domain.User) error {
 	_, err := r.pool.Exec(ctx, query, user.ID)
 	return err
 }
+
+func (r *userRepository) Search(query, searcherID string, limit, offset int) ([]*domain.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Prefix matches use the escaped query with LIKE; fuzzy matches use the
+	// pg_trgm similarity operator, both backed by the trigram GIN indexes.
+	prefix := escapeLike(query) + "%"
+	sqlQuery := `SELECT ` + userColumns + ` FROM users
+			  WHERE discoverable AND id <> $1
+			  AND NOT EXISTS (SELECT 1 FROM user_blocks b
+			                  WHERE (b.blocker_id = users.id AND b.blocked_id = $1)
+			                     OR (b.blocker_id = $1 AND b.blocked_id = users.id))
+			  AND (username ILIKE '@' || $2 OR name ILIKE $2 OR username % $3 OR name % $3)
+			  ORDER BY GREATEST(similarity(COALESCE(username, ''), $3), similarity(name, $3)) DESC, name ASC
+			  LIMIT $4 OFFSET $5`
+	rows, err := r.pool.Query(ctx, sqlQuery, searcherID, prefix, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*domain.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// scanUser reads a row selected with userColumns.
+func scanUser(row pgx.Row) (*domain.User, error) {
+	var user domain.User
+	err := row.Scan(&user.ID, &user.Name, &user.Phone, &user.Username, &user.Password, &user.Discoverable,
+		&user.Privacy.PhoneVisibility, &user.Privacy.FindByPhone, &user.Privacy.Messages, &user.Privacy.GroupInvites,
+		&user.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s so it is matched literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"social_media/internal/domain"
+)
+
+// AuditService exposes a room's moderation audit log to its owner and admins.
+type AuditService interface {
+	GetAuditLog(roomID, requesterID string, filter domain.RoomAuditFilter) ([]*domain.RoomAuditEntry, error)
+}
+
+type auditService struct {
+	membershipRepo domain.RoomMembershipRepository
+	auditRepo      domain.RoomAuditRepository
+}
+
+// NewAuditService creates a new instance of AuditService.
+func NewAuditService(membershipRepo domain.RoomMembershipRepository, auditRepo domain.RoomAuditRepository) AuditService {
+	return &auditService{membershipRepo: membershipRepo, auditRepo: auditRepo}
+}
+
+// GetAuditLog returns a page of the room's audit log, newest first.
+func (s *auditService) GetAuditLog(roomID, requesterID string, filter domain.RoomAuditFilter) ([]*domain.RoomAuditEntry, error) {
+	role, err := s.membershipRepo.GetMemberRole(roomID, requesterID)
+	if err != nil {
+		return nil, err
+	}
+	if role.Rank() < domain.RoleAdmin.Rank() {
+		return nil, forbiddenError("not authorized to view audit log")
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, errors.New("invalid date range")
+	}
+	filter.Limit, filter.Offset = normalizePage(filter.Limit, filter.Offset)
+	return s.auditRepo.Find(roomID, filter)
+}
+
+// appendAudit records a moderation action. before and after are stored as
+// JSON snapshots; nil snapshots and an empty targetID are omitted.
+func appendAudit(
+	auditRepo domain.RoomAuditRepository,
+	roomID, actorID string,
+	action domain.AuditAction,
+	targetID string,
+	before, after interface{},
+) error {
+	entry := &domain.RoomAuditEntry{
+		ID:        uuid.New().String(),
+		RoomID:    roomID,
+		ActorID:   actorID,
+		Action:    action,
+		CreatedAt: time.Now(),
+	}
+	if targetID != "" {
+		entry.TargetID = &targetID
+	}
+	var err error
+	if before != nil {
+		if entry.Before, err = json.Marshal(before); err != nil {
+			return err
+		}
+	}
+	if after != nil {
+		if entry.After, err = json.Marshal(after); err != nil {
+			return err
+		}
+	}
+	return auditRepo.Append(entry)
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"social_media/internal/domain"
+	"social_media/internal/mocks"
+)
+
+// Test 1: Regular members cannot read the audit log.
+func TestGetAuditLogUnauthorized(t *testing.T) {
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	auditService := NewAuditService(membershipRepoMock, auditRepoMock)
+
+	membershipRepoMock.On("GetMemberRole", "room1", "user1").Return(domain.RoleMember, nil)
+
+	entries, err := auditService.GetAuditLog("room1", "user1", domain.RoomAuditFilter{})
+	assert.Nil(t, entries)
+	assert.EqualError(t, err, "not authorized to view audit log")
+	auditRepoMock.AssertNotCalled(t, "Find")
+}
+
+// Test 2: Admins read the audit log with the default page size.
+func TestGetAuditLogSuccess(t *testing.T) {
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	auditService := NewAuditService(membershipRepoMock, auditRepoMock)
+
+	expected := []*domain.RoomAuditEntry{{ID: "entry1", RoomID: "room1", ActorID: "admin1", Action: domain.AuditBan}}
+	filter := domain.RoomAuditFilter{Action: domain.AuditBan}
+	membershipRepoMock.On("GetMemberRole", "room1", "admin1").Return(domain.RoleAdmin, nil)
+	auditRepoMock.On("Find", "room1", domain.RoomAuditFilter{Action: domain.AuditBan, Limit: defaultSearchLimit}).Return(expected, nil)
+
+	entries, err := auditService.GetAuditLog("room1", "admin1", filter)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, entries)
+	auditRepoMock.AssertExpectations(t)
+}
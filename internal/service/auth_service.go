@@ -66,11 +66,13 @@ func (s *authService) Register(name, phone, username, password string) (*domain.
 	}
 
 	user := &domain.User{
-		ID:        userID,
-		Name:      name,
-		Phone:     phone,
-		Password:  string(hashedPassword),
-		CreatedAt: time.Now(),
+		ID:           userID,
+		Name:         name,
+		Phone:        phone,
+		Password:     string(hashedPassword),
+		Discoverable: true, // listed in the user directory until they opt out
+		Privacy:      domain.DefaultPrivacySettings(),
+		CreatedAt:    time.Now(),
 	}
 
 	// Set username only if provided.
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"social_media/internal/domain"
+)
+
+// BlockService manages the users a user has blocked from messaging them.
+type BlockService interface {
+	BlockUser(blockerID, blockedID string) error
+	UnblockUser(blockerID, blockedID string) error
+	GetBlockedUsers(blockerID string) ([]*domain.UserBlock, error)
+}
+
+type blockService struct {
+	userRepo  domain.UserRepository
+	blockRepo domain.BlockRepository
+}
+
+// NewBlockService creates a new instance of BlockService.
+func NewBlockService(userRepo domain.UserRepository, blockRepo domain.BlockRepository) BlockService {
+	return &blockService{userRepo: userRepo, blockRepo: blockRepo}
+}
+
+// BlockUser blocks another user. Blocking an already blocked user succeeds.
+func (s *blockService) BlockUser(blockerID, blockedID string) error {
+	if blockerID == blockedID {
+		return errors.New("cannot block yourself")
+	}
+	user, err := s.userRepo.FindByID(blockedID)
+	if err != nil || user == nil {
+		return errors.New("user not found")
+	}
+	return s.blockRepo.Block(&domain.UserBlock{
+		BlockerID: blockerID,
+		BlockedID: blockedID,
+		CreatedAt: time.Now(),
+	})
+}
+
+// UnblockUser removes a block. Unblocking a user who is not blocked succeeds.
+func (s *blockService) UnblockUser(blockerID, blockedID string) error {
+	return s.blockRepo.Unblock(blockerID, blockedID)
+}
+
+// GetBlockedUsers lists the users blocked by blockerID.
+func (s *blockService) GetBlockedUsers(blockerID string) ([]*domain.UserBlock, error) {
+	return s.blockRepo.FindByBlocker(blockerID)
+}
+
+// blockedEitherWay reports whether either user has blocked the other. Anything
+// that shows one user to another (search, profiles and, once added, presence)
+// hides the other user when it holds.
+func blockedEitherWay(blockRepo domain.BlockRepository, userA, userB string) (bool, error) {
+	blocked, err := blockRepo.IsBlocked(userA, userB)
+	if err != nil || blocked {
+		return blocked, err
+	}
+	return blockRepo.IsBlocked(userB, userA)
+}
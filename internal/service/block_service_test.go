@@ -0,0 +1,37 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+	"social_media/internal/mocks"
+)
+
+// Test 1: Users cannot block themselves.
+func TestBlockUserSelf(t *testing.T) {
+	userRepoMock := new(mocks.UserRepositoryMock)
+	blockRepoMock := new(mocks.BlockRepositoryMock)
+	blockService := NewBlockService(userRepoMock, blockRepoMock)
+
+	err := blockService.BlockUser("user1", "user1")
+	assert.EqualError(t, err, "cannot block yourself")
+	blockRepoMock.AssertNotCalled(t, "Block", mock.Anything)
+}
+
+// Test 2: Block another user.
+func TestBlockUserSuccess(t *testing.T) {
+	userRepoMock := new(mocks.UserRepositoryMock)
+	blockRepoMock := new(mocks.BlockRepositoryMock)
+	blockService := NewBlockService(userRepoMock, blockRepoMock)
+
+	userRepoMock.On("FindByID", "user2").Return(&domain.User{ID: "user2"}, nil)
+	blockRepoMock.On("Block", mock.MatchedBy(func(b *domain.UserBlock) bool {
+		return b.BlockerID == "user1" && b.BlockedID == "user2"
+	})).Return(nil)
+
+	err := blockService.BlockUser("user1", "user2")
+	assert.Nil(t, err)
+	blockRepoMock.AssertExpectations(t)
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"social_media/internal/domain"
+)
+
+const (
+	maxBookmarkNoteRunes = 1024
+	maxBookmarkTags      = 10
+	maxBookmarkTagRunes  = 32
+)
+
+var (
+	ErrBookmarkNotFound  = errors.New("bookmark not found")
+	ErrAlreadyBookmarked = errors.New("message is already bookmarked")
+)
+
+// BookmarkService manages a user's saved messages. Any message the user can read
+// may be saved; the bookmark keeps a snapshot of it.
+type BookmarkService interface {
+	AddBookmark(userID string, source domain.ChatRef, messageID, note string, tags []string) (*domain.Bookmark, error)
+	// UpdateBookmark replaces the bookmark's note and tags.
+	UpdateBookmark(userID, bookmarkID, note string, tags []string) (*domain.Bookmark, error)
+	RemoveBookmark(userID, bookmarkID string) error
+	GetBookmarks(userID string, filter domain.BookmarkFilter) ([]*domain.Bookmark, error)
+}
+
+type bookmarkService struct {
+	convoRepo       domain.ConversationRepository
+	messageRepo     domain.MessageRepository
+	roomRepo        domain.RoomRepository
+	membershipRepo  domain.RoomMembershipRepository
+	restrictionRepo domain.RoomRestrictionRepository
+	roomMessageRepo domain.RoomMessageRepository
+	bookmarkRepo    domain.BookmarkRepository
+}
+
+// NewBookmarkService creates a new instance of BookmarkService.
+func NewBookmarkService(
+	convoRepo domain.ConversationRepository,
+	messageRepo domain.MessageRepository,
+	roomRepo domain.RoomRepository,
+	membershipRepo domain.RoomMembershipRepository,
+	restrictionRepo domain.RoomRestrictionRepository,
+	roomMessageRepo domain.RoomMessageRepository,
+	bookmarkRepo domain.BookmarkRepository,
+) BookmarkService {
+	return &bookmarkService{
+		convoRepo:       convoRepo,
+		messageRepo:     messageRepo,
+		roomRepo:        roomRepo,
+		membershipRepo:  membershipRepo,
+		restrictionRepo: restrictionRepo,
+		roomMessageRepo: roomMessageRepo,
+		bookmarkRepo:    bookmarkRepo,
+	}
+}
+
+func (s *bookmarkService) AddBookmark(userID string, source domain.ChatRef, messageID, note string, tags []string) (*domain.Bookmark, error) {
+	note, tags, err := validateBookmark(note, tags)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkReadAccess(s.convoRepo, s.roomRepo, s.membershipRepo, s.restrictionRepo, userID, source); err != nil {
+		return nil, err
+	}
+	snapshot, err := s.snapshot(source, messageID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.bookmarkRepo.FindByMessage(userID, source, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrAlreadyBookmarked
+	}
+	bookmark := &domain.Bookmark{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Source:    source,
+		MessageID: messageID,
+		Snapshot:  *snapshot,
+		Note:      note,
+		Tags:      tags,
+		CreatedAt: time.Now(),
+	}
+	if err := s.bookmarkRepo.Create(bookmark); err != nil {
+		return nil, err
+	}
+	return bookmark, nil
+}
+
+// snapshot copies a message of the source chat.
+func (s *bookmarkService) snapshot(source domain.ChatRef, messageID string) (*domain.MessageSnapshot, error) {
+	if source.Kind == domain.ChatRoom {
+		message, err := s.roomMessageRepo.FindByID(messageID)
+		if err != nil {
+			return nil, err
+		}
+		if message == nil || message.RoomID != source.ID || message.Kind.IsService() {
+			return nil, ErrMessageNotFound
+		}
+		return &domain.MessageSnapshot{SenderID: message.SenderID, Content: message.Content,
+			ForwardedFrom: message.ForwardedFrom, SentAt: message.CreatedAt}, nil
+	}
+	message, err := s.messageRepo.FindByID(messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message == nil || message.ConversationID != source.ID || message.Kind.IsService() {
+		return nil, ErrMessageNotFound
+	}
+	return &domain.MessageSnapshot{SenderID: message.SenderID, Content: message.Content,
+		ForwardedFrom: message.ForwardedFrom, SentAt: message.CreatedAt}, nil
+}
+
+func (s *bookmarkService) UpdateBookmark(userID, bookmarkID, note string, tags []string) (*domain.Bookmark, error) {
+	note, tags, err := validateBookmark(note, tags)
+	if err != nil {
+		return nil, err
+	}
+	bookmark, err := s.ownedBookmark(userID, bookmarkID)
+	if err != nil {
+		return nil, err
+	}
+	bookmark.Note = note
+	bookmark.Tags = tags
+	if err := s.bookmarkRepo.Update(bookmark); err != nil {
+		return nil, err
+	}
+	return bookmark, nil
+}
+
+func (s *bookmarkService) RemoveBookmark(userID, bookmarkID string) error {
+	if _, err := s.ownedBookmark(userID, bookmarkID); err != nil {
+		return err
+	}
+	return s.bookmarkRepo.Delete(bookmarkID)
+}
+
+// GetBookmarks returns a page of the user's bookmarks, newest first.
+func (s *bookmarkService) GetBookmarks(userID string, filter domain.BookmarkFilter) ([]*domain.Bookmark, error) {
+	if filter.SourceKind != "" && !filter.SourceKind.Valid() {
+		return nil, fmt.Errorf("invalid source kind %q", filter.SourceKind)
+	}
+	if filter.Tag != "" {
+		filter.Tag = normalizeTag(filter.Tag)
+	}
+	filter.Limit, filter.Offset = normalizePage(filter.Limit, filter.Offset)
+	return s.bookmarkRepo.FindByUser(userID, filter)
+}
+
+// ownedBookmark loads one of the user's bookmarks; other users' bookmarks are reported as not found.
+func (s *bookmarkService) ownedBookmark(userID, bookmarkID string) (*domain.Bookmark, error) {
+	bookmark, err := s.bookmarkRepo.FindByID(bookmarkID)
+	if err != nil {
+		return nil, err
+	}
+	if bookmark == nil || bookmark.UserID != userID {
+		return nil, ErrBookmarkNotFound
+	}
+	return bookmark, nil
+}
+
+// validateBookmark trims the note and normalizes tags, dropping duplicates.
+func validateBookmark(note string, tags []string) (string, []string, error) {
+	note = strings.TrimSpace(note)
+	if len([]rune(note)) > maxBookmarkNoteRunes {
+		return "", nil, fmt.Errorf("note must be at most %d characters", maxBookmarkNoteRunes)
+	}
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" {
+			return "", nil, errors.New("tags cannot be empty")
+		}
+		if len([]rune(tag)) > maxBookmarkTagRunes {
+			return "", nil, fmt.Errorf("tags must be at most %d characters", maxBookmarkTagRunes)
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > maxBookmarkTags {
+		return "", nil, fmt.Errorf("a bookmark can have at most %d tags", maxBookmarkTags)
+	}
+	return note, normalized, nil
+}
+
+// normalizeTag lowercases a tag and strips a leading '#'.
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+	"social_media/internal/mocks"
+)
+
+// Test 1: Bookmarking a room message stores a snapshot and normalized tags.
+func TestAddBookmarkSnapshotsMessage(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	bookmarkRepoMock := new(mocks.BookmarkRepositoryMock)
+	bookmarkService := NewBookmarkService(new(mocks.ConversationRepositoryMock), new(mocks.MessageRepositoryMock), roomRepoMock,
+		membershipRepoMock, new(mocks.RoomRestrictionRepositoryMock), roomMessageRepoMock, bookmarkRepoMock)
+
+	source := domain.ChatRef{Kind: domain.ChatRoom, ID: "room1"}
+	sent := time.Now().Add(-time.Hour)
+	roomRepoMock.On("FindByID", "room1").Return(&domain.Room{ID: "room1", Type: domain.RoomTypeGroup}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user1").Return(&domain.RoomMembership{Role: domain.RoleMember}, nil)
+	roomMessageRepoMock.On("FindByID", "msg1").Return(&domain.RoomMessage{ID: "msg1", RoomID: "room1", SenderID: "user2", Content: "Pasta recipe", CreatedAt: sent}, nil)
+	bookmarkRepoMock.On("FindByMessage", "user1", source, "msg1").Return(nil, nil)
+	bookmarkRepoMock.On("Create", mock.AnythingOfType("*domain.Bookmark")).Return(nil)
+
+	bookmark, err := bookmarkService.AddBookmark("user1", source, "msg1", " try this ", []string{"#Recipes", "recipes", "dinner"})
+	assert.Nil(t, err)
+	assert.Equal(t, "Pasta recipe", bookmark.Snapshot.Content)
+	assert.Equal(t, "user2", bookmark.Snapshot.SenderID)
+	assert.Equal(t, sent, bookmark.Snapshot.SentAt)
+	assert.Equal(t, "try this", bookmark.Note)
+	assert.Equal(t, []string{"recipes", "dinner"}, bookmark.Tags)
+}
+
+// Test 2: Messages from conversations the user is not part of cannot be bookmarked.
+func TestAddBookmarkUnreadableConversation(t *testing.T) {
+	convoRepoMock := new(mocks.ConversationRepositoryMock)
+	bookmarkRepoMock := new(mocks.BookmarkRepositoryMock)
+	bookmarkService := NewBookmarkService(convoRepoMock, new(mocks.MessageRepositoryMock), new(mocks.RoomRepositoryMock),
+		new(mocks.RoomMembershipRepositoryMock), new(mocks.RoomRestrictionRepositoryMock), new(mocks.RoomMessageRepositoryMock), bookmarkRepoMock)
+
+	convoRepoMock.On("FindByID", "c1").Return(&domain.Conversation{ID: "c1", Participants: []string{"user2", "user3"}}, nil)
+
+	bookmark, err := bookmarkService.AddBookmark("user1", domain.ChatRef{Kind: domain.ChatConversation, ID: "c1"}, "msg1", "", nil)
+	assert.ErrorIs(t, err, ErrChatNotFound)
+	assert.Nil(t, bookmark)
+	bookmarkRepoMock.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// Test 3: Users cannot remove other users' bookmarks.
+func TestRemoveBookmarkNotOwner(t *testing.T) {
+	bookmarkRepoMock := new(mocks.BookmarkRepositoryMock)
+	bookmarkService := NewBookmarkService(new(mocks.ConversationRepositoryMock), new(mocks.MessageRepositoryMock), new(mocks.RoomRepositoryMock),
+		new(mocks.RoomMembershipRepositoryMock), new(mocks.RoomRestrictionRepositoryMock), new(mocks.RoomMessageRepositoryMock), bookmarkRepoMock)
+
+	bookmarkRepoMock.On("FindByID", "b1").Return(&domain.Bookmark{ID: "b1", UserID: "user2"}, nil)
+
+	err := bookmarkService.RemoveBookmark("user1", "b1")
+	assert.ErrorIs(t, err, ErrBookmarkNotFound)
+	bookmarkRepoMock.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+// Test 4: Listing normalizes the tag filter and applies the default page size.
+func TestGetBookmarksFilter(t *testing.T) {
+	bookmarkRepoMock := new(mocks.BookmarkRepositoryMock)
+	bookmarkService := NewBookmarkService(new(mocks.ConversationRepositoryMock), new(mocks.MessageRepositoryMock), new(mocks.RoomRepositoryMock),
+		new(mocks.RoomMembershipRepositoryMock), new(mocks.RoomRestrictionRepositoryMock), new(mocks.RoomMessageRepositoryMock), bookmarkRepoMock)
+
+	bookmarkRepoMock.On("FindByUser", "user1", domain.BookmarkFilter{
+		Tag: "recipes", SourceKind: domain.ChatRoom, Limit: defaultSearchLimit,
+	}).Return([]*domain.Bookmark{{ID: "b1"}}, nil)
+
+	bookmarks, err := bookmarkService.GetBookmarks("user1", domain.BookmarkFilter{Tag: "#Recipes", SourceKind: domain.ChatRoom})
+	assert.Nil(t, err)
+	assert.Len(t, bookmarks, 1)
+
+	_, err = bookmarkService.GetBookmarks("user1", domain.BookmarkFilter{SourceKind: "channel"})
+	assert.NotNil(t, err)
+}
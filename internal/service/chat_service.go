@@ -0,0 +1,488 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"social_media/internal/domain"
+)
+
+const (
+	maxPinnedChats     = 5
+	maxChatFolders     = 10
+	maxFolderNameRunes = 64
+)
+
+var (
+	ErrChatNotFound   = errors.New("chat not found")
+	ErrFolderNotFound = errors.New("folder not found")
+)
+
+// ChatFilter narrows a chat listing. Without a folder, Archived selects the
+// archive (true) or the main list (false, the default). A folder applies its own
+// rules, further narrowed by Archived when it is set.
+type ChatFilter struct {
+	FolderID string
+	Archived *bool
+}
+
+// ChatStateUpdate holds a partial chat state change. Nil fields are left unchanged.
+type ChatStateUpdate struct {
+	Archived   *bool
+	Pinned     *bool
+	MutedUntil *time.Time // a zero or past time unmutes; domain.MutedForever mutes indefinitely
+}
+
+// ChatService organizes a user's conversations and rooms: archive, pins, mutes,
+// read state and folders.
+type ChatService interface {
+	ListConversations(userID string, filter ChatFilter) ([]*domain.ConversationListItem, error)
+	ListRooms(userID string, filter ChatFilter) ([]*domain.RoomListItem, error)
+	UpdateChatState(userID string, chat domain.ChatRef, update ChatStateUpdate) (*domain.ChatState, error)
+	// ReorderPinnedChats sets the pin order; chats must list every pinned chat exactly once.
+	ReorderPinnedChats(userID string, chats []domain.ChatRef) error
+	MarkRead(userID string, chat domain.ChatRef) error
+	GetFolders(userID string) ([]*domain.ChatFolder, error)
+	CreateFolder(userID, name string, rules domain.ChatFolderRules) (*domain.ChatFolder, error)
+	UpdateFolder(userID, folderID, name string, rules domain.ChatFolderRules) (*domain.ChatFolder, error)
+	DeleteFolder(userID, folderID string) error
+}
+
+type chatService struct {
+	convoRepo       domain.ConversationRepository
+	roomRepo        domain.RoomRepository
+	membershipRepo  domain.RoomMembershipRepository
+	restrictionRepo domain.RoomRestrictionRepository
+	chatStateRepo   domain.ChatStateRepository
+	folderRepo      domain.ChatFolderRepository
+}
+
+// NewChatService creates a new instance of ChatService.
+func NewChatService(
+	convoRepo domain.ConversationRepository,
+	roomRepo domain.RoomRepository,
+	membershipRepo domain.RoomMembershipRepository,
+	restrictionRepo domain.RoomRestrictionRepository,
+	chatStateRepo domain.ChatStateRepository,
+	folderRepo domain.ChatFolderRepository,
+) ChatService {
+	return &chatService{
+		convoRepo:       convoRepo,
+		roomRepo:        roomRepo,
+		membershipRepo:  membershipRepo,
+		restrictionRepo: restrictionRepo,
+		chatStateRepo:   chatStateRepo,
+		folderRepo:      folderRepo,
+	}
+}
+
+// chatStates is a user's chat states and unread chats, keyed by chat.
+type chatStates struct {
+	states map[domain.ChatRef]*domain.ChatState
+	unread map[domain.ChatRef]bool
+}
+
+// state returns the stored state or an empty one.
+func (c chatStates) state(userID string, chat domain.ChatRef) domain.ChatState {
+	if state, ok := c.states[chat]; ok {
+		return *state
+	}
+	return domain.ChatState{UserID: userID, Chat: chat}
+}
+
+func (s *chatService) ListConversations(userID string, filter ChatFilter) ([]*domain.ConversationListItem, error) {
+	match, err := s.chatMatcher(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	convos, err := s.convoRepo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	states, err := s.loadStates(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	items := make([]*domain.ConversationListItem, 0, len(convos))
+	for _, convo := range convos {
+		chat := domain.ChatRef{Kind: domain.ChatConversation, ID: convo.ID}
+		category := domain.ChatCategoryDirect
+		if convo.Kind == domain.ConversationGroup {
+			category = domain.ChatCategoryGroup
+		}
+		item := &domain.ConversationListItem{Conversation: convo, ChatState: states.state(userID, chat), Unread: states.unread[chat]}
+		if !match(chatFacts(chat, category, &item.ChatState, item.Unread, now)) {
+			continue
+		}
+		items = append(items, item)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return pinnedBefore(items[i].PinOrder, items[j].PinOrder)
+	})
+	return items, nil
+}
+
+func (s *chatService) ListRooms(userID string, filter ChatFilter) ([]*domain.RoomListItem, error) {
+	match, err := s.chatMatcher(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	rooms, err := s.roomRepo.FindByMember(userID)
+	if err != nil {
+		return nil, err
+	}
+	states, err := s.loadStates(userID)
+	if err != nil {
+		return nil, err
+	}
+	mentions, err := s.chatStateRepo.CountUnreadMentions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	items := make([]*domain.RoomListItem, 0, len(rooms))
+	for _, room := range rooms {
+		chat := domain.ChatRef{Kind: domain.ChatRoom, ID: room.ID}
+		category := domain.ChatCategoryGroup
+		if room.Type == domain.RoomTypeChannel {
+			category = domain.ChatCategoryChannel
+		}
+		item := &domain.RoomListItem{Room: room, ChatState: states.state(userID, chat), Unread: states.unread[chat],
+			UnreadMentions: mentions[room.ID]}
+		if !match(chatFacts(chat, category, &item.ChatState, item.Unread, now)) {
+			continue
+		}
+		items = append(items, item)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return pinnedBefore(items[i].PinOrder, items[j].PinOrder)
+	})
+	return items, nil
+}
+
+// chatFacts collects what folder rules match against.
+func chatFacts(chat domain.ChatRef, category domain.ChatCategory, state *domain.ChatState, unread bool, now time.Time) domain.ChatFacts {
+	return domain.ChatFacts{
+		Chat:     chat,
+		Category: category,
+		Archived: state.Archived,
+		Muted:    state.Muted(now),
+		Unread:   unread,
+	}
+}
+
+// pinnedBefore orders pinned chats first, by pin order.
+func pinnedBefore(a, b *int) bool {
+	if a == nil || b == nil {
+		return a != nil && b == nil
+	}
+	return *a < *b
+}
+
+// chatMatcher builds the predicate for the requested listing.
+func (s *chatService) chatMatcher(userID string, filter ChatFilter) (func(domain.ChatFacts) bool, error) {
+	if filter.FolderID == "" {
+		archived := filter.Archived != nil && *filter.Archived
+		return func(chat domain.ChatFacts) bool { return chat.Archived == archived }, nil
+	}
+	folder, err := s.ownedFolder(userID, filter.FolderID)
+	if err != nil {
+		return nil, err
+	}
+	return func(chat domain.ChatFacts) bool {
+		if filter.Archived != nil && chat.Archived != *filter.Archived {
+			return false
+		}
+		return folder.Rules.Matches(chat)
+	}, nil
+}
+
+func (s *chatService) loadStates(userID string) (chatStates, error) {
+	states, err := s.chatStateRepo.FindByUser(userID)
+	if err != nil {
+		return chatStates{}, err
+	}
+	unread, err := s.chatStateRepo.FindUnread(userID)
+	if err != nil {
+		return chatStates{}, err
+	}
+	loaded := chatStates{
+		states: make(map[domain.ChatRef]*domain.ChatState, len(states)),
+		unread: make(map[domain.ChatRef]bool, len(unread)),
+	}
+	for _, state := range states {
+		loaded.states[state.Chat] = state
+	}
+	for _, chat := range unread {
+		loaded.unread[chat] = true
+	}
+	return loaded, nil
+}
+
+// UpdateChatState archives, pins or mutes a chat the user belongs to.
+func (s *chatService) UpdateChatState(userID string, chat domain.ChatRef, update ChatStateUpdate) (*domain.ChatState, error) {
+	state, err := s.memberState(userID, chat)
+	if err != nil {
+		return nil, err
+	}
+	if update.Archived != nil {
+		state.Archived = *update.Archived
+	}
+	if update.Pinned != nil {
+		if err := s.setPinned(state, *update.Pinned); err != nil {
+			return nil, err
+		}
+	}
+	if update.MutedUntil != nil {
+		state.MutedUntil = nil
+		if update.MutedUntil.After(time.Now()) {
+			mutedUntil := *update.MutedUntil
+			state.MutedUntil = &mutedUntil
+		}
+	}
+	if err := s.chatStateRepo.Save(state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// setPinned pins the chat below the existing pins, or unpins it.
+func (s *chatService) setPinned(state *domain.ChatState, pinned bool) error {
+	if !pinned {
+		state.PinOrder = nil
+		return nil
+	}
+	if state.PinOrder != nil {
+		return nil
+	}
+	states, err := s.chatStateRepo.FindByUser(state.UserID)
+	if err != nil {
+		return err
+	}
+	count, next := 0, 0
+	for _, other := range states {
+		if other.PinOrder == nil {
+			continue
+		}
+		count++
+		if *other.PinOrder >= next {
+			next = *other.PinOrder + 1
+		}
+	}
+	if count >= maxPinnedChats {
+		return fmt.Errorf("cannot pin more than %d chats", maxPinnedChats)
+	}
+	state.PinOrder = &next
+	return nil
+}
+
+func (s *chatService) ReorderPinnedChats(userID string, chats []domain.ChatRef) error {
+	states, err := s.chatStateRepo.FindByUser(userID)
+	if err != nil {
+		return err
+	}
+	pinned := make(map[domain.ChatRef]*domain.ChatState)
+	for _, state := range states {
+		if state.PinOrder != nil {
+			pinned[state.Chat] = state
+		}
+	}
+	if len(chats) != len(pinned) {
+		return errors.New("order must list every pinned chat exactly once")
+	}
+	seen := make(map[domain.ChatRef]bool, len(chats))
+	for _, chat := range chats {
+		if pinned[chat] == nil || seen[chat] {
+			return errors.New("order must list every pinned chat exactly once")
+		}
+		seen[chat] = true
+	}
+	for i, chat := range chats {
+		order := i
+		state := pinned[chat]
+		state.PinOrder = &order
+		if err := s.chatStateRepo.Save(state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarkRead marks every message currently in the chat as read.
+func (s *chatService) MarkRead(userID string, chat domain.ChatRef) error {
+	state, err := s.memberState(userID, chat)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	state.LastReadAt = &now
+	return s.chatStateRepo.Save(state)
+}
+
+// memberState checks that the user belongs to the chat and loads their state for it.
+func (s *chatService) memberState(userID string, chat domain.ChatRef) (*domain.ChatState, error) {
+	switch chat.Kind {
+	case domain.ChatConversation:
+		convo, err := s.convoRepo.FindByID(chat.ID)
+		if err != nil {
+			return nil, err
+		}
+		if convo == nil || !convo.HasParticipant(userID) {
+			return nil, ErrChatNotFound
+		}
+	case domain.ChatRoom:
+		membership, err := roomMembership(s.membershipRepo, s.restrictionRepo, chat.ID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if membership == nil || membership.Role == domain.RoleBanned {
+			return nil, ErrChatNotFound
+		}
+	default:
+		return nil, ErrChatNotFound
+	}
+	state, err := s.chatStateRepo.Get(userID, chat)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		state = &domain.ChatState{UserID: userID, Chat: chat}
+	}
+	return state, nil
+}
+
+// checkReadAccess checks that the user can read the chat's messages: conversation
+// participants, members of a room, and everyone for public rooms. Users under
+// a ban in force are refused.
+func checkReadAccess(
+	convoRepo domain.ConversationRepository,
+	roomRepo domain.RoomRepository,
+	membershipRepo domain.RoomMembershipRepository,
+	restrictionRepo domain.RoomRestrictionRepository,
+	userID string,
+	chat domain.ChatRef,
+) error {
+	switch chat.Kind {
+	case domain.ChatConversation:
+		convo, err := convoRepo.FindByID(chat.ID)
+		if err != nil {
+			return err
+		}
+		if convo == nil || !convo.HasParticipant(userID) {
+			return ErrChatNotFound
+		}
+	case domain.ChatRoom:
+		room, err := roomRepo.FindByID(chat.ID)
+		if err != nil {
+			return err
+		}
+		if room == nil {
+			return ErrChatNotFound
+		}
+		membership, err := roomMembership(membershipRepo, restrictionRepo, chat.ID, userID)
+		if err != nil {
+			return err
+		}
+		if membership != nil && membership.Role == domain.RoleBanned {
+			return ErrBanned
+		}
+		if membership == nil && !room.IsPublic() {
+			return ErrChatNotFound
+		}
+	default:
+		return ErrChatNotFound
+	}
+	return nil
+}
+
+func (s *chatService) GetFolders(userID string) ([]*domain.ChatFolder, error) {
+	return s.folderRepo.FindByUser(userID)
+}
+
+func (s *chatService) CreateFolder(userID, name string, rules domain.ChatFolderRules) (*domain.ChatFolder, error) {
+	name, err := validateFolder(name, rules)
+	if err != nil {
+		return nil, err
+	}
+	folders, err := s.folderRepo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(folders) >= maxChatFolders {
+		return nil, fmt.Errorf("cannot create more than %d folders", maxChatFolders)
+	}
+	folder := &domain.ChatFolder{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Name:      name,
+		Rules:     rules,
+		CreatedAt: time.Now(),
+	}
+	if err := s.folderRepo.Create(folder); err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+func (s *chatService) UpdateFolder(userID, folderID, name string, rules domain.ChatFolderRules) (*domain.ChatFolder, error) {
+	name, err := validateFolder(name, rules)
+	if err != nil {
+		return nil, err
+	}
+	folder, err := s.ownedFolder(userID, folderID)
+	if err != nil {
+		return nil, err
+	}
+	folder.Name = name
+	folder.Rules = rules
+	if err := s.folderRepo.Update(folder); err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+func (s *chatService) DeleteFolder(userID, folderID string) error {
+	if _, err := s.ownedFolder(userID, folderID); err != nil {
+		return err
+	}
+	return s.folderRepo.Delete(folderID)
+}
+
+// ownedFolder loads a folder belonging to userID.
+func (s *chatService) ownedFolder(userID, folderID string) (*domain.ChatFolder, error) {
+	folder, err := s.folderRepo.FindByID(folderID)
+	if err != nil {
+		return nil, err
+	}
+	if folder == nil || folder.UserID != userID {
+		return nil, ErrFolderNotFound
+	}
+	return folder, nil
+}
+
+// validateFolder checks the folder definition and returns the trimmed name.
+func validateFolder(name string, rules domain.ChatFolderRules) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("folder name is required")
+	}
+	if len([]rune(name)) > maxFolderNameRunes {
+		return "", fmt.Errorf("folder name must be at most %d characters", maxFolderNameRunes)
+	}
+	if rules.Empty() {
+		return "", errors.New("folder must include at least one chat type or chat")
+	}
+	for _, chat := range append(rules.IncludedChats, rules.ExcludedChats...) {
+		if !chat.Kind.Valid() || chat.ID == "" {
+			return "", errors.New("invalid chat in folder rules")
+		}
+	}
+	return name, nil
+}
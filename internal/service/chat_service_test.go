@@ -0,0 +1,99 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+	"social_media/internal/mocks"
+)
+
+// Test 1: The main list hides archived chats and shows pinned chats first.
+func TestListConversationsMainList(t *testing.T) {
+	convoRepoMock := new(mocks.ConversationRepositoryMock)
+	chatStateRepoMock := new(mocks.ChatStateRepositoryMock)
+	chatService := NewChatService(convoRepoMock, new(mocks.RoomRepositoryMock), new(mocks.RoomMembershipRepositoryMock), new(mocks.RoomRestrictionRepositoryMock), chatStateRepoMock, new(mocks.ChatFolderRepositoryMock))
+
+	convoRepoMock.On("FindByUser", "user1").Return([]*domain.Conversation{{ID: "c1"}, {ID: "c2"}, {ID: "c3"}}, nil)
+	pinOrder := 0
+	chatStateRepoMock.On("FindByUser", "user1").Return([]*domain.ChatState{
+		{UserID: "user1", Chat: domain.ChatRef{Kind: domain.ChatConversation, ID: "c2"}, Archived: true},
+		{UserID: "user1", Chat: domain.ChatRef{Kind: domain.ChatConversation, ID: "c3"}, PinOrder: &pinOrder},
+	}, nil)
+	chatStateRepoMock.On("FindUnread", "user1").Return([]domain.ChatRef{{Kind: domain.ChatConversation, ID: "c1"}}, nil)
+
+	items, err := chatService.ListConversations("user1", ChatFilter{})
+	assert.Nil(t, err)
+	assert.Len(t, items, 2)
+	assert.Equal(t, "c3", items[0].ID)
+	assert.Equal(t, "c1", items[1].ID)
+	assert.True(t, items[1].Unread)
+}
+
+// Test 2: An "Unread groups" folder lists only unread group rooms.
+func TestListRoomsUnreadGroupsFolder(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	chatStateRepoMock := new(mocks.ChatStateRepositoryMock)
+	folderRepoMock := new(mocks.ChatFolderRepositoryMock)
+	chatService := NewChatService(new(mocks.ConversationRepositoryMock), roomRepoMock, new(mocks.RoomMembershipRepositoryMock), new(mocks.RoomRestrictionRepositoryMock), chatStateRepoMock, folderRepoMock)
+
+	folder := &domain.ChatFolder{ID: "f1", UserID: "user1", Name: "Unread groups",
+		Rules: domain.ChatFolderRules{IncludeGroups: true, ExcludeRead: true}}
+	folderRepoMock.On("FindByID", "f1").Return(folder, nil)
+	roomRepoMock.On("FindByMember", "user1").Return([]*domain.Room{
+		{ID: "r1", Type: domain.RoomTypeGroup},
+		{ID: "r2", Type: domain.RoomTypeGroup},
+		{ID: "r3", Type: domain.RoomTypeChannel},
+	}, nil)
+	chatStateRepoMock.On("FindByUser", "user1").Return([]*domain.ChatState{}, nil)
+	chatStateRepoMock.On("FindUnread", "user1").Return([]domain.ChatRef{
+		{Kind: domain.ChatRoom, ID: "r2"},
+		{Kind: domain.ChatRoom, ID: "r3"},
+	}, nil)
+	chatStateRepoMock.On("CountUnreadMentions", "user1").Return(map[string]int{"r2": 2}, nil)
+
+	items, err := chatService.ListRooms("user1", ChatFilter{FolderID: "f1"})
+	assert.Nil(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, "r2", items[0].ID)
+	assert.Equal(t, 2, items[0].UnreadMentions)
+}
+
+// Test 3: Users cannot change the state of chats they do not belong to.
+func TestUpdateChatStateNotMember(t *testing.T) {
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	chatStateRepoMock := new(mocks.ChatStateRepositoryMock)
+	chatService := NewChatService(new(mocks.ConversationRepositoryMock), new(mocks.RoomRepositoryMock), membershipRepoMock, new(mocks.RoomRestrictionRepositoryMock), chatStateRepoMock, new(mocks.ChatFolderRepositoryMock))
+
+	membershipRepoMock.On("GetMembership", "room1", "user1").Return(nil, nil)
+
+	archived := true
+	state, err := chatService.UpdateChatState("user1", domain.ChatRef{Kind: domain.ChatRoom, ID: "room1"}, ChatStateUpdate{Archived: &archived})
+	assert.Nil(t, state)
+	assert.Equal(t, ErrChatNotFound, err)
+	chatStateRepoMock.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+// Test 4: Pinning is limited.
+func TestUpdateChatStatePinLimit(t *testing.T) {
+	convoRepoMock := new(mocks.ConversationRepositoryMock)
+	chatStateRepoMock := new(mocks.ChatStateRepositoryMock)
+	chatService := NewChatService(convoRepoMock, new(mocks.RoomRepositoryMock), new(mocks.RoomMembershipRepositoryMock), new(mocks.RoomRestrictionRepositoryMock), chatStateRepoMock, new(mocks.ChatFolderRepositoryMock))
+
+	chat := domain.ChatRef{Kind: domain.ChatConversation, ID: "c9"}
+	convoRepoMock.On("FindByID", "c9").Return(&domain.Conversation{ID: "c9", Participants: []string{"user1", "user2"}}, nil)
+	chatStateRepoMock.On("Get", "user1", chat).Return(nil, nil)
+	var pinned []*domain.ChatState
+	for i := 0; i < maxPinnedChats; i++ {
+		order := i
+		pinned = append(pinned, &domain.ChatState{UserID: "user1", PinOrder: &order})
+	}
+	chatStateRepoMock.On("FindByUser", "user1").Return(pinned, nil)
+
+	pin := true
+	state, err := chatService.UpdateChatState("user1", chat, ChatStateUpdate{Pinned: &pin})
+	assert.Nil(t, state)
+	assert.EqualError(t, err, "cannot pin more than 5 chats")
+	chatStateRepoMock.AssertNotCalled(t, "Save", mock.Anything)
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"social_media/internal/domain"
+)
+
+// maxContactImport caps how many phone numbers and hashes one import may check.
+const maxContactImport = 500
+
+// ContactService manages users' contact lists and phone-book imports.
+type ContactService interface {
+	// SaveContact adds userID to ownerID's contacts or renames an existing contact.
+	// An empty displayName falls back to the contact's own name.
+	SaveContact(ownerID, userID, displayName string) (*domain.Contact, error)
+	RemoveContact(ownerID, userID string) error
+	GetContacts(ownerID string) ([]*domain.Contact, error)
+	// ImportContacts reports which of the given phone numbers or salted phone hashes
+	// belong to registered users who can be found by phone by ownerID. Users on
+	// either side of a block are left out.
+	ImportContacts(ownerID string, phones, phoneHashes []string) ([]*domain.ImportedContact, error)
+}
+
+type contactService struct {
+	userRepo    domain.UserRepository
+	contactRepo domain.ContactRepository
+	blockRepo   domain.BlockRepository
+}
+
+// NewContactService creates a new instance of ContactService.
+func NewContactService(userRepo domain.UserRepository, contactRepo domain.ContactRepository, blockRepo domain.BlockRepository) ContactService {
+	return &contactService{userRepo: userRepo, contactRepo: contactRepo, blockRepo: blockRepo}
+}
+
+func (s *contactService) SaveContact(ownerID, userID, displayName string) (*domain.Contact, error) {
+	if ownerID == userID {
+		return nil, errors.New("cannot add yourself as a contact")
+	}
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		return nil, errors.New("user not found")
+	}
+	contact := &domain.Contact{
+		OwnerID:   ownerID,
+		UserID:    userID,
+		Name:      user.Name,
+		Username:  user.Username,
+		CreatedAt: time.Now(),
+	}
+	if displayName = strings.TrimSpace(displayName); displayName != "" {
+		contact.DisplayName = &displayName
+	}
+	if err := s.contactRepo.Save(contact); err != nil {
+		return nil, err
+	}
+	return contact, nil
+}
+
+// RemoveContact deletes a contact. Removing a user who is not a contact succeeds.
+func (s *contactService) RemoveContact(ownerID, userID string) error {
+	return s.contactRepo.Delete(ownerID, userID)
+}
+
+func (s *contactService) GetContacts(ownerID string) ([]*domain.Contact, error) {
+	return s.contactRepo.FindByOwner(ownerID)
+}
+
+func (s *contactService) ImportContacts(ownerID string, phones, phoneHashes []string) ([]*domain.ImportedContact, error) {
+	if len(phones)+len(phoneHashes) == 0 {
+		return nil, errors.New("no phone numbers to import")
+	}
+	if len(phones)+len(phoneHashes) > maxContactImport {
+		return nil, fmt.Errorf("cannot import more than %d phone numbers at once", maxContactImport)
+	}
+	for i, hash := range phoneHashes {
+		phoneHashes[i] = strings.ToLower(hash)
+	}
+	users, err := s.userRepo.FindByPhones(phones, phoneHashes)
+	if err != nil {
+		return nil, err
+	}
+
+	sentPhones := make(map[string]bool, len(phones))
+	for _, phone := range phones {
+		sentPhones[phone] = true
+	}
+	matches := make([]*domain.ImportedContact, 0, len(users))
+	for _, user := range users {
+		if user.ID == ownerID {
+			continue
+		}
+		// Users hidden from phone lookups are left out as if unregistered.
+		allowed, err := audienceAllows(s.contactRepo, user.Privacy.FindByPhone, user.ID, ownerID)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			continue
+		}
+		blocked, err := blockedEitherWay(s.blockRepo, ownerID, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			continue
+		}
+		match := &domain.ImportedContact{
+			User: &domain.PublicProfile{ID: user.ID, Name: user.Name, Username: user.Username},
+		}
+		if sentPhones[user.Phone] {
+			match.Phone = user.Phone
+		} else {
+			match.PhoneHash = domain.HashPhone(user.Phone)
+		}
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
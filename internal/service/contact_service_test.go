@@ -0,0 +1,88 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+	"social_media/internal/mocks"
+)
+
+// Test 1: Users cannot add themselves as a contact.
+func TestSaveContactSelf(t *testing.T) {
+	userRepoMock := new(mocks.UserRepositoryMock)
+	contactRepoMock := new(mocks.ContactRepositoryMock)
+	blockRepoMock := new(mocks.BlockRepositoryMock)
+	contactService := NewContactService(userRepoMock, contactRepoMock, blockRepoMock)
+
+	contact, err := contactService.SaveContact("user1", "user1", "Me")
+	assert.Nil(t, contact)
+	assert.EqualError(t, err, "cannot add yourself as a contact")
+	contactRepoMock.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+// Test 2: Import matches phone numbers and hashes, echoing the identifier that was sent.
+func TestImportContactsMatches(t *testing.T) {
+	userRepoMock := new(mocks.UserRepositoryMock)
+	contactRepoMock := new(mocks.ContactRepositoryMock)
+	blockRepoMock := new(mocks.BlockRepositoryMock)
+	contactService := NewContactService(userRepoMock, contactRepoMock, blockRepoMock)
+
+	byPhone := &domain.User{ID: "user2", Name: "Bob", Phone: "5550000002", Privacy: domain.DefaultPrivacySettings()}
+	byHash := &domain.User{ID: "user3", Name: "Carol", Phone: "5550000003", Privacy: domain.DefaultPrivacySettings()}
+	hash := domain.HashPhone("5550000003")
+	userRepoMock.On("FindByPhones", []string{"5550000002"}, []string{hash}).Return([]*domain.User{byPhone, byHash}, nil)
+	blockRepoMock.On("IsBlocked", mock.Anything, mock.Anything).Return(false, nil)
+
+	matches, err := contactService.ImportContacts("user1", []string{"5550000002"}, []string{hash})
+	assert.Nil(t, err)
+	assert.Len(t, matches, 2)
+	assert.Equal(t, "5550000002", matches[0].Phone)
+	assert.Equal(t, "user2", matches[0].User.ID)
+	assert.Equal(t, hash, matches[1].PhoneHash)
+	assert.Empty(t, matches[1].Phone)
+}
+
+// Test 3: Users who only allow contacts to find them are matched only by their contacts.
+func TestImportContactsRespectsFindByPhone(t *testing.T) {
+	userRepoMock := new(mocks.UserRepositoryMock)
+	contactRepoMock := new(mocks.ContactRepositoryMock)
+	blockRepoMock := new(mocks.BlockRepositoryMock)
+	contactService := NewContactService(userRepoMock, contactRepoMock, blockRepoMock)
+
+	privacy := domain.DefaultPrivacySettings()
+	privacy.FindByPhone = domain.AudienceContacts
+	hidden := &domain.User{ID: "user2", Phone: "5550000002", Privacy: privacy}
+	userRepoMock.On("FindByPhones", []string{"5550000002"}, []string(nil)).Return([]*domain.User{hidden}, nil)
+	contactRepoMock.On("IsContact", "user2", "user1").Return(false, nil)
+
+	matches, err := contactService.ImportContacts("user1", []string{"5550000002"}, nil)
+	assert.Nil(t, err)
+	assert.Empty(t, matches)
+	contactRepoMock.AssertExpectations(t)
+}
+
+// Test 4: Users who blocked the importer, or whom the importer blocked, are not matched.
+func TestImportContactsSkipsBlocked(t *testing.T) {
+	userRepoMock := new(mocks.UserRepositoryMock)
+	contactRepoMock := new(mocks.ContactRepositoryMock)
+	blockRepoMock := new(mocks.BlockRepositoryMock)
+	contactService := NewContactService(userRepoMock, contactRepoMock, blockRepoMock)
+
+	blocker := &domain.User{ID: "user2", Phone: "5550000002", Privacy: domain.DefaultPrivacySettings()}
+	blocked := &domain.User{ID: "user3", Phone: "5550000003", Privacy: domain.DefaultPrivacySettings()}
+	friend := &domain.User{ID: "user4", Phone: "5550000004", Privacy: domain.DefaultPrivacySettings()}
+	phones := []string{"5550000002", "5550000003", "5550000004"}
+	userRepoMock.On("FindByPhones", phones, []string(nil)).Return([]*domain.User{blocker, blocked, friend}, nil)
+	blockRepoMock.On("IsBlocked", "user1", "user2").Return(false, nil)
+	blockRepoMock.On("IsBlocked", "user2", "user1").Return(true, nil)
+	blockRepoMock.On("IsBlocked", "user1", "user3").Return(true, nil)
+	blockRepoMock.On("IsBlocked", "user1", "user4").Return(false, nil)
+	blockRepoMock.On("IsBlocked", "user4", "user1").Return(false, nil)
+
+	matches, err := contactService.ImportContacts("user1", phones, nil)
+	assert.Nil(t, err)
+	assert.Len(t, matches, 1)
+	assert.Equal(t, "user4", matches[0].User.ID)
+}
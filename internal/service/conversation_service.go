@@ -2,27 +2,79 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
 	"time"
+	"unicode/utf16"
 
 	"github.com/google/uuid"
 	"social_media/internal/domain"
 )
 
+// maxMessageEntities caps the formatting entities of one message.
+const maxMessageEntities = 100
+
+// ErrMessageNotDelivered is returned when the recipient has blocked the sender.
+// It is deliberately vague so that senders cannot detect the block.
+var ErrMessageNotDelivered = errors.New("message could not be delivered")
+
+// ErrMessagesRestricted is returned when the recipient's privacy settings do not
+// allow the sender to start a conversation with them.
+var ErrMessagesRestricted = forbiddenError("this user does not accept messages from you")
+
+// ErrMessageRequestPending is returned when the sender of a message request tries to
+// send another message before the recipient accepts it.
+var ErrMessageRequestPending = forbiddenError("wait for the recipient to accept your message request")
+
+// Errors returned by group conversation operations.
+var (
+	ErrConversationNotFound    = errors.New("conversation not found")
+	ErrNotGroupConversation    = errors.New("not a group conversation")
+	ErrNotParticipant          = forbiddenError("you are not a participant in this conversation")
+	ErrTooManyParticipants     = fmt.Errorf("group conversations are limited to %d participants", domain.MaxGroupConversationParticipants)
+	ErrTooFewGroupParticipants = errors.New("a group conversation needs at least two other participants")
+)
+
+// ErrMessageRequestNotFound is returned when a conversation is not a pending request to the user.
+var ErrMessageRequestNotFound = errors.New("message request not found")
+
 // ConversationService defines the interface for conversation and messaging operations.
 type ConversationService interface {
 	// SendMessage creates a conversation (if needed) and sends a message.
 	// The recipientIdentifier can be a phone number or a username (with '@').
-	SendMessage(senderID, recipientIdentifier, content string) (*domain.Message, error)
-	GetConversations(userID string) ([]*domain.Conversation, error)
-	GetMessages(convoID string) ([]*domain.Message, error)
-	UpdateMessage(senderID, messageID, content string) (*domain.Message, error)
+	// Entities optionally format content.
+	SendMessage(senderID, recipientIdentifier, content string, entities []domain.MessageEntity) (*domain.Message, error)
+	// GetMessages returns a conversation's history to one of its participants.
+	GetMessages(userID, convoID string) ([]*domain.Message, error)
+	// UpdateMessage replaces the content and formatting of the sender's message.
+	UpdateMessage(senderID, messageID, content string, entities []domain.MessageEntity) (*domain.Message, error)
 	DeleteMessage(senderID, messageID string) error
+	// GetMessageRequests returns first-contact conversations from non-contacts awaiting the user.
+	GetMessageRequests(userID string) ([]*domain.Conversation, error)
+	AcceptMessageRequest(userID, convoID string) (*domain.Conversation, error)
+	// DeleteMessageRequest discards the request along with its message.
+	DeleteMessageRequest(userID, convoID string) error
+	// BlockMessageRequest discards the request and blocks its sender.
+	BlockMessageRequest(userID, convoID string) error
+	// CreateGroupConversation starts a group conversation between the creator and memberIDs.
+	CreateGroupConversation(creatorID, title string, memberIDs []string) (*domain.Conversation, error)
+	// AddParticipant lets a participant add another user to a group conversation.
+	AddParticipant(convoID, requesterID, userID string) error
+	LeaveConversation(convoID, userID string) error
+	// SendGroupMessage posts a message to a group conversation the sender takes part in.
+	SendGroupMessage(senderID, convoID, content string, entities []domain.MessageEntity) (*domain.Message, error)
+	// ForwardMessages posts copies of messages, attributed to their origin, to a conversation.
+	ForwardMessages(senderID, convoID string, forwards []domain.Forward) ([]*domain.Message, error)
 }
 
 type conversationService struct {
 	convoRepo   domain.ConversationRepository
 	messageRepo domain.MessageRepository
 	userRepo    domain.UserRepository // Used to lookup recipient details.
+	blockRepo   domain.BlockRepository
+	contactRepo domain.ContactRepository // evaluates contacts-only privacy settings
 }
 
 // NewConversationService creates a new instance of ConversationService.
@@ -30,25 +82,40 @@ func NewConversationService(
 	convoRepo domain.ConversationRepository,
 	messageRepo domain.MessageRepository,
 	userRepo domain.UserRepository,
+	blockRepo domain.BlockRepository,
+	contactRepo domain.ContactRepository,
 ) ConversationService {
 	return &conversationService{
 		convoRepo:   convoRepo,
 		messageRepo: messageRepo,
 		userRepo:    userRepo,
+		blockRepo:   blockRepo,
+		contactRepo: contactRepo,
 	}
 }
 
 // SendMessage looks up the recipient by phone or username and sends the message.
-func (s *conversationService) SendMessage(senderID, recipientIdentifier, content string) (*domain.Message, error) {
+func (s *conversationService) SendMessage(senderID, recipientIdentifier, content string, entities []domain.MessageEntity) (*domain.Message, error) {
+	entities, err := validateEntities(content, entities)
+	if err != nil {
+		return nil, err
+	}
 	// Lookup the recipient using the identifier.
 	var recipient *domain.User
-	var err error
 	if recipientIdentifier != "" && recipientIdentifier[0] == '@' {
 		// Treat the identifier as a username.
 		recipient, err = s.userRepo.FindByUsername(recipientIdentifier)
 	} else {
-		// Otherwise, assume it's a phone number.
+		// Otherwise, assume it's a phone number. Users hidden from phone lookups
+		// are reported as not found so their numbers cannot be enumerated.
 		recipient, err = s.userRepo.FindByPhone(recipientIdentifier)
+		if err == nil && recipient != nil {
+			var allowed bool
+			allowed, err = audienceAllows(s.contactRepo, recipient.Privacy.FindByPhone, recipient.ID, senderID)
+			if !allowed {
+				recipient = nil
+			}
+		}
 	}
 	if err != nil {
 		return nil, err
@@ -57,6 +124,9 @@ func (s *conversationService) SendMessage(senderID, recipientIdentifier, content
 		return nil, errors.New("recipient not found")
 	}
 	recipientID := recipient.ID
+	if err := s.checkBlocks(senderID, recipientID); err != nil {
+		return nil, err
+	}
 
 	// Order senderID and recipientID lexicographically so that a unique conversation exists per pair.
 	p1, p2 := senderID, recipientID
@@ -70,23 +140,67 @@ func (s *conversationService) SendMessage(senderID, recipientIdentifier, content
 		return nil, err
 	}
 	if convo == nil {
+		// The recipient's message privacy only governs new conversations.
+		allowed, err := audienceAllows(s.contactRepo, recipient.Privacy.Messages, recipientID, senderID)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, ErrMessagesRestricted
+		}
+		// First contact from someone the recipient has not saved lands in their message requests.
+		isContact, err := s.contactRepo.IsContact(recipientID, senderID)
+		if err != nil {
+			return nil, err
+		}
+		status := domain.ConversationActive
+		if !isContact {
+			status = domain.ConversationRequest
+		}
 		convo = &domain.Conversation{
 			ID:           uuid.New().String(),
+			Kind:         domain.ConversationDirect,
 			Participant1: p1,
 			Participant2: p2,
+			Participants: []string{p1, p2},
+			Status:       status,
+			InitiatorID:  &senderID,
 			CreatedAt:    time.Now(),
 		}
 		if err := s.convoRepo.Create(convo); err != nil {
 			return nil, err
 		}
+	} else if err := s.replyToRequest(convo, senderID); err != nil {
+		return nil, err
 	}
 
-	// Create the message.
+	return s.createMessage(convo.ID, senderID, content, nil, entities)
+}
+
+// replyToRequest enforces a pending message request: the sender gets one message
+// until the request is accepted, and a reply from the recipient accepts it.
+func (s *conversationService) replyToRequest(convo *domain.Conversation, senderID string) error {
+	if convo.Status != domain.ConversationRequest {
+		return nil
+	}
+	if convo.InitiatorID != nil && *convo.InitiatorID == senderID {
+		return ErrMessageRequestPending
+	}
+	convo.Status = domain.ConversationActive
+	return s.convoRepo.UpdateStatus(convo)
+}
+
+// createMessage stores a new message in the conversation.
+func (s *conversationService) createMessage(convoID, senderID, content string, forwardedFrom *domain.ForwardOrigin, entities []domain.MessageEntity) (*domain.Message, error) {
 	message := &domain.Message{
 		ID:             uuid.New().String(),
-		ConversationID: convo.ID,
+		ConversationID: convoID,
 		SenderID:       senderID,
 		Content:        content,
+		Kind:           domain.MessageKindText,
+		ForwardedFrom:  forwardedFrom,
+		Entities:       entities,
+		PreviewURL:     domain.PreviewURL(content, entities),
 		CreatedAt:      time.Now(),
 		UpdatedAt:      time.Now(),
 	}
@@ -96,18 +210,117 @@ func (s *conversationService) SendMessage(senderID, recipientIdentifier, content
 	return message, nil
 }
 
-// GetConversations returns all conversations for the specified user.
-func (s *conversationService) GetConversations(userID string) ([]*domain.Conversation, error) {
-	return s.convoRepo.FindByUser(userID)
+// validateEntities checks the sender's formatting entities against content and
+// returns them ordered by offset, outer entities first. Offsets and lengths count
+// UTF-16 code units and may not split a surrogate pair. Entities may nest but not
+// partially overlap, and code and pre spans may not contain other entities.
+func validateEntities(content string, entities []domain.MessageEntity) ([]domain.MessageEntity, error) {
+	if len(entities) == 0 {
+		return nil, nil
+	}
+	if len(entities) > maxMessageEntities {
+		return nil, fmt.Errorf("a message can have at most %d entities", maxMessageEntities)
+	}
+	units := utf16.Encode([]rune(content))
+	// A low surrogate is the second half of a character and cannot start or end an entity.
+	boundary := func(i int) bool {
+		return i == len(units) || units[i] < 0xDC00 || units[i] > 0xDFFF
+	}
+	for _, entity := range entities {
+		if !entity.Type.IsFormatting() {
+			return nil, fmt.Errorf("unsupported entity type %q", entity.Type)
+		}
+		end := entity.Offset + entity.Length
+		if entity.Offset < 0 || entity.Length <= 0 || end > len(units) {
+			return nil, fmt.Errorf("%s entity at offset %d is out of bounds", entity.Type, entity.Offset)
+		}
+		if !boundary(entity.Offset) || !boundary(end) {
+			return nil, fmt.Errorf("%s entity at offset %d splits a character", entity.Type, entity.Offset)
+		}
+		if entity.UserID != nil {
+			return nil, errors.New("user_id is only allowed on mentions")
+		}
+		if entity.Type != domain.EntityTextLink {
+			if entity.URL != "" {
+				return nil, errors.New("url is only allowed on text links")
+			}
+			continue
+		}
+		link, err := url.Parse(entity.URL)
+		if err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" {
+			return nil, fmt.Errorf("text link at offset %d needs an http or https url", entity.Offset)
+		}
+	}
+
+	sorted := sortEntities(entities)
+	for i, outer := range sorted {
+		outerEnd := outer.Offset + outer.Length
+		for _, inner := range sorted[i+1:] {
+			if inner.Offset >= outerEnd {
+				break
+			}
+			if inner.Offset+inner.Length > outerEnd {
+				return nil, fmt.Errorf("%s and %s entities overlap", outer.Type, inner.Type)
+			}
+			if outer.Type.IsMonospace() {
+				return nil, fmt.Errorf("%s entities cannot contain other entities", outer.Type)
+			}
+		}
+	}
+	return sorted, nil
+}
+
+// sortEntities returns a copy of entities ordered by offset, longer entities
+// first. Of entities covering the same text, code and pre come last, as they
+// are always the innermost.
+func sortEntities(entities []domain.MessageEntity) []domain.MessageEntity {
+	sorted := append([]domain.MessageEntity(nil), entities...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Offset != sorted[j].Offset {
+			return sorted[i].Offset < sorted[j].Offset
+		}
+		if sorted[i].Length != sorted[j].Length {
+			return sorted[i].Length > sorted[j].Length
+		}
+		return !sorted[i].Type.IsMonospace() && sorted[j].Type.IsMonospace()
+	})
+	return sorted
+}
+
+// checkBlocks rejects messages between users where either has blocked the other.
+func (s *conversationService) checkBlocks(senderID, recipientID string) error {
+	blocked, err := s.blockRepo.IsBlocked(recipientID, senderID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrMessageNotDelivered
+	}
+	blocked, err = s.blockRepo.IsBlocked(senderID, recipientID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return errors.New("unblock this user to send them a message")
+	}
+	return nil
 }
 
-// GetMessages returns all messages within the specified conversation.
-func (s *conversationService) GetMessages(convoID string) ([]*domain.Message, error) {
+// GetMessages returns all messages within the specified conversation. Users who are
+// not participants, including those who left, get ErrChatNotFound.
+func (s *conversationService) GetMessages(userID, convoID string) ([]*domain.Message, error) {
+	if err := checkReadAccess(s.convoRepo, nil, nil, nil, userID, domain.ChatRef{Kind: domain.ChatConversation, ID: convoID}); err != nil {
+		return nil, err
+	}
 	return s.messageRepo.FindByConversation(convoID)
 }
 
 // UpdateMessage allows the sender to update their message.
-func (s *conversationService) UpdateMessage(senderID, messageID, content string) (*domain.Message, error) {
+func (s *conversationService) UpdateMessage(senderID, messageID, content string, entities []domain.MessageEntity) (*domain.Message, error) {
+	entities, err := validateEntities(content, entities)
+	if err != nil {
+		return nil, err
+	}
 	message, err := s.messageRepo.FindByID(messageID)
 	if err != nil || message == nil {
 		return nil, errors.New("message not found")
@@ -116,7 +329,15 @@ func (s *conversationService) UpdateMessage(senderID, messageID, content string)
 	if message.SenderID != senderID {
 		return nil, errors.New("not authorized to update this message")
 	}
+	if message.Kind.IsService() {
+		return nil, errors.New("service messages cannot be edited")
+	}
 	message.Content = content
+	message.Entities = entities
+	// A changed link gets a new preview.
+	if link := domain.PreviewURL(content, entities); link != previewedURL(message) {
+		message.PreviewURL, message.LinkPreview = link, nil
+	}
 	message.UpdatedAt = time.Now()
 	if err := s.messageRepo.Update(message); err != nil {
 		return nil, err
@@ -124,6 +345,15 @@ func (s *conversationService) UpdateMessage(senderID, messageID, content string)
 	return message, nil
 }
 
+// previewedURL returns the link the message's preview is for, whether or not it
+// has been generated yet.
+func previewedURL(message *domain.Message) string {
+	if message.PreviewURL == "" && message.LinkPreview != nil {
+		return message.LinkPreview.URL
+	}
+	return message.PreviewURL
+}
+
 // DeleteMessage allows the sender to delete their message.
 func (s *conversationService) DeleteMessage(senderID, messageID string) error {
 	message, err := s.messageRepo.FindByID(messageID)
@@ -136,3 +366,224 @@ func (s *conversationService) DeleteMessage(senderID, messageID string) error {
 	}
 	return s.messageRepo.Delete(message)
 }
+
+// GetMessageRequests returns the pending message requests sent to the user.
+func (s *conversationService) GetMessageRequests(userID string) ([]*domain.Conversation, error) {
+	return s.convoRepo.FindRequests(userID)
+}
+
+// AcceptMessageRequest moves the request into the user's conversations.
+func (s *conversationService) AcceptMessageRequest(userID, convoID string) (*domain.Conversation, error) {
+	convo, err := s.incomingRequest(userID, convoID)
+	if err != nil {
+		return nil, err
+	}
+	convo.Status = domain.ConversationActive
+	if err := s.convoRepo.UpdateStatus(convo); err != nil {
+		return nil, err
+	}
+	return convo, nil
+}
+
+// DeleteMessageRequest deletes the request conversation.
+func (s *conversationService) DeleteMessageRequest(userID, convoID string) error {
+	convo, err := s.incomingRequest(userID, convoID)
+	if err != nil {
+		return err
+	}
+	return s.convoRepo.Delete(convo.ID)
+}
+
+// BlockMessageRequest blocks the request's sender and deletes the request.
+func (s *conversationService) BlockMessageRequest(userID, convoID string) error {
+	convo, err := s.incomingRequest(userID, convoID)
+	if err != nil {
+		return err
+	}
+	err = s.blockRepo.Block(&domain.UserBlock{
+		BlockerID: userID,
+		BlockedID: *convo.InitiatorID,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	return s.convoRepo.Delete(convo.ID)
+}
+
+// incomingRequest loads a pending message request addressed to userID.
+func (s *conversationService) incomingRequest(userID, convoID string) (*domain.Conversation, error) {
+	convo, err := s.convoRepo.FindByID(convoID)
+	if err != nil {
+		return nil, err
+	}
+	if convo == nil || convo.Status != domain.ConversationRequest || !convo.HasParticipant(userID) ||
+		convo.InitiatorID == nil || *convo.InitiatorID == userID {
+		return nil, ErrMessageRequestNotFound
+	}
+	return convo, nil
+}
+
+// CreateGroupConversation creates the conversation with the creator and every member.
+// Members whose privacy settings exclude the creator, or who blocked them, cannot be added.
+func (s *conversationService) CreateGroupConversation(creatorID, title string, memberIDs []string) (*domain.Conversation, error) {
+	participants := []string{creatorID}
+	seen := map[string]bool{creatorID: true}
+	for _, memberID := range memberIDs {
+		if seen[memberID] {
+			continue
+		}
+		seen[memberID] = true
+		if err := s.checkCanAdd(creatorID, memberID); err != nil {
+			return nil, err
+		}
+		participants = append(participants, memberID)
+	}
+	if len(participants) < 3 {
+		return nil, ErrTooFewGroupParticipants
+	}
+	if len(participants) > domain.MaxGroupConversationParticipants {
+		return nil, ErrTooManyParticipants
+	}
+
+	convo := &domain.Conversation{
+		ID:           uuid.New().String(),
+		Kind:         domain.ConversationGroup,
+		Participants: participants,
+		Status:       domain.ConversationActive,
+		InitiatorID:  &creatorID,
+		CreatedAt:    time.Now(),
+	}
+	if title = strings.TrimSpace(title); title != "" {
+		convo.Title = &title
+	}
+	if err := s.convoRepo.Create(convo); err != nil {
+		return nil, err
+	}
+	return convo, nil
+}
+
+// AddParticipant adds userID to the group conversation.
+func (s *conversationService) AddParticipant(convoID, requesterID, userID string) error {
+	convo, err := s.groupConversation(convoID, requesterID)
+	if err != nil {
+		return err
+	}
+	if convo.HasParticipant(userID) {
+		return errors.New("user is already a participant")
+	}
+	if len(convo.Participants) >= domain.MaxGroupConversationParticipants {
+		return ErrTooManyParticipants
+	}
+	if err := s.checkCanAdd(requesterID, userID); err != nil {
+		return err
+	}
+	return s.convoRepo.AddParticipant(convoID, userID)
+}
+
+// LeaveConversation removes the user from a group conversation.
+// The conversation is deleted when its last participant leaves.
+func (s *conversationService) LeaveConversation(convoID, userID string) error {
+	convo, err := s.groupConversation(convoID, userID)
+	if err != nil {
+		return err
+	}
+	if len(convo.Participants) == 1 {
+		return s.convoRepo.Delete(convoID)
+	}
+	return s.convoRepo.RemoveParticipant(convoID, userID)
+}
+
+// SendGroupMessage stores a message in the group conversation.
+func (s *conversationService) SendGroupMessage(senderID, convoID, content string, entities []domain.MessageEntity) (*domain.Message, error) {
+	entities, err := validateEntities(content, entities)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.groupConversation(convoID, senderID); err != nil {
+		return nil, err
+	}
+	return s.createMessage(convoID, senderID, content, nil, entities)
+}
+
+// ForwardMessages posts the forwards to a direct or group conversation under the
+// same rules as sending a message there.
+func (s *conversationService) ForwardMessages(senderID, convoID string, forwards []domain.Forward) ([]*domain.Message, error) {
+	convo, err := s.convoRepo.FindByID(convoID)
+	if err != nil {
+		return nil, err
+	}
+	if convo == nil {
+		return nil, ErrConversationNotFound
+	}
+	if !convo.HasParticipant(senderID) {
+		return nil, ErrNotParticipant
+	}
+	if convo.Kind == domain.ConversationDirect {
+		recipientID := convo.Participant1
+		if recipientID == senderID {
+			recipientID = convo.Participant2
+		}
+		if err := s.checkBlocks(senderID, recipientID); err != nil {
+			return nil, err
+		}
+		if err := s.replyToRequest(convo, senderID); err != nil {
+			return nil, err
+		}
+	}
+	messages := make([]*domain.Message, 0, len(forwards))
+	for _, forward := range forwards {
+		origin := forward.Origin
+		message, err := s.createMessage(convo.ID, senderID, forward.Content, &origin, forward.Entities)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// groupConversation loads a group conversation that userID takes part in.
+func (s *conversationService) groupConversation(convoID, userID string) (*domain.Conversation, error) {
+	convo, err := s.convoRepo.FindByID(convoID)
+	if err != nil {
+		return nil, err
+	}
+	if convo == nil {
+		return nil, ErrConversationNotFound
+	}
+	if convo.Kind != domain.ConversationGroup {
+		return nil, ErrNotGroupConversation
+	}
+	if !convo.HasParticipant(userID) {
+		return nil, ErrNotParticipant
+	}
+	return convo, nil
+}
+
+// checkCanAdd rejects adding userID to a group conversation when they blocked the
+// requester or their group invite privacy excludes them. Both look the same to the requester.
+func (s *conversationService) checkCanAdd(requesterID, userID string) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+	blocked, err := s.blockRepo.IsBlocked(userID, requesterID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrGroupInvitesRestricted
+	}
+	allowed, err := audienceAllows(s.contactRepo, user.Privacy.GroupInvites, userID, requesterID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrGroupInvitesRestricted
+	}
+	return nil
+}
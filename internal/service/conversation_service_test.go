@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"social_media/internal/domain"
 	"social_media/internal/mocks"
+	"social_media/pkg/markdown"
 )
 
 // Test 1: Send message with recipient not found.
@@ -15,12 +16,13 @@ func TestSendMessageRecipientNotFound(t *testing.T) {
 	userRepoMock := new(mocks.UserRepositoryMock)
 	convoRepoMock := new(mocks.ConversationRepositoryMock)
 	messageRepoMock := new(mocks.MessageRepositoryMock)
-	convoService := NewConversationService(convoRepoMock, messageRepoMock, userRepoMock)
+	blockRepoMock := new(mocks.BlockRepositoryMock)
+	convoService := NewConversationService(convoRepoMock, messageRepoMock, userRepoMock, blockRepoMock, new(mocks.ContactRepositoryMock))
 
 	// Simulate recipient not found (using phone)
 	userRepoMock.On("FindByPhone", "9998887777").Return(nil, nil)
 
-	msg, err := convoService.SendMessage("sender1", "9998887777", "Hello!")
+	msg, err := convoService.SendMessage("sender1", "9998887777", "Hello!", nil)
 	assert.Nil(t, msg)
 	assert.EqualError(t, err, "recipient not found")
 	userRepoMock.AssertExpectations(t)
@@ -31,11 +33,15 @@ func TestSendMessageNewConversation(t *testing.T) {
 	userRepoMock := new(mocks.UserRepositoryMock)
 	convoRepoMock := new(mocks.ConversationRepositoryMock)
 	messageRepoMock := new(mocks.MessageRepositoryMock)
-	convoService := NewConversationService(convoRepoMock, messageRepoMock, userRepoMock)
+	blockRepoMock := new(mocks.BlockRepositoryMock)
+	contactRepoMock := new(mocks.ContactRepositoryMock)
+	convoService := NewConversationService(convoRepoMock, messageRepoMock, userRepoMock, blockRepoMock, contactRepoMock)
 
 	// Recipient found by phone.
-	recipient := &domain.User{ID: "recipient1"}
+	recipient := &domain.User{ID: "recipient1", Privacy: domain.DefaultPrivacySettings()}
 	userRepoMock.On("FindByPhone", "1231231234").Return(recipient, nil)
+	blockRepoMock.On("IsBlocked", "recipient1", "sender1").Return(false, nil)
+	blockRepoMock.On("IsBlocked", "sender1", "recipient1").Return(false, nil)
 
 	// Order sender and recipient lexicographically.
 	p1, p2 := "sender1", "recipient1"
@@ -44,10 +50,13 @@ func TestSendMessageNewConversation(t *testing.T) {
 	}
 	// No conversation exists.
 	convoRepoMock.On("FindByParticipants", p1, p2).Return(nil, nil)
-	convoRepoMock.On("Create", mock.AnythingOfType("*domain.Conversation")).Return(nil)
+	contactRepoMock.On("IsContact", "recipient1", "sender1").Return(true, nil)
+	convoRepoMock.On("Create", mock.MatchedBy(func(c *domain.Conversation) bool {
+		return c.Status == domain.ConversationActive && *c.InitiatorID == "sender1"
+	})).Return(nil)
 	messageRepoMock.On("Create", mock.AnythingOfType("*domain.Message")).Return(nil)
 
-	msg, err := convoService.SendMessage("sender1", "1231231234", "Hi there!")
+	msg, err := convoService.SendMessage("sender1", "1231231234", "Hi there!", nil)
 	assert.NotNil(t, msg)
 	assert.Nil(t, err)
 	userRepoMock.AssertExpectations(t)
@@ -60,10 +69,13 @@ func TestSendMessageExistingConversation(t *testing.T) {
 	userRepoMock := new(mocks.UserRepositoryMock)
 	convoRepoMock := new(mocks.ConversationRepositoryMock)
 	messageRepoMock := new(mocks.MessageRepositoryMock)
-	convoService := NewConversationService(convoRepoMock, messageRepoMock, userRepoMock)
+	blockRepoMock := new(mocks.BlockRepositoryMock)
+	convoService := NewConversationService(convoRepoMock, messageRepoMock, userRepoMock, blockRepoMock, new(mocks.ContactRepositoryMock))
 
-	recipient := &domain.User{ID: "recipient1"}
+	recipient := &domain.User{ID: "recipient1", Privacy: domain.DefaultPrivacySettings()}
 	userRepoMock.On("FindByPhone", "1231231234").Return(recipient, nil)
+	blockRepoMock.On("IsBlocked", "recipient1", "sender1").Return(false, nil)
+	blockRepoMock.On("IsBlocked", "sender1", "recipient1").Return(false, nil)
 
 	p1, p2 := "sender1", "recipient1"
 	if p1 > p2 {
@@ -73,7 +85,7 @@ func TestSendMessageExistingConversation(t *testing.T) {
 	convoRepoMock.On("FindByParticipants", p1, p2).Return(existingConvo, nil)
 	messageRepoMock.On("Create", mock.AnythingOfType("*domain.Message")).Return(nil)
 
-	msg, err := convoService.SendMessage("sender1", "1231231234", "Hi again!")
+	msg, err := convoService.SendMessage("sender1", "1231231234", "Hi again!", nil)
 	assert.NotNil(t, msg)
 	assert.Nil(t, err)
 	userRepoMock.AssertExpectations(t)
@@ -86,12 +98,13 @@ func TestUpdateMessageUnauthorized(t *testing.T) {
 	userRepoMock := new(mocks.UserRepositoryMock)
 	convoRepoMock := new(mocks.ConversationRepositoryMock)
 	messageRepoMock := new(mocks.MessageRepositoryMock)
-	convoService := NewConversationService(convoRepoMock, messageRepoMock, userRepoMock)
+	blockRepoMock := new(mocks.BlockRepositoryMock)
+	convoService := NewConversationService(convoRepoMock, messageRepoMock, userRepoMock, blockRepoMock, new(mocks.ContactRepositoryMock))
 
 	existingMessage := &domain.Message{ID: "msg1", SenderID: "sender1", Content: "Original", CreatedAt: time.Now(), UpdatedAt: time.Now()}
 	messageRepoMock.On("FindByID", "msg1").Return(existingMessage, nil)
 
-	updatedMsg, err := convoService.UpdateMessage("anotherSender", "msg1", "Updated content")
+	updatedMsg, err := convoService.UpdateMessage("anotherSender", "msg1", "Updated content", nil)
 	assert.Nil(t, updatedMsg)
 	assert.EqualError(t, err, "not authorized to update this message")
 	messageRepoMock.AssertExpectations(t)
@@ -102,7 +115,8 @@ func TestUpdateMessageSuccess(t *testing.T) {
 	userRepoMock := new(mocks.UserRepositoryMock)
 	convoRepoMock := new(mocks.ConversationRepositoryMock)
 	messageRepoMock := new(mocks.MessageRepositoryMock)
-	convoService := NewConversationService(convoRepoMock, messageRepoMock, userRepoMock)
+	blockRepoMock := new(mocks.BlockRepositoryMock)
+	convoService := NewConversationService(convoRepoMock, messageRepoMock, userRepoMock, blockRepoMock, new(mocks.ContactRepositoryMock))
 
 	existingMessage := &domain.Message{ID: "msg1", SenderID: "sender1", Content: "Original", CreatedAt: time.Now(), UpdatedAt: time.Now()}
 	messageRepoMock.On("FindByID", "msg1").Return(existingMessage, nil)
@@ -111,7 +125,7 @@ func TestUpdateMessageSuccess(t *testing.T) {
 		m.UpdatedAt = time.Now()
 	})
 
-	updatedMsg, err := convoService.UpdateMessage("sender1", "msg1", "Updated content")
+	updatedMsg, err := convoService.UpdateMessage("sender1", "msg1", "Updated content", nil)
 	assert.NotNil(t, updatedMsg)
 	assert.Nil(t, err)
 	assert.Equal(t, "Updated content", updatedMsg.Content)
@@ -123,7 +137,8 @@ func TestDeleteMessageUnauthorized(t *testing.T) {
 	userRepoMock := new(mocks.UserRepositoryMock)
 	convoRepoMock := new(mocks.ConversationRepositoryMock)
 	messageRepoMock := new(mocks.MessageRepositoryMock)
-	convoService := NewConversationService(convoRepoMock, messageRepoMock, userRepoMock)
+	blockRepoMock := new(mocks.BlockRepositoryMock)
+	convoService := NewConversationService(convoRepoMock, messageRepoMock, userRepoMock, blockRepoMock, new(mocks.ContactRepositoryMock))
 
 	existingMessage := &domain.Message{ID: "msg1", SenderID: "sender1", Content: "To be deleted", CreatedAt: time.Now(), UpdatedAt: time.Now()}
 	messageRepoMock.On("FindByID", "msg1").Return(existingMessage, nil)
@@ -138,7 +153,8 @@ func TestDeleteMessageSuccess(t *testing.T) {
 	userRepoMock := new(mocks.UserRepositoryMock)
 	convoRepoMock := new(mocks.ConversationRepositoryMock)
 	messageRepoMock := new(mocks.MessageRepositoryMock)
-	convoService := NewConversationService(convoRepoMock, messageRepoMock, userRepoMock)
+	blockRepoMock := new(mocks.BlockRepositoryMock)
+	convoService := NewConversationService(convoRepoMock, messageRepoMock, userRepoMock, blockRepoMock, new(mocks.ContactRepositoryMock))
 
 	existingMessage := &domain.Message{ID: "msg1", SenderID: "sender1", Content: "To be deleted", CreatedAt: time.Now(), UpdatedAt: time.Now()}
 	messageRepoMock.On("FindByID", "msg1").Return(existingMessage, nil)
@@ -148,3 +164,327 @@ func TestDeleteMessageSuccess(t *testing.T) {
 	assert.Nil(t, err)
 	messageRepoMock.AssertExpectations(t)
 }
+
+// Test 8: A blocked sender gets a generic delivery error.
+func TestSendMessageBlockedByRecipient(t *testing.T) {
+	userRepoMock := new(mocks.UserRepositoryMock)
+	convoRepoMock := new(mocks.ConversationRepositoryMock)
+	messageRepoMock := new(mocks.MessageRepositoryMock)
+	blockRepoMock := new(mocks.BlockRepositoryMock)
+	convoService := NewConversationService(convoRepoMock, messageRepoMock, userRepoMock, blockRepoMock, new(mocks.ContactRepositoryMock))
+
+	recipient := &domain.User{ID: "recipient1", Privacy: domain.DefaultPrivacySettings()}
+	userRepoMock.On("FindByUsername", "@recipient").Return(recipient, nil)
+	blockRepoMock.On("IsBlocked", "recipient1", "sender1").Return(true, nil)
+
+	msg, err := convoService.SendMessage("sender1", "@recipient", "Hello!", nil)
+	assert.Nil(t, msg)
+	assert.Equal(t, ErrMessageNotDelivered, err)
+	messageRepoMock.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// Test 9: Users hidden from phone lookups are reported as not found.
+func TestSendMessageFindByPhoneRestricted(t *testing.T) {
+	userRepoMock := new(mocks.UserRepositoryMock)
+	convoRepoMock := new(mocks.ConversationRepositoryMock)
+	messageRepoMock := new(mocks.MessageRepositoryMock)
+	blockRepoMock := new(mocks.BlockRepositoryMock)
+	convoService := NewConversationService(convoRepoMock, messageRepoMock, userRepoMock, blockRepoMock, new(mocks.ContactRepositoryMock))
+
+	privacy := domain.DefaultPrivacySettings()
+	privacy.FindByPhone = domain.AudienceNobody
+	userRepoMock.On("FindByPhone", "1231231234").Return(&domain.User{ID: "recipient1", Privacy: privacy}, nil)
+
+	msg, err := convoService.SendMessage("sender1", "1231231234", "Hello!", nil)
+	assert.Nil(t, msg)
+	assert.EqualError(t, err, "recipient not found")
+	messageRepoMock.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// Test 10: Message privacy stops new conversations.
+func TestSendMessageNewConversationRestricted(t *testing.T) {
+	userRepoMock := new(mocks.UserRepositoryMock)
+	convoRepoMock := new(mocks.ConversationRepositoryMock)
+	messageRepoMock := new(mocks.MessageRepositoryMock)
+	blockRepoMock := new(mocks.BlockRepositoryMock)
+	convoService := NewConversationService(convoRepoMock, messageRepoMock, userRepoMock, blockRepoMock, new(mocks.ContactRepositoryMock))
+
+	privacy := domain.DefaultPrivacySettings()
+	privacy.Messages = domain.AudienceNobody
+	userRepoMock.On("FindByUsername", "@recipient").Return(&domain.User{ID: "recipient1", Privacy: privacy}, nil)
+	blockRepoMock.On("IsBlocked", "recipient1", "sender1").Return(false, nil)
+	blockRepoMock.On("IsBlocked", "sender1", "recipient1").Return(false, nil)
+	convoRepoMock.On("FindByParticipants", "recipient1", "sender1").Return(nil, nil)
+
+	msg, err := convoService.SendMessage("sender1", "@recipient", "Hello!", nil)
+	assert.Nil(t, msg)
+	assert.Equal(t, ErrMessagesRestricted, err)
+	convoRepoMock.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// Test 11: A first message from a non-contact opens a message request.
+func TestSendMessageFromStrangerCreatesRequest(t *testing.T) {
+	userRepoMock := new(mocks.UserRepositoryMock)
+	convoRepoMock := new(mocks.ConversationRepositoryMock)
+	messageRepoMock := new(mocks.MessageRepositoryMock)
+	blockRepoMock := new(mocks.BlockRepositoryMock)
+	contactRepoMock := new(mocks.ContactRepositoryMock)
+	convoService := NewConversationService(convoRepoMock, messageRepoMock, userRepoMock, blockRepoMock, contactRepoMock)
+
+	userRepoMock.On("FindByUsername", "@recipient").Return(&domain.User{ID: "recipient1", Privacy: domain.DefaultPrivacySettings()}, nil)
+	blockRepoMock.On("IsBlocked", "recipient1", "sender1").Return(false, nil)
+	blockRepoMock.On("IsBlocked", "sender1", "recipient1").Return(false, nil)
+	convoRepoMock.On("FindByParticipants", "recipient1", "sender1").Return(nil, nil)
+	contactRepoMock.On("IsContact", "recipient1", "sender1").Return(false, nil)
+	convoRepoMock.On("Create", mock.MatchedBy(func(c *domain.Conversation) bool {
+		return c.Status == domain.ConversationRequest && *c.InitiatorID == "sender1"
+	})).Return(nil)
+	messageRepoMock.On("Create", mock.AnythingOfType("*domain.Message")).Return(nil)
+
+	msg, err := convoService.SendMessage("sender1", "@recipient", "Hi, we met at the conference", nil)
+	assert.Nil(t, err)
+	assert.NotNil(t, msg)
+	convoRepoMock.AssertExpectations(t)
+}
+
+// Test 12: The sender of a pending request cannot send a second message.
+func TestSendMessagePendingRequest(t *testing.T) {
+	userRepoMock := new(mocks.UserRepositoryMock)
+	convoRepoMock := new(mocks.ConversationRepositoryMock)
+	messageRepoMock := new(mocks.MessageRepositoryMock)
+	blockRepoMock := new(mocks.BlockRepositoryMock)
+	convoService := NewConversationService(convoRepoMock, messageRepoMock, userRepoMock, blockRepoMock, new(mocks.ContactRepositoryMock))
+
+	userRepoMock.On("FindByUsername", "@recipient").Return(&domain.User{ID: "recipient1", Privacy: domain.DefaultPrivacySettings()}, nil)
+	blockRepoMock.On("IsBlocked", "recipient1", "sender1").Return(false, nil)
+	blockRepoMock.On("IsBlocked", "sender1", "recipient1").Return(false, nil)
+	request := &domain.Conversation{ID: "convo1", Participant1: "recipient1", Participant2: "sender1", Participants: []string{"recipient1", "sender1"}, Status: domain.ConversationRequest, InitiatorID: ptr("sender1")}
+	convoRepoMock.On("FindByParticipants", "recipient1", "sender1").Return(request, nil)
+
+	msg, err := convoService.SendMessage("sender1", "@recipient", "Hello? Are you there?", nil)
+	assert.Nil(t, msg)
+	assert.Equal(t, ErrMessageRequestPending, err)
+	messageRepoMock.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// Test 13: Only the recipient can act on a message request.
+func TestAcceptMessageRequestBySender(t *testing.T) {
+	convoRepoMock := new(mocks.ConversationRepositoryMock)
+	convoService := NewConversationService(convoRepoMock, new(mocks.MessageRepositoryMock), new(mocks.UserRepositoryMock), new(mocks.BlockRepositoryMock), new(mocks.ContactRepositoryMock))
+
+	request := &domain.Conversation{ID: "convo1", Participant1: "recipient1", Participant2: "sender1", Participants: []string{"recipient1", "sender1"}, Status: domain.ConversationRequest, InitiatorID: ptr("sender1")}
+	convoRepoMock.On("FindByID", "convo1").Return(request, nil)
+
+	convo, err := convoService.AcceptMessageRequest("sender1", "convo1")
+	assert.Nil(t, convo)
+	assert.Equal(t, ErrMessageRequestNotFound, err)
+	convoRepoMock.AssertNotCalled(t, "UpdateStatus", mock.Anything)
+}
+
+// Test 14: Blocking from a message request blocks the sender and deletes the request.
+func TestBlockMessageRequest(t *testing.T) {
+	convoRepoMock := new(mocks.ConversationRepositoryMock)
+	blockRepoMock := new(mocks.BlockRepositoryMock)
+	convoService := NewConversationService(convoRepoMock, new(mocks.MessageRepositoryMock), new(mocks.UserRepositoryMock), blockRepoMock, new(mocks.ContactRepositoryMock))
+
+	request := &domain.Conversation{ID: "convo1", Participant1: "recipient1", Participant2: "sender1", Participants: []string{"recipient1", "sender1"}, Status: domain.ConversationRequest, InitiatorID: ptr("sender1")}
+	convoRepoMock.On("FindByID", "convo1").Return(request, nil)
+	blockRepoMock.On("Block", mock.MatchedBy(func(b *domain.UserBlock) bool {
+		return b.BlockerID == "recipient1" && b.BlockedID == "sender1"
+	})).Return(nil)
+	convoRepoMock.On("Delete", "convo1").Return(nil)
+
+	err := convoService.BlockMessageRequest("recipient1", "convo1")
+	assert.Nil(t, err)
+	blockRepoMock.AssertExpectations(t)
+	convoRepoMock.AssertExpectations(t)
+}
+
+// Test 15: Group conversations need at least three participants.
+func TestCreateGroupConversationTooFew(t *testing.T) {
+	userRepoMock := new(mocks.UserRepositoryMock)
+	convoRepoMock := new(mocks.ConversationRepositoryMock)
+	blockRepoMock := new(mocks.BlockRepositoryMock)
+	convoService := NewConversationService(convoRepoMock, new(mocks.MessageRepositoryMock), userRepoMock, blockRepoMock, new(mocks.ContactRepositoryMock))
+
+	userRepoMock.On("FindByID", "user2").Return(&domain.User{ID: "user2", Privacy: domain.DefaultPrivacySettings()}, nil)
+	blockRepoMock.On("IsBlocked", "user2", "user1").Return(false, nil)
+
+	convo, err := convoService.CreateGroupConversation("user1", "Trip", []string{"user2", "user1", "user2"})
+	assert.Nil(t, convo)
+	assert.Equal(t, ErrTooFewGroupParticipants, err)
+	convoRepoMock.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// Test 16: Create a group conversation.
+func TestCreateGroupConversationSuccess(t *testing.T) {
+	userRepoMock := new(mocks.UserRepositoryMock)
+	convoRepoMock := new(mocks.ConversationRepositoryMock)
+	blockRepoMock := new(mocks.BlockRepositoryMock)
+	convoService := NewConversationService(convoRepoMock, new(mocks.MessageRepositoryMock), userRepoMock, blockRepoMock, new(mocks.ContactRepositoryMock))
+
+	for _, id := range []string{"user2", "user3"} {
+		userRepoMock.On("FindByID", id).Return(&domain.User{ID: id, Privacy: domain.DefaultPrivacySettings()}, nil)
+		blockRepoMock.On("IsBlocked", id, "user1").Return(false, nil)
+	}
+	convoRepoMock.On("Create", mock.MatchedBy(func(c *domain.Conversation) bool {
+		return c.Kind == domain.ConversationGroup && c.Participant1 == "" && len(c.Participants) == 3
+	})).Return(nil)
+
+	convo, err := convoService.CreateGroupConversation("user1", " Trip ", []string{"user2", "user3"})
+	assert.Nil(t, err)
+	assert.Equal(t, "Trip", *convo.Title)
+	assert.Equal(t, []string{"user1", "user2", "user3"}, convo.Participants)
+	convoRepoMock.AssertExpectations(t)
+}
+
+// Test 17: Only participants can post to a group conversation.
+func TestSendGroupMessageNotParticipant(t *testing.T) {
+	convoRepoMock := new(mocks.ConversationRepositoryMock)
+	messageRepoMock := new(mocks.MessageRepositoryMock)
+	convoService := NewConversationService(convoRepoMock, messageRepoMock, new(mocks.UserRepositoryMock), new(mocks.BlockRepositoryMock), new(mocks.ContactRepositoryMock))
+
+	group := &domain.Conversation{ID: "convo1", Kind: domain.ConversationGroup, Participants: []string{"user1", "user2", "user3"}}
+	convoRepoMock.On("FindByID", "convo1").Return(group, nil)
+
+	msg, err := convoService.SendGroupMessage("user4", "convo1", "Hi all", nil)
+	assert.Nil(t, msg)
+	assert.ErrorIs(t, err, ErrForbidden)
+	messageRepoMock.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// Test 18: The last participant to leave deletes the group conversation.
+func TestLeaveConversationLastParticipant(t *testing.T) {
+	convoRepoMock := new(mocks.ConversationRepositoryMock)
+	convoService := NewConversationService(convoRepoMock, new(mocks.MessageRepositoryMock), new(mocks.UserRepositoryMock), new(mocks.BlockRepositoryMock), new(mocks.ContactRepositoryMock))
+
+	group := &domain.Conversation{ID: "convo1", Kind: domain.ConversationGroup, Participants: []string{"user1"}}
+	convoRepoMock.On("FindByID", "convo1").Return(group, nil)
+	convoRepoMock.On("Delete", "convo1").Return(nil)
+
+	err := convoService.LeaveConversation("convo1", "user1")
+	assert.Nil(t, err)
+	convoRepoMock.AssertExpectations(t)
+	convoRepoMock.AssertNotCalled(t, "RemoveParticipant", mock.Anything, mock.Anything)
+}
+
+// Test 19: Formatting entities are stored with a group message, ordered by offset.
+func TestSendGroupMessageWithEntities(t *testing.T) {
+	convoRepoMock := new(mocks.ConversationRepositoryMock)
+	messageRepoMock := new(mocks.MessageRepositoryMock)
+	convoService := NewConversationService(convoRepoMock, messageRepoMock, new(mocks.UserRepositoryMock), new(mocks.BlockRepositoryMock), new(mocks.ContactRepositoryMock))
+
+	group := &domain.Conversation{ID: "convo1", Kind: domain.ConversationGroup, Participants: []string{"user1", "user2", "user3"}}
+	convoRepoMock.On("FindByID", "convo1").Return(group, nil)
+	messageRepoMock.On("Create", mock.AnythingOfType("*domain.Message")).Return(nil)
+
+	// "🎉 Party at the docs" has a surrogate pair before "Party".
+	entities := []domain.MessageEntity{
+		{Type: domain.EntityTextLink, Offset: 16, Length: 4, URL: "https://example.com/docs"},
+		{Type: domain.EntityItalic, Offset: 3, Length: 5},
+		{Type: domain.EntityBold, Offset: 3, Length: 17},
+	}
+	msg, err := convoService.SendGroupMessage("user1", "convo1", "🎉 Party at the docs", entities)
+	assert.Nil(t, err)
+	assert.Equal(t, []domain.MessageEntity{entities[2], entities[1], entities[0]}, msg.Entities)
+}
+
+// Test 20: Invalid formatting entities are rejected before anything is stored.
+func TestSendMessageInvalidEntities(t *testing.T) {
+	messageRepoMock := new(mocks.MessageRepositoryMock)
+	convoService := NewConversationService(new(mocks.ConversationRepositoryMock), messageRepoMock, new(mocks.UserRepositoryMock), new(mocks.BlockRepositoryMock), new(mocks.ContactRepositoryMock))
+
+	invalid := [][]domain.MessageEntity{
+		{{Type: domain.EntityBold, Offset: 4, Length: 3}},                                                    // past the end
+		{{Type: domain.EntityBold, Offset: 1, Length: 2}},                                                    // splits the emoji
+		{{Type: domain.EntityMention, Offset: 0, Length: 2}},                                                 // mentions are resolved by the server
+		{{Type: domain.EntityTextLink, Offset: 0, Length: 2, URL: "javascript:alert(1)"}},                    // not a web link
+		{{Type: domain.EntityBold, Offset: 0, Length: 3}, {Type: domain.EntityItalic, Offset: 2, Length: 2}}, // partial overlap
+		{{Type: domain.EntityCode, Offset: 0, Length: 4}, {Type: domain.EntityBold, Offset: 2, Length: 1}},   // inside code
+	}
+	for _, entities := range invalid {
+		msg, err := convoService.SendMessage("sender1", "@recipient", "😀 hi", entities)
+		assert.NotNil(t, err)
+		assert.Nil(t, msg)
+	}
+	messageRepoMock.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// Test 21: A sent message records its first link for the preview job; editing the link queues a new preview.
+func TestMessageLinkPreviewURL(t *testing.T) {
+	convoRepoMock := new(mocks.ConversationRepositoryMock)
+	messageRepoMock := new(mocks.MessageRepositoryMock)
+	convoService := NewConversationService(convoRepoMock, messageRepoMock, new(mocks.UserRepositoryMock), new(mocks.BlockRepositoryMock), new(mocks.ContactRepositoryMock))
+
+	group := &domain.Conversation{ID: "convo1", Kind: domain.ConversationGroup, Participants: []string{"user1", "user2", "user3"}}
+	convoRepoMock.On("FindByID", "convo1").Return(group, nil)
+	messageRepoMock.On("Create", mock.AnythingOfType("*domain.Message")).Return(nil)
+
+	msg, err := convoService.SendGroupMessage("user1", "convo1", "See (https://example.com/a?b=1), and http://other.example.", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://example.com/a?b=1", msg.PreviewURL)
+
+	msg, err = convoService.SendGroupMessage("user1", "convo1", "Docs", []domain.MessageEntity{
+		{Type: domain.EntityTextLink, Offset: 0, Length: 4, URL: "https://example.com/docs"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "https://example.com/docs", msg.PreviewURL)
+
+	existing := &domain.Message{ID: "msg1", SenderID: "user1", Kind: domain.MessageKindText,
+		LinkPreview: &domain.LinkPreview{URL: "https://example.com/a", Title: "A"}}
+	messageRepoMock.On("FindByID", "msg1").Return(existing, nil)
+	messageRepoMock.On("Update", existing).Return(nil)
+
+	msg, err = convoService.UpdateMessage("user1", "msg1", "Typo fixed: https://example.com/a", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "A", msg.LinkPreview.Title)
+	assert.Empty(t, msg.PreviewURL)
+
+	msg, err = convoService.UpdateMessage("user1", "msg1", "Moved to https://example.com/b", nil)
+	assert.Nil(t, err)
+	assert.Nil(t, msg.LinkPreview)
+	assert.Equal(t, "https://example.com/b", msg.PreviewURL)
+}
+
+// Test 22: Entities produced by the markdown parser pass validation, including
+// code nested in other spans.
+func TestMarkdownEntitiesAreValid(t *testing.T) {
+	inputs := []string{
+		"**bold `code` here**",
+		"[see `x`](https://a.b)",
+		"||`x`||",
+		"***a***",
+		"***a** b*",
+		"_italic ```\npre\n``` block_ and `code`",
+	}
+	for _, input := range inputs {
+		text, entities := markdown.Parse(input)
+		_, err := validateEntities(text, entities)
+		assert.Nil(t, err, input)
+	}
+}
+
+// Test 23: Only participants can read a conversation; users who left get "chat not found".
+func TestGetMessagesRequiresParticipant(t *testing.T) {
+	convoRepoMock := new(mocks.ConversationRepositoryMock)
+	messageRepoMock := new(mocks.MessageRepositoryMock)
+	convoService := NewConversationService(convoRepoMock, messageRepoMock, new(mocks.UserRepositoryMock), new(mocks.BlockRepositoryMock), new(mocks.ContactRepositoryMock))
+
+	convo := &domain.Conversation{ID: "convo1", Participants: []string{"user1", "user2"}}
+	convoRepoMock.On("FindByID", "convo1").Return(convo, nil)
+	convoRepoMock.On("FindByID", "missing").Return(nil, nil)
+	messageRepoMock.On("FindByConversation", "convo1").Return([]*domain.Message{{ID: "msg1", ConversationID: "convo1"}}, nil)
+
+	messages, err := convoService.GetMessages("user1", "convo1")
+	assert.Nil(t, err)
+	assert.Len(t, messages, 1)
+
+	// user3 never joined, or left and was removed from Participants.
+	messages, err = convoService.GetMessages("user3", "convo1")
+	assert.Nil(t, messages)
+	assert.ErrorIs(t, err, ErrChatNotFound)
+
+	_, err = convoService.GetMessages("user1", "missing")
+	assert.ErrorIs(t, err, ErrChatNotFound)
+	messageRepoMock.AssertNumberOfCalls(t, "FindByConversation", 1)
+}
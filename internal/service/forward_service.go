@@ -0,0 +1,153 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"social_media/internal/domain"
+)
+
+const maxForwardedMessages = 100
+
+// ForwardService copies messages between conversations and rooms. The user must be
+// able to read the source chat, and the target's usual send rules apply.
+type ForwardService interface {
+	// ForwardToConversation posts the messages, in the order given, to a conversation.
+	ForwardToConversation(userID string, from domain.ChatRef, messageIDs []string, convoID string) ([]*domain.Message, error)
+	// ForwardToRoom posts the messages, in the order given, to a room.
+	ForwardToRoom(userID string, from domain.ChatRef, messageIDs []string, roomID string) ([]*domain.RoomMessage, error)
+}
+
+type forwardService struct {
+	convoRepo       domain.ConversationRepository
+	messageRepo     domain.MessageRepository
+	roomRepo        domain.RoomRepository
+	membershipRepo  domain.RoomMembershipRepository
+	restrictionRepo domain.RoomRestrictionRepository
+	roomMessageRepo domain.RoomMessageRepository
+	convoService    ConversationService
+	roomService     RoomService
+}
+
+// NewForwardService creates a new instance of ForwardService.
+func NewForwardService(
+	convoRepo domain.ConversationRepository,
+	messageRepo domain.MessageRepository,
+	roomRepo domain.RoomRepository,
+	membershipRepo domain.RoomMembershipRepository,
+	restrictionRepo domain.RoomRestrictionRepository,
+	roomMessageRepo domain.RoomMessageRepository,
+	convoService ConversationService,
+	roomService RoomService,
+) ForwardService {
+	return &forwardService{
+		convoRepo:       convoRepo,
+		messageRepo:     messageRepo,
+		roomRepo:        roomRepo,
+		membershipRepo:  membershipRepo,
+		restrictionRepo: restrictionRepo,
+		roomMessageRepo: roomMessageRepo,
+		convoService:    convoService,
+		roomService:     roomService,
+	}
+}
+
+func (s *forwardService) ForwardToConversation(userID string, from domain.ChatRef, messageIDs []string, convoID string) ([]*domain.Message, error) {
+	forwards, err := s.forwards(userID, from, messageIDs)
+	if err != nil {
+		return nil, err
+	}
+	return s.convoService.ForwardMessages(userID, convoID, forwards)
+}
+
+func (s *forwardService) ForwardToRoom(userID string, from domain.ChatRef, messageIDs []string, roomID string) ([]*domain.RoomMessage, error) {
+	forwards, err := s.forwards(userID, from, messageIDs)
+	if err != nil {
+		return nil, err
+	}
+	return s.roomService.ForwardMessages(roomID, userID, forwards)
+}
+
+// forwards loads the source messages the user can read and attributes each to its origin.
+func (s *forwardService) forwards(userID string, from domain.ChatRef, messageIDs []string) ([]domain.Forward, error) {
+	if len(messageIDs) == 0 {
+		return nil, errors.New("no messages to forward")
+	}
+	if len(messageIDs) > maxForwardedMessages {
+		return nil, fmt.Errorf("cannot forward more than %d messages at once", maxForwardedMessages)
+	}
+	if err := checkReadAccess(s.convoRepo, s.roomRepo, s.membershipRepo, s.restrictionRepo, userID, from); err != nil {
+		return nil, err
+	}
+	if from.Kind == domain.ChatRoom {
+		return s.roomForwards(from.ID, messageIDs)
+	}
+
+	forwards := make([]domain.Forward, 0, len(messageIDs))
+	for _, messageID := range messageIDs {
+		message, err := s.messageRepo.FindByID(messageID)
+		if err != nil {
+			return nil, err
+		}
+		if message == nil || message.ConversationID != from.ID {
+			return nil, ErrMessageNotFound
+		}
+		if message.Kind.IsService() {
+			return nil, errors.New("service messages cannot be forwarded")
+		}
+		origin := domain.ForwardOrigin{SenderID: message.SenderID, Date: message.CreatedAt}
+		if message.ForwardedFrom != nil {
+			origin = *message.ForwardedFrom
+		}
+		forwards = append(forwards, domain.Forward{Content: message.Content, Entities: formattingOf(message.Entities), Origin: origin})
+	}
+	return forwards, nil
+}
+
+// roomForwards loads the room messages. Posts from channels link back to the channel.
+func (s *forwardService) roomForwards(roomID string, messageIDs []string) ([]domain.Forward, error) {
+	room, err := s.roomRepo.FindByID(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+	forwards := make([]domain.Forward, 0, len(messageIDs))
+	for _, messageID := range messageIDs {
+		message, err := s.roomMessageRepo.FindByID(messageID)
+		if err != nil {
+			return nil, err
+		}
+		if message == nil || message.RoomID != roomID {
+			return nil, ErrMessageNotFound
+		}
+		if message.Kind.IsService() {
+			return nil, errors.New("service messages cannot be forwarded")
+		}
+		if message.Kind == domain.MessageKindPoll {
+			return nil, errors.New("polls cannot be forwarded")
+		}
+		origin := domain.ForwardOrigin{SenderID: message.SenderID, Date: message.CreatedAt}
+		if room.Type == domain.RoomTypeChannel {
+			origin.RoomID, origin.MessageID = &room.ID, &message.ID
+		}
+		if message.ForwardedFrom != nil {
+			origin = *message.ForwardedFrom
+		}
+		forwards = append(forwards, domain.Forward{Content: message.Content, Entities: formattingOf(message.Entities), Origin: origin})
+	}
+	return forwards, nil
+}
+
+// formattingOf keeps the formatting entities of a message; mentions only make
+// sense in the chat they were sent in.
+func formattingOf(entities []domain.MessageEntity) []domain.MessageEntity {
+	var formatting []domain.MessageEntity
+	for _, entity := range entities {
+		if entity.Type.IsFormatting() {
+			formatting = append(formatting, entity)
+		}
+	}
+	return formatting
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+	"social_media/internal/mocks"
+)
+
+// Test 1: Channel posts forwarded to a conversation keep their order and link back to the channel.
+func TestForwardChannelPostsToConversation(t *testing.T) {
+	convoRepoMock := new(mocks.ConversationRepositoryMock)
+	messageRepoMock := new(mocks.MessageRepositoryMock)
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	blockRepoMock := new(mocks.BlockRepositoryMock)
+	convoService := NewConversationService(convoRepoMock, messageRepoMock, new(mocks.UserRepositoryMock), blockRepoMock, new(mocks.ContactRepositoryMock))
+	forwardService := NewForwardService(convoRepoMock, messageRepoMock, roomRepoMock, membershipRepoMock, new(mocks.RoomRestrictionRepositoryMock), roomMessageRepoMock, convoService, nil)
+
+	channelName := "news"
+	posted := time.Now().Add(-time.Hour)
+	roomRepoMock.On("FindByID", "chan1").Return(&domain.Room{ID: "chan1", Type: domain.RoomTypeChannel, Username: &channelName}, nil)
+	membershipRepoMock.On("GetMembership", "chan1", "user1").Return(nil, nil)
+	roomMessageRepoMock.On("FindByID", "post1").Return(&domain.RoomMessage{ID: "post1", RoomID: "chan1", SenderID: "admin1", Content: "First", CreatedAt: posted}, nil)
+	roomMessageRepoMock.On("FindByID", "post2").Return(&domain.RoomMessage{ID: "post2", RoomID: "chan1", SenderID: "admin1", Content: "Second", CreatedAt: posted}, nil)
+	convoRepoMock.On("FindByID", "c1").Return(&domain.Conversation{ID: "c1", Kind: domain.ConversationDirect,
+		Participant1: "user1", Participant2: "user2", Participants: []string{"user1", "user2"}, Status: domain.ConversationActive}, nil)
+	blockRepoMock.On("IsBlocked", "user2", "user1").Return(false, nil)
+	blockRepoMock.On("IsBlocked", "user1", "user2").Return(false, nil)
+	messageRepoMock.On("Create", mock.AnythingOfType("*domain.Message")).Return(nil)
+
+	messages, err := forwardService.ForwardToConversation("user1", domain.ChatRef{Kind: domain.ChatRoom, ID: "chan1"}, []string{"post2", "post1"}, "c1")
+	assert.Nil(t, err)
+	assert.Len(t, messages, 2)
+	assert.Equal(t, "Second", messages[0].Content)
+	assert.Equal(t, "user1", messages[0].SenderID)
+	origin := messages[0].ForwardedFrom
+	assert.Equal(t, "admin1", origin.SenderID)
+	assert.Equal(t, "chan1", *origin.RoomID)
+	assert.Equal(t, "post2", *origin.MessageID)
+	assert.Equal(t, posted, origin.Date)
+}
+
+// Test 2: Messages of a private room cannot be forwarded by non-members.
+func TestForwardFromUnreadableRoom(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	forwardService := NewForwardService(new(mocks.ConversationRepositoryMock), new(mocks.MessageRepositoryMock), roomRepoMock, membershipRepoMock, new(mocks.RoomRestrictionRepositoryMock), roomMessageRepoMock, nil, nil)
+
+	roomRepoMock.On("FindByID", "room1").Return(&domain.Room{ID: "room1", Type: domain.RoomTypeGroup}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user1").Return(nil, nil)
+
+	messages, err := forwardService.ForwardToConversation("user1", domain.ChatRef{Kind: domain.ChatRoom, ID: "room1"}, []string{"msg1"}, "c1")
+	assert.ErrorIs(t, err, ErrChatNotFound)
+	assert.Nil(t, messages)
+	roomMessageRepoMock.AssertNotCalled(t, "FindByID", mock.Anything)
+}
+
+// Test 3: Forwarding into a channel requires the post permission there.
+func TestForwardToChannelWithoutPostPermission(t *testing.T) {
+	convoRepoMock := new(mocks.ConversationRepositoryMock)
+	messageRepoMock := new(mocks.MessageRepositoryMock)
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, new(mocks.UserRepositoryMock), new(mocks.RoomAuditRepositoryMock), restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+	forwardService := NewForwardService(convoRepoMock, messageRepoMock, roomRepoMock, membershipRepoMock, restrictionRepoMock, roomMessageRepoMock, nil, roomService)
+
+	convoRepoMock.On("FindByID", "c1").Return(&domain.Conversation{ID: "c1", Participants: []string{"user1", "user2"}}, nil)
+	messageRepoMock.On("FindByID", "msg1").Return(&domain.Message{ID: "msg1", ConversationID: "c1", SenderID: "user2", Content: "Hi"}, nil)
+	restrictionRepoMock.On("Find", "chan1", "user1", domain.RestrictionMute).Return(nil, nil)
+	roomRepoMock.On("FindByID", "chan1").Return(&domain.Room{ID: "chan1", Type: domain.RoomTypeChannel}, nil)
+	membershipRepoMock.On("GetMembership", "chan1", "user1").Return(&domain.RoomMembership{Role: domain.RoleMember}, nil)
+
+	messages, err := forwardService.ForwardToRoom("user1", domain.ChatRef{Kind: domain.ChatConversation, ID: "c1"}, []string{"msg1"}, "chan1")
+	assert.ErrorIs(t, err, ErrForbidden)
+	assert.Nil(t, messages)
+	roomMessageRepoMock.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// Test 4: Forwarding a forwarded message keeps the original attribution.
+func TestForwardKeepsOriginalAttribution(t *testing.T) {
+	convoRepoMock := new(mocks.ConversationRepositoryMock)
+	messageRepoMock := new(mocks.MessageRepositoryMock)
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, new(mocks.UserRepositoryMock), new(mocks.RoomAuditRepositoryMock), restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+	forwardService := NewForwardService(convoRepoMock, messageRepoMock, roomRepoMock, membershipRepoMock, restrictionRepoMock, roomMessageRepoMock, nil, roomService)
+
+	original := domain.ForwardOrigin{SenderID: "author1", Date: time.Now().Add(-24 * time.Hour)}
+	convoRepoMock.On("FindByID", "c1").Return(&domain.Conversation{ID: "c1", Participants: []string{"user1", "user2"}}, nil)
+	messageRepoMock.On("FindByID", "msg1").Return(&domain.Message{ID: "msg1", ConversationID: "c1", SenderID: "user2", Content: "Hi", ForwardedFrom: &original}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user1").Return(&domain.RoomMembership{RoomID: "room1", UserID: "user1", Role: domain.RoleMember}, nil)
+	restrictionRepoMock.On("Find", "room1", "user1", domain.RestrictionMute).Return(nil, nil)
+	roomRepoMock.On("FindByID", "room1").Return(&domain.Room{ID: "room1", Type: domain.RoomTypeGroup}, nil)
+	roomMessageRepoMock.On("Create", mock.AnythingOfType("*domain.RoomMessage")).Return(nil)
+
+	messages, err := forwardService.ForwardToRoom("user1", domain.ChatRef{Kind: domain.ChatConversation, ID: "c1"}, []string{"msg1"}, "room1")
+	assert.Nil(t, err)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, original, *messages[0].ForwardedFrom)
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"social_media/internal/domain"
+)
+
+// InviteService defines the operations on room invite links.
+type InviteService interface {
+	// CreateInvite creates a link for the room. expiresAt and maxUses are optional.
+	CreateInvite(roomID, requesterID string, expiresAt *time.Time, maxUses *int, requiresApproval bool) (*domain.RoomInvite, error)
+	ListInvites(roomID, requesterID string) ([]*domain.RoomInvite, error)
+	RevokeInvite(roomID, requesterID, token string) error
+	// AcceptInvite joins the user to the invite's room. If the link requires
+	// admin approval, a pending join request is returned instead.
+	AcceptInvite(token, userID string) (*domain.RoomJoinRequest, error)
+}
+
+type inviteService struct {
+	roomRepo        domain.RoomRepository
+	membershipRepo  domain.RoomMembershipRepository
+	restrictionRepo domain.RoomRestrictionRepository
+	inviteRepo      domain.RoomInviteRepository
+	joinRequestRepo domain.RoomJoinRequestRepository
+	auditRepo       domain.RoomAuditRepository
+}
+
+// NewInviteService creates a new instance of InviteService.
+func NewInviteService(
+	roomRepo domain.RoomRepository,
+	membershipRepo domain.RoomMembershipRepository,
+	restrictionRepo domain.RoomRestrictionRepository,
+	inviteRepo domain.RoomInviteRepository,
+	joinRequestRepo domain.RoomJoinRequestRepository,
+	auditRepo domain.RoomAuditRepository,
+) InviteService {
+	return &inviteService{
+		roomRepo:        roomRepo,
+		membershipRepo:  membershipRepo,
+		restrictionRepo: restrictionRepo,
+		inviteRepo:      inviteRepo,
+		joinRequestRepo: joinRequestRepo,
+		auditRepo:       auditRepo,
+	}
+}
+
+// CreateInvite creates a new invite link. Only owners and admins may create links.
+func (s *inviteService) CreateInvite(roomID, requesterID string, expiresAt *time.Time, maxUses *int, requiresApproval bool) (*domain.RoomInvite, error) {
+	room, err := s.roomRepo.FindByID(roomID)
+	if err != nil || room == nil {
+		return nil, errors.New("room not found")
+	}
+	if err := s.requireInvitePermission(roomID, requesterID); err != nil {
+		return nil, err
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, errors.New("expiry must be in the future")
+	}
+	if maxUses != nil && *maxUses <= 0 {
+		return nil, errors.New("max uses must be positive")
+	}
+	token, err := newInviteToken()
+	if err != nil {
+		return nil, err
+	}
+	invite := &domain.RoomInvite{
+		Token:            token,
+		RoomID:           roomID,
+		CreatorID:        requesterID,
+		ExpiresAt:        expiresAt,
+		MaxUses:          maxUses,
+		RequiresApproval: requiresApproval,
+		CreatedAt:        time.Now(),
+	}
+	if err := s.inviteRepo.Create(invite); err != nil {
+		return nil, err
+	}
+	if err := appendAudit(s.auditRepo, roomID, requesterID, domain.AuditCreateInvite, token, nil, invite); err != nil {
+		return nil, err
+	}
+	return invite, nil
+}
+
+// ListInvites returns all invite links of the room, including revoked ones.
+func (s *inviteService) ListInvites(roomID, requesterID string) ([]*domain.RoomInvite, error) {
+	if err := s.requireInvitePermission(roomID, requesterID); err != nil {
+		return nil, err
+	}
+	return s.inviteRepo.FindByRoom(roomID)
+}
+
+// RevokeInvite disables an invite link of the room.
+func (s *inviteService) RevokeInvite(roomID, requesterID, token string) error {
+	if err := s.requireInvitePermission(roomID, requesterID); err != nil {
+		return err
+	}
+	invite, err := s.inviteRepo.FindByToken(token)
+	if err != nil || invite == nil || invite.RoomID != roomID {
+		return errors.New("invite not found")
+	}
+	if err := s.inviteRepo.Revoke(token); err != nil {
+		return err
+	}
+	return appendAudit(s.auditRepo, roomID, requesterID, domain.AuditRevokeInvite, token, invite, nil)
+}
+
+// AcceptInvite redeems the invite for the user.
+func (s *inviteService) AcceptInvite(token, userID string) (*domain.RoomJoinRequest, error) {
+	invite, err := s.inviteRepo.FindByToken(token)
+	if err != nil || invite == nil {
+		return nil, errors.New("invite not found")
+	}
+	now := time.Now()
+	if !invite.IsUsable(now) {
+		return nil, errors.New("invite is no longer valid")
+	}
+	// Lifting an expired ban restores the membership, which the check below reports.
+	banned, err := activeBan(s.membershipRepo, s.restrictionRepo, invite.RoomID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if banned {
+		return nil, errors.New("you are banned from this room")
+	}
+	existingRole, err := s.membershipRepo.GetMemberRole(invite.RoomID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existingRole != "" {
+		return nil, errors.New("user already a member")
+	}
+	if invite.RequiresApproval {
+		pending, err := s.joinRequestRepo.FindPending(invite.RoomID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if pending != nil {
+			return pending, nil
+		}
+	}
+
+	// Consume a use atomically; this also guards against races on max uses.
+	ok, err := s.inviteRepo.Redeem(token, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("invite is no longer valid")
+	}
+
+	if invite.RequiresApproval {
+		request := &domain.RoomJoinRequest{
+			ID:          uuid.New().String(),
+			RoomID:      invite.RoomID,
+			UserID:      userID,
+			InviteToken: &invite.Token,
+			Status:      domain.JoinRequestPending,
+			CreatedAt:   now,
+		}
+		if err := s.joinRequestRepo.Create(request); err != nil {
+			return nil, err
+		}
+		return request, nil
+	}
+	membership := &domain.RoomMembership{
+		RoomID:    invite.RoomID,
+		UserID:    userID,
+		Role:      domain.RoleMember,
+		CreatedAt: now,
+	}
+	return nil, s.membershipRepo.AddMember(membership)
+}
+
+func (s *inviteService) requireInvitePermission(roomID, userID string) error {
+	_, err := requirePermission(s.membershipRepo, roomID, userID, domain.PermInviteUsers, "not authorized to manage invites")
+	return err
+}
+
+// newInviteToken returns a random URL-safe token.
+func newInviteToken() (string, error) {
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
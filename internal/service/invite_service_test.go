@@ -0,0 +1,183 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+	"social_media/internal/mocks"
+)
+
+// Test 1: Create invite by a regular member.
+func TestCreateInviteUnauthorized(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	inviteRepoMock := new(mocks.RoomInviteRepositoryMock)
+	joinRequestRepoMock := new(mocks.RoomJoinRequestRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	inviteService := NewInviteService(roomRepoMock, membershipRepoMock, new(mocks.RoomRestrictionRepositoryMock), inviteRepoMock, joinRequestRepoMock, auditRepoMock)
+
+	roomRepoMock.On("FindByID", "room1").Return(&domain.Room{ID: "room1"}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user1").Return(&domain.RoomMembership{Role: domain.RoleMember}, nil)
+
+	invite, err := inviteService.CreateInvite("room1", "user1", nil, nil, false)
+	assert.Nil(t, invite)
+	assert.EqualError(t, err, "not authorized to manage invites")
+	inviteRepoMock.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// Test 2: Create invite successfully.
+func TestCreateInviteSuccess(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	inviteRepoMock := new(mocks.RoomInviteRepositoryMock)
+	joinRequestRepoMock := new(mocks.RoomJoinRequestRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	inviteService := NewInviteService(roomRepoMock, membershipRepoMock, new(mocks.RoomRestrictionRepositoryMock), inviteRepoMock, joinRequestRepoMock, auditRepoMock)
+
+	roomRepoMock.On("FindByID", "room1").Return(&domain.Room{ID: "room1"}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "admin1").Return(&domain.RoomMembership{Role: domain.RoleAdmin, Permissions: domain.DefaultAdminPermissions}, nil)
+	inviteRepoMock.On("Create", mock.AnythingOfType("*domain.RoomInvite")).Return(nil)
+	auditRepoMock.On("Append", mock.MatchedBy(func(e *domain.RoomAuditEntry) bool {
+		return e.Action == domain.AuditCreateInvite && e.ActorID == "admin1"
+	})).Return(nil)
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+	maxUses := 5
+	invite, err := inviteService.CreateInvite("room1", "admin1", &expiresAt, &maxUses, true)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, invite.Token)
+	assert.Equal(t, 5, *invite.MaxUses)
+	assert.True(t, invite.RequiresApproval)
+	inviteRepoMock.AssertExpectations(t)
+	auditRepoMock.AssertExpectations(t)
+}
+
+// Test 3: Create invite with an expiry in the past.
+func TestCreateInvitePastExpiry(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	inviteRepoMock := new(mocks.RoomInviteRepositoryMock)
+	joinRequestRepoMock := new(mocks.RoomJoinRequestRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	inviteService := NewInviteService(roomRepoMock, membershipRepoMock, new(mocks.RoomRestrictionRepositoryMock), inviteRepoMock, joinRequestRepoMock, auditRepoMock)
+
+	roomRepoMock.On("FindByID", "room1").Return(&domain.Room{ID: "room1"}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "owner1").Return(&domain.RoomMembership{Role: domain.RoleOwner}, nil)
+
+	expiresAt := time.Now().Add(-time.Minute)
+	invite, err := inviteService.CreateInvite("room1", "owner1", &expiresAt, nil, false)
+	assert.Nil(t, invite)
+	assert.EqualError(t, err, "expiry must be in the future")
+}
+
+// Test 4: Accept an exhausted invite.
+func TestAcceptInviteExhausted(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	inviteRepoMock := new(mocks.RoomInviteRepositoryMock)
+	joinRequestRepoMock := new(mocks.RoomJoinRequestRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	inviteService := NewInviteService(roomRepoMock, membershipRepoMock, new(mocks.RoomRestrictionRepositoryMock), inviteRepoMock, joinRequestRepoMock, auditRepoMock)
+
+	maxUses := 1
+	invite := &domain.RoomInvite{Token: "tok", RoomID: "room1", MaxUses: &maxUses, UseCount: 1}
+	inviteRepoMock.On("FindByToken", "tok").Return(invite, nil)
+
+	request, err := inviteService.AcceptInvite("tok", "user1")
+	assert.Nil(t, request)
+	assert.EqualError(t, err, "invite is no longer valid")
+	membershipRepoMock.AssertNotCalled(t, "AddMember", mock.Anything)
+}
+
+// Test 5: Banned users cannot rejoin through a link.
+func TestAcceptInviteBannedUser(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	inviteRepoMock := new(mocks.RoomInviteRepositoryMock)
+	joinRequestRepoMock := new(mocks.RoomJoinRequestRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	inviteService := NewInviteService(roomRepoMock, membershipRepoMock, restrictionRepoMock, inviteRepoMock, joinRequestRepoMock, auditRepoMock)
+
+	inviteRepoMock.On("FindByToken", "tok").Return(&domain.RoomInvite{Token: "tok", RoomID: "room1"}, nil)
+	membershipRepoMock.On("GetMemberRole", "room1", "user1").Return(domain.RoleBanned, nil)
+	restrictionRepoMock.On("Find", "room1", "user1", domain.RestrictionBan).Return(nil, nil)
+
+	request, err := inviteService.AcceptInvite("tok", "user1")
+	assert.Nil(t, request)
+	assert.EqualError(t, err, "you are banned from this room")
+	inviteRepoMock.AssertNotCalled(t, "Redeem", mock.Anything, mock.Anything)
+}
+
+// Test 6: Accept an invite and join directly.
+func TestAcceptInviteJoins(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	inviteRepoMock := new(mocks.RoomInviteRepositoryMock)
+	joinRequestRepoMock := new(mocks.RoomJoinRequestRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	inviteService := NewInviteService(roomRepoMock, membershipRepoMock, new(mocks.RoomRestrictionRepositoryMock), inviteRepoMock, joinRequestRepoMock, auditRepoMock)
+
+	inviteRepoMock.On("FindByToken", "tok").Return(&domain.RoomInvite{Token: "tok", RoomID: "room1"}, nil)
+	membershipRepoMock.On("GetMemberRole", "room1", "user1").Return(domain.RoomMembershipRole(""), nil)
+	inviteRepoMock.On("Redeem", "tok", mock.AnythingOfType("time.Time")).Return(true, nil)
+	membershipRepoMock.On("AddMember", mock.MatchedBy(func(m *domain.RoomMembership) bool {
+		return m.RoomID == "room1" && m.UserID == "user1" && m.Role == domain.RoleMember
+	})).Return(nil)
+
+	request, err := inviteService.AcceptInvite("tok", "user1")
+	assert.Nil(t, err)
+	assert.Nil(t, request)
+	inviteRepoMock.AssertExpectations(t)
+	membershipRepoMock.AssertExpectations(t)
+}
+
+// Test 7: Accept an invite that requires approval.
+func TestAcceptInviteRequiresApproval(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	inviteRepoMock := new(mocks.RoomInviteRepositoryMock)
+	joinRequestRepoMock := new(mocks.RoomJoinRequestRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	inviteService := NewInviteService(roomRepoMock, membershipRepoMock, new(mocks.RoomRestrictionRepositoryMock), inviteRepoMock, joinRequestRepoMock, auditRepoMock)
+
+	inviteRepoMock.On("FindByToken", "tok").Return(&domain.RoomInvite{Token: "tok", RoomID: "room1", RequiresApproval: true}, nil)
+	membershipRepoMock.On("GetMemberRole", "room1", "user1").Return(domain.RoomMembershipRole(""), nil)
+	joinRequestRepoMock.On("FindPending", "room1", "user1").Return(nil, nil)
+	inviteRepoMock.On("Redeem", "tok", mock.AnythingOfType("time.Time")).Return(true, nil)
+	joinRequestRepoMock.On("Create", mock.AnythingOfType("*domain.RoomJoinRequest")).Return(nil)
+
+	request, err := inviteService.AcceptInvite("tok", "user1")
+	assert.Nil(t, err)
+	assert.Equal(t, domain.JoinRequestPending, request.Status)
+	assert.Equal(t, "tok", *request.InviteToken)
+	membershipRepoMock.AssertNotCalled(t, "AddMember", mock.Anything)
+	joinRequestRepoMock.AssertExpectations(t)
+}
+
+// Test 8: A timed ban that has expired is lifted when an invite is used, which
+// restores the user's membership.
+func TestAcceptInviteExpiredBan(t *testing.T) {
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	inviteRepoMock := new(mocks.RoomInviteRepositoryMock)
+	inviteService := NewInviteService(new(mocks.RoomRepositoryMock), membershipRepoMock, restrictionRepoMock, inviteRepoMock, new(mocks.RoomJoinRequestRepositoryMock), new(mocks.RoomAuditRepositoryMock))
+
+	expired := time.Now().Add(-time.Minute)
+	inviteRepoMock.On("FindByToken", "tok").Return(&domain.RoomInvite{Token: "tok", RoomID: "room1"}, nil)
+	membershipRepoMock.On("GetMemberRole", "room1", "user1").Return(domain.RoleBanned, nil).Once()
+	restrictionRepoMock.On("Find", "room1", "user1", domain.RestrictionBan).Return(&domain.RoomRestriction{
+		RoomID: "room1", UserID: "user1", Kind: domain.RestrictionBan, PriorRole: domain.RoleMember, ExpiresAt: &expired,
+	}, nil)
+	membershipRepoMock.On("UpdateMemberRole", "room1", "user1", domain.RoleMember).Return(nil)
+	restrictionRepoMock.On("Delete", "room1", "user1", domain.RestrictionBan).Return(nil)
+	membershipRepoMock.On("GetMemberRole", "room1", "user1").Return(domain.RoleMember, nil)
+
+	request, err := inviteService.AcceptInvite("tok", "user1")
+	assert.Nil(t, request)
+	assert.EqualError(t, err, "user already a member")
+	restrictionRepoMock.AssertExpectations(t)
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"social_media/internal/domain"
+)
+
+// JoinRequestService defines the request-to-join flow and its approval queue.
+type JoinRequestService interface {
+	RequestToJoin(roomID, userID, message string) (*domain.RoomJoinRequest, error)
+	ListPending(roomID, requesterID string) ([]*domain.RoomJoinRequest, error)
+	Approve(roomID, requesterID, requestID string) error
+	Decline(roomID, requesterID, requestID string) error
+}
+
+type joinRequestService struct {
+	roomRepo         domain.RoomRepository
+	membershipRepo   domain.RoomMembershipRepository
+	restrictionRepo  domain.RoomRestrictionRepository
+	joinRequestRepo  domain.RoomJoinRequestRepository
+	notificationRepo domain.NotificationRepository
+	chatStateRepo    domain.ChatStateRepository // muted rooms suppress notifications
+	roomService      RoomService                // approvals go through RoomService.AdmitMember
+}
+
+// NewJoinRequestService creates a new instance of JoinRequestService.
+func NewJoinRequestService(
+	roomRepo domain.RoomRepository,
+	membershipRepo domain.RoomMembershipRepository,
+	restrictionRepo domain.RoomRestrictionRepository,
+	joinRequestRepo domain.RoomJoinRequestRepository,
+	notificationRepo domain.NotificationRepository,
+	chatStateRepo domain.ChatStateRepository,
+	roomService RoomService,
+) JoinRequestService {
+	return &joinRequestService{
+		roomRepo:         roomRepo,
+		membershipRepo:   membershipRepo,
+		restrictionRepo:  restrictionRepo,
+		joinRequestRepo:  joinRequestRepo,
+		notificationRepo: notificationRepo,
+		chatStateRepo:    chatStateRepo,
+		roomService:      roomService,
+	}
+}
+
+// RequestToJoin submits a join request for a room in request-to-join mode.
+func (s *joinRequestService) RequestToJoin(roomID, userID, message string) (*domain.RoomJoinRequest, error) {
+	room, err := s.roomRepo.FindByID(roomID)
+	if err != nil || room == nil {
+		return nil, errors.New("room not found")
+	}
+	if !room.JoinByRequest {
+		return nil, errors.New("room does not accept join requests")
+	}
+	existingRole, err := s.membershipRepo.GetMemberRole(roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existingRole == domain.RoleBanned {
+		banned, err := banInForce(s.membershipRepo, s.restrictionRepo, roomID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if banned {
+			return nil, errors.New("you are banned from this room")
+		}
+		// The expired ban was lifted and the prior membership restored.
+		return nil, errors.New("user already a member")
+	}
+	if existingRole != "" {
+		return nil, errors.New("user already a member")
+	}
+	pending, err := s.joinRequestRepo.FindPending(roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if pending != nil {
+		return nil, errors.New("join request already pending")
+	}
+	request := &domain.RoomJoinRequest{
+		ID:        uuid.New().String(),
+		RoomID:    roomID,
+		UserID:    userID,
+		Message:   message,
+		Status:    domain.JoinRequestPending,
+		CreatedAt: time.Now(),
+	}
+	if err := s.joinRequestRepo.Create(request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// ListPending returns the room's pending requests, oldest first.
+func (s *joinRequestService) ListPending(roomID, requesterID string) ([]*domain.RoomJoinRequest, error) {
+	if err := s.requireInvitePermission(roomID, requesterID); err != nil {
+		return nil, err
+	}
+	return s.joinRequestRepo.FindPendingByRoom(roomID)
+}
+
+// Approve adds the requester as a member and notifies them.
+func (s *joinRequestService) Approve(roomID, requesterID, requestID string) error {
+	request, err := s.pendingRequest(roomID, requesterID, requestID)
+	if err != nil {
+		return err
+	}
+	if err := s.roomService.AdmitMember(roomID, requesterID, request.UserID); err != nil {
+		return err
+	}
+	return s.decide(request, requesterID, domain.JoinRequestApproved, domain.NotificationJoinRequestApproved)
+}
+
+// Decline rejects the request and notifies the requester.
+func (s *joinRequestService) Decline(roomID, requesterID, requestID string) error {
+	request, err := s.pendingRequest(roomID, requesterID, requestID)
+	if err != nil {
+		return err
+	}
+	return s.decide(request, requesterID, domain.JoinRequestDeclined, domain.NotificationJoinRequestDeclined)
+}
+
+// pendingRequest checks the requester may decide and loads the pending request.
+func (s *joinRequestService) pendingRequest(roomID, requesterID, requestID string) (*domain.RoomJoinRequest, error) {
+	if err := s.requireInvitePermission(roomID, requesterID); err != nil {
+		return nil, err
+	}
+	request, err := s.joinRequestRepo.FindByID(requestID)
+	if err != nil || request == nil || request.RoomID != roomID {
+		return nil, errors.New("join request not found")
+	}
+	if request.Status != domain.JoinRequestPending {
+		return nil, errors.New("join request already decided")
+	}
+	return request, nil
+}
+
+// decide records the decision and notifies the requester.
+func (s *joinRequestService) decide(request *domain.RoomJoinRequest, deciderID string, status domain.JoinRequestStatus, notificationType domain.NotificationType) error {
+	now := time.Now()
+	request.Status = status
+	request.DecidedBy = &deciderID
+	request.DecidedAt = &now
+	if err := s.joinRequestRepo.UpdateStatus(request); err != nil {
+		return err
+	}
+	notification := &domain.Notification{
+		ID:          uuid.New().String(),
+		UserID:      request.UserID,
+		Type:        notificationType,
+		RoomID:      &request.RoomID,
+		ActorID:     &deciderID,
+		ReferenceID: &request.ID,
+		CreatedAt:   now,
+	}
+	return deliverNotification(s.notificationRepo, s.chatStateRepo, notification)
+}
+
+func (s *joinRequestService) requireInvitePermission(roomID, userID string) error {
+	_, err := requirePermission(s.membershipRepo, roomID, userID, domain.PermInviteUsers, "not authorized to manage join requests")
+	return err
+}
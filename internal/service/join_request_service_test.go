@@ -0,0 +1,164 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+	"social_media/internal/mocks"
+)
+
+// Test 1: Request to join a room that is not in request-to-join mode.
+func TestRequestToJoinNotAccepted(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	joinRequestRepoMock := new(mocks.RoomJoinRequestRepositoryMock)
+	notificationRepoMock := new(mocks.NotificationRepositoryMock)
+	chatStateRepoMock := new(mocks.ChatStateRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, new(mocks.RoomMessageRepositoryMock), new(mocks.UserRepositoryMock), new(mocks.RoomAuditRepositoryMock), new(mocks.RoomRestrictionRepositoryMock), new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+	joinRequestService := NewJoinRequestService(roomRepoMock, membershipRepoMock, new(mocks.RoomRestrictionRepositoryMock), joinRequestRepoMock, notificationRepoMock, chatStateRepoMock, roomService)
+
+	roomRepoMock.On("FindByID", "room1").Return(&domain.Room{ID: "room1"}, nil)
+
+	request, err := joinRequestService.RequestToJoin("room1", "user1", "hi")
+	assert.Nil(t, request)
+	assert.EqualError(t, err, "room does not accept join requests")
+}
+
+// Test 2: Submit a join request successfully.
+func TestRequestToJoinSuccess(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	joinRequestRepoMock := new(mocks.RoomJoinRequestRepositoryMock)
+	notificationRepoMock := new(mocks.NotificationRepositoryMock)
+	chatStateRepoMock := new(mocks.ChatStateRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, new(mocks.RoomMessageRepositoryMock), new(mocks.UserRepositoryMock), new(mocks.RoomAuditRepositoryMock), new(mocks.RoomRestrictionRepositoryMock), new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+	joinRequestService := NewJoinRequestService(roomRepoMock, membershipRepoMock, new(mocks.RoomRestrictionRepositoryMock), joinRequestRepoMock, notificationRepoMock, chatStateRepoMock, roomService)
+
+	roomRepoMock.On("FindByID", "room1").Return(&domain.Room{ID: "room1", JoinByRequest: true}, nil)
+	membershipRepoMock.On("GetMemberRole", "room1", "user1").Return(domain.RoomMembershipRole(""), nil)
+	joinRequestRepoMock.On("FindPending", "room1", "user1").Return(nil, nil)
+	joinRequestRepoMock.On("Create", mock.AnythingOfType("*domain.RoomJoinRequest")).Return(nil)
+
+	request, err := joinRequestService.RequestToJoin("room1", "user1", "Let me in")
+	assert.Nil(t, err)
+	assert.Equal(t, domain.JoinRequestPending, request.Status)
+	assert.Equal(t, "Let me in", request.Message)
+	joinRequestRepoMock.AssertExpectations(t)
+}
+
+// Test 3: Only owners and admins see the queue.
+func TestListPendingUnauthorized(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	joinRequestRepoMock := new(mocks.RoomJoinRequestRepositoryMock)
+	notificationRepoMock := new(mocks.NotificationRepositoryMock)
+	chatStateRepoMock := new(mocks.ChatStateRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, new(mocks.RoomMessageRepositoryMock), new(mocks.UserRepositoryMock), new(mocks.RoomAuditRepositoryMock), new(mocks.RoomRestrictionRepositoryMock), new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+	joinRequestService := NewJoinRequestService(roomRepoMock, membershipRepoMock, new(mocks.RoomRestrictionRepositoryMock), joinRequestRepoMock, notificationRepoMock, chatStateRepoMock, roomService)
+
+	membershipRepoMock.On("GetMembership", "room1", "user2").Return(&domain.RoomMembership{Role: domain.RoleMember}, nil)
+
+	requests, err := joinRequestService.ListPending("room1", "user2")
+	assert.Nil(t, requests)
+	assert.EqualError(t, err, "not authorized to manage join requests")
+}
+
+// Test 4: Approving adds the member and notifies the requester.
+func TestApproveJoinRequest(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	joinRequestRepoMock := new(mocks.RoomJoinRequestRepositoryMock)
+	notificationRepoMock := new(mocks.NotificationRepositoryMock)
+	chatStateRepoMock := new(mocks.ChatStateRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, new(mocks.RoomMessageRepositoryMock), new(mocks.UserRepositoryMock), new(mocks.RoomAuditRepositoryMock), new(mocks.RoomRestrictionRepositoryMock), new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+	joinRequestService := NewJoinRequestService(roomRepoMock, membershipRepoMock, new(mocks.RoomRestrictionRepositoryMock), joinRequestRepoMock, notificationRepoMock, chatStateRepoMock, roomService)
+
+	request := &domain.RoomJoinRequest{ID: "req1", RoomID: "room1", UserID: "user1", Status: domain.JoinRequestPending}
+	membershipRepoMock.On("GetMembership", "room1", "admin1").Return(&domain.RoomMembership{Role: domain.RoleAdmin, Permissions: domain.DefaultAdminPermissions}, nil)
+	joinRequestRepoMock.On("FindByID", "req1").Return(request, nil)
+	// AdmitMember path.
+	roomRepoMock.On("FindByID", "room1").Return(&domain.Room{ID: "room1", Type: domain.RoomTypeGroup, JoinByRequest: true}, nil)
+	membershipRepoMock.On("GetMemberRole", "room1", "user1").Return(domain.RoomMembershipRole(""), nil)
+	membershipRepoMock.On("AddMember", mock.AnythingOfType("*domain.RoomMembership")).Return(nil)
+	joinRequestRepoMock.On("UpdateStatus", request).Return(nil)
+	chatStateRepoMock.On("Get", "user1", domain.ChatRef{Kind: domain.ChatRoom, ID: "room1"}).Return(nil, nil)
+	notificationRepoMock.On("Create", mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.UserID == "user1" && n.Type == domain.NotificationJoinRequestApproved && *n.ReferenceID == "req1"
+	})).Return(nil)
+
+	err := joinRequestService.Approve("room1", "admin1", "req1")
+	assert.Nil(t, err)
+	assert.Equal(t, domain.JoinRequestApproved, request.Status)
+	assert.Equal(t, "admin1", *request.DecidedBy)
+	membershipRepoMock.AssertExpectations(t)
+	notificationRepoMock.AssertExpectations(t)
+}
+
+// Test 5: Declining notifies the requester without adding them.
+func TestDeclineJoinRequest(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	joinRequestRepoMock := new(mocks.RoomJoinRequestRepositoryMock)
+	notificationRepoMock := new(mocks.NotificationRepositoryMock)
+	chatStateRepoMock := new(mocks.ChatStateRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, new(mocks.RoomMessageRepositoryMock), new(mocks.UserRepositoryMock), new(mocks.RoomAuditRepositoryMock), new(mocks.RoomRestrictionRepositoryMock), new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+	joinRequestService := NewJoinRequestService(roomRepoMock, membershipRepoMock, new(mocks.RoomRestrictionRepositoryMock), joinRequestRepoMock, notificationRepoMock, chatStateRepoMock, roomService)
+
+	request := &domain.RoomJoinRequest{ID: "req1", RoomID: "room1", UserID: "user1", Status: domain.JoinRequestPending}
+	membershipRepoMock.On("GetMembership", "room1", "owner1").Return(&domain.RoomMembership{Role: domain.RoleOwner}, nil)
+	joinRequestRepoMock.On("FindByID", "req1").Return(request, nil)
+	joinRequestRepoMock.On("UpdateStatus", request).Return(nil)
+	chatStateRepoMock.On("Get", "user1", domain.ChatRef{Kind: domain.ChatRoom, ID: "room1"}).Return(nil, nil)
+	notificationRepoMock.On("Create", mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.UserID == "user1" && n.Type == domain.NotificationJoinRequestDeclined
+	})).Return(nil)
+
+	err := joinRequestService.Decline("room1", "owner1", "req1")
+	assert.Nil(t, err)
+	assert.Equal(t, domain.JoinRequestDeclined, request.Status)
+	membershipRepoMock.AssertNotCalled(t, "AddMember", mock.Anything)
+	notificationRepoMock.AssertExpectations(t)
+}
+
+// Test 6: A decided request cannot be decided again.
+func TestApproveJoinRequestAlreadyDecided(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	joinRequestRepoMock := new(mocks.RoomJoinRequestRepositoryMock)
+	notificationRepoMock := new(mocks.NotificationRepositoryMock)
+	chatStateRepoMock := new(mocks.ChatStateRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, new(mocks.RoomMessageRepositoryMock), new(mocks.UserRepositoryMock), new(mocks.RoomAuditRepositoryMock), new(mocks.RoomRestrictionRepositoryMock), new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+	joinRequestService := NewJoinRequestService(roomRepoMock, membershipRepoMock, new(mocks.RoomRestrictionRepositoryMock), joinRequestRepoMock, notificationRepoMock, chatStateRepoMock, roomService)
+
+	request := &domain.RoomJoinRequest{ID: "req1", RoomID: "room1", UserID: "user1", Status: domain.JoinRequestDeclined}
+	membershipRepoMock.On("GetMembership", "room1", "owner1").Return(&domain.RoomMembership{Role: domain.RoleOwner}, nil)
+	joinRequestRepoMock.On("FindByID", "req1").Return(request, nil)
+
+	err := joinRequestService.Approve("room1", "owner1", "req1")
+	assert.EqualError(t, err, "join request already decided")
+	membershipRepoMock.AssertNotCalled(t, "AddMember", mock.Anything)
+}
+
+// Test 7: No notification is stored when the requester has muted the room.
+func TestDeclineJoinRequestMutedRoom(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	joinRequestRepoMock := new(mocks.RoomJoinRequestRepositoryMock)
+	notificationRepoMock := new(mocks.NotificationRepositoryMock)
+	chatStateRepoMock := new(mocks.ChatStateRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, new(mocks.RoomMessageRepositoryMock), new(mocks.UserRepositoryMock), new(mocks.RoomAuditRepositoryMock), new(mocks.RoomRestrictionRepositoryMock), new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+	joinRequestService := NewJoinRequestService(roomRepoMock, membershipRepoMock, new(mocks.RoomRestrictionRepositoryMock), joinRequestRepoMock, notificationRepoMock, chatStateRepoMock, roomService)
+
+	request := &domain.RoomJoinRequest{ID: "req1", RoomID: "room1", UserID: "user1", Status: domain.JoinRequestPending}
+	membershipRepoMock.On("GetMembership", "room1", "owner1").Return(&domain.RoomMembership{Role: domain.RoleOwner}, nil)
+	joinRequestRepoMock.On("FindByID", "req1").Return(request, nil)
+	joinRequestRepoMock.On("UpdateStatus", request).Return(nil)
+	chatStateRepoMock.On("Get", "user1", domain.ChatRef{Kind: domain.ChatRoom, ID: "room1"}).
+		Return(&domain.ChatState{MutedUntil: &domain.MutedForever}, nil)
+
+	err := joinRequestService.Decline("room1", "owner1", "req1")
+	assert.Nil(t, err)
+	notificationRepoMock.AssertNotCalled(t, "Create", mock.Anything)
+}
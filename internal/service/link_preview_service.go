@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"social_media/internal/domain"
+	"social_media/pkg/linkpreview"
+)
+
+const (
+	linkPreviewBatch   = 20             // pending messages handled per chat type and run
+	linkPreviewWorkers = 4              // pages fetched at the same time
+	linkPreviewTTL     = 24 * time.Hour // cached previews are refetched after this
+	linkPreviewTimeout = 10 * time.Second
+)
+
+// LinkPreviewService generates previews for links in messages. Sending a message
+// only records its link; previews are built in the background so sending never
+// waits on a remote site.
+type LinkPreviewService interface {
+	// GeneratePending builds the previews of messages awaiting one and returns
+	// how many messages it updated.
+	GeneratePending() (int, error)
+}
+
+type linkPreviewService struct {
+	messageRepo     domain.MessageRepository
+	roomMessageRepo domain.RoomMessageRepository
+	previewRepo     domain.LinkPreviewRepository
+	fetcher         linkpreview.Fetcher
+}
+
+// NewLinkPreviewService creates a new instance of LinkPreviewService.
+func NewLinkPreviewService(
+	messageRepo domain.MessageRepository,
+	roomMessageRepo domain.RoomMessageRepository,
+	previewRepo domain.LinkPreviewRepository,
+	fetcher linkpreview.Fetcher,
+) LinkPreviewService {
+	return &linkPreviewService{
+		messageRepo:     messageRepo,
+		roomMessageRepo: roomMessageRepo,
+		previewRepo:     previewRepo,
+		fetcher:         fetcher,
+	}
+}
+
+func (s *linkPreviewService) GeneratePending() (int, error) {
+	messages, err := s.messageRepo.FindPendingPreviews(linkPreviewBatch)
+	if err != nil {
+		return 0, err
+	}
+	roomMessages, err := s.roomMessageRepo.FindPendingPreviews(linkPreviewBatch)
+	if err != nil {
+		return 0, err
+	}
+	var links []string
+	for _, message := range messages {
+		links = append(links, message.PreviewURL)
+	}
+	for _, message := range roomMessages {
+		links = append(links, message.PreviewURL)
+	}
+	previews := s.previews(links)
+
+	// One message failing to update must not hold back the rest of the batch.
+	updated := 0
+	var firstErr error
+	for _, message := range messages {
+		if err := s.messageRepo.SetLinkPreview(message.ID, previews[message.PreviewURL]); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		updated++
+	}
+	for _, message := range roomMessages {
+		if err := s.roomMessageRepo.SetLinkPreview(message.ID, previews[message.PreviewURL]); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		updated++
+	}
+	return updated, firstErr
+}
+
+// previews resolves each distinct link, a few at a time. Links without a preview
+// map to nil, including links whose preview could not be looked up or cached:
+// their messages are then marked done rather than retried on every run.
+func (s *linkPreviewService) previews(links []string) map[string]*domain.LinkPreview {
+	previews := make(map[string]*domain.LinkPreview, len(links))
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	slots := make(chan struct{}, linkPreviewWorkers)
+	queued := make(map[string]bool, len(links))
+	for _, link := range links {
+		if queued[link] {
+			continue
+		}
+		queued[link] = true
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(link string) {
+			defer func() { <-slots; wg.Done() }()
+			preview, err := s.preview(link)
+			if err != nil {
+				log.Printf("Failed to build link preview for %q: %v", link, err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if !preview.IsEmpty() {
+				previews[link] = preview
+			}
+		}(link)
+	}
+	wg.Wait()
+	return previews
+}
+
+// preview returns the cached preview of link, fetching it when it is missing or
+// stale. Pages that cannot be fetched are cached as empty previews.
+func (s *linkPreviewService) preview(link string) (*domain.LinkPreview, error) {
+	cached, err := s.previewRepo.FindByURL(link)
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil && time.Since(cached.FetchedAt) < linkPreviewTTL {
+		return cached, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), linkPreviewTimeout)
+	defer cancel()
+	preview, err := s.fetcher.Fetch(ctx, link)
+	if err != nil {
+		preview = &domain.LinkPreview{}
+	}
+	preview.URL = link
+	preview.FetchedAt = time.Now()
+	if err := s.previewRepo.Save(preview); err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
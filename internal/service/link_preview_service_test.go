@@ -0,0 +1,101 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+	"social_media/internal/mocks"
+)
+
+// Test 1: A fresh cached preview is used for every message with the link, without fetching.
+func TestGeneratePendingUsesCache(t *testing.T) {
+	messageRepoMock := new(mocks.MessageRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	previewRepoMock := new(mocks.LinkPreviewRepositoryMock)
+	fetcherMock := new(mocks.LinkPreviewFetcherMock)
+	previewService := NewLinkPreviewService(messageRepoMock, roomMessageRepoMock, previewRepoMock, fetcherMock)
+
+	link := "https://example.com/post"
+	cached := &domain.LinkPreview{URL: link, Title: "Post", FetchedAt: time.Now().Add(-time.Hour)}
+	messageRepoMock.On("FindPendingPreviews", linkPreviewBatch).Return([]*domain.Message{{ID: "msg1", PreviewURL: link}}, nil)
+	roomMessageRepoMock.On("FindPendingPreviews", linkPreviewBatch).Return([]*domain.RoomMessage{{ID: "rmsg1", PreviewURL: link}}, nil)
+	previewRepoMock.On("FindByURL", link).Return(cached, nil).Once()
+	messageRepoMock.On("SetLinkPreview", "msg1", cached).Return(nil)
+	roomMessageRepoMock.On("SetLinkPreview", "rmsg1", cached).Return(nil)
+
+	updated, err := previewService.GeneratePending()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, updated)
+	fetcherMock.AssertNotCalled(t, "Fetch", mock.Anything, mock.Anything)
+	previewRepoMock.AssertExpectations(t)
+}
+
+// Test 2: Stale or missing previews are fetched and cached; failed fetches are cached empty
+// and leave the message without a preview.
+func TestGeneratePendingFetches(t *testing.T) {
+	messageRepoMock := new(mocks.MessageRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	previewRepoMock := new(mocks.LinkPreviewRepositoryMock)
+	fetcherMock := new(mocks.LinkPreviewFetcherMock)
+	previewService := NewLinkPreviewService(messageRepoMock, roomMessageRepoMock, previewRepoMock, fetcherMock)
+
+	good, broken := "https://example.com/good", "https://example.com/broken"
+	messageRepoMock.On("FindPendingPreviews", linkPreviewBatch).Return([]*domain.Message{{ID: "msg1", PreviewURL: good}}, nil)
+	roomMessageRepoMock.On("FindPendingPreviews", linkPreviewBatch).Return([]*domain.RoomMessage{{ID: "rmsg1", PreviewURL: broken}}, nil)
+	previewRepoMock.On("FindByURL", good).Return(&domain.LinkPreview{URL: good, Title: "Old", FetchedAt: time.Now().Add(-48 * time.Hour)}, nil)
+	previewRepoMock.On("FindByURL", broken).Return(nil, nil)
+	fetcherMock.On("Fetch", mock.Anything, good).Return(&domain.LinkPreview{Title: "New"}, nil)
+	fetcherMock.On("Fetch", mock.Anything, broken).Return(nil, errors.New("connection refused"))
+	previewRepoMock.On("Save", mock.MatchedBy(func(p *domain.LinkPreview) bool {
+		return p.URL == good && p.Title == "New" && !p.FetchedAt.IsZero()
+	})).Return(nil)
+	previewRepoMock.On("Save", mock.MatchedBy(func(p *domain.LinkPreview) bool {
+		return p.URL == broken && p.IsEmpty()
+	})).Return(nil)
+	messageRepoMock.On("SetLinkPreview", "msg1", mock.MatchedBy(func(p *domain.LinkPreview) bool {
+		return p != nil && p.Title == "New"
+	})).Return(nil)
+	roomMessageRepoMock.On("SetLinkPreview", "rmsg1", (*domain.LinkPreview)(nil)).Return(nil)
+
+	updated, err := previewService.GeneratePending()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, updated)
+	previewRepoMock.AssertNumberOfCalls(t, "Save", 2)
+	roomMessageRepoMock.AssertExpectations(t)
+}
+
+// Test 3: A link whose preview cannot be looked up leaves its message without a
+// preview and does not hold back the rest of the batch.
+func TestGeneratePendingLinkError(t *testing.T) {
+	messageRepoMock := new(mocks.MessageRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	previewRepoMock := new(mocks.LinkPreviewRepositoryMock)
+	fetcherMock := new(mocks.LinkPreviewFetcherMock)
+	previewService := NewLinkPreviewService(messageRepoMock, roomMessageRepoMock, previewRepoMock, fetcherMock)
+
+	good, bad := "https://example.com/good", "https://example.com/bad"
+	cached := &domain.LinkPreview{URL: good, Title: "Good", FetchedAt: time.Now()}
+	messageRepoMock.On("FindPendingPreviews", linkPreviewBatch).Return([]*domain.Message{{ID: "msg1", PreviewURL: bad}, {ID: "msg2", PreviewURL: good}}, nil)
+	roomMessageRepoMock.On("FindPendingPreviews", linkPreviewBatch).Return([]*domain.RoomMessage{}, nil)
+	previewRepoMock.On("FindByURL", bad).Return(nil, errors.New("index row size exceeds maximum"))
+	previewRepoMock.On("FindByURL", good).Return(cached, nil)
+	messageRepoMock.On("SetLinkPreview", "msg1", (*domain.LinkPreview)(nil)).Return(nil)
+	messageRepoMock.On("SetLinkPreview", "msg2", cached).Return(nil)
+
+	updated, err := previewService.GeneratePending()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, updated)
+	messageRepoMock.AssertExpectations(t)
+}
+
+// Test 4: Links longer than the limit are not queued for a preview.
+func TestPreviewURLTooLong(t *testing.T) {
+	long := "https://example.com/" + strings.Repeat("a", domain.MaxPreviewURLLength)
+	assert.Equal(t, "", domain.PreviewURL("see "+long, nil))
+	assert.Equal(t, "https://example.com/b", domain.PreviewURL(long+" or https://example.com/b", nil))
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"time"
+
+	"social_media/internal/domain"
+)
+
+// NotificationService defines the operations on a user's notifications.
+type NotificationService interface {
+	GetNotifications(userID string, unreadOnly bool, limit, offset int) ([]*domain.Notification, error)
+	MarkRead(userID, notificationID string) error
+}
+
+type notificationService struct {
+	notificationRepo domain.NotificationRepository
+}
+
+// NewNotificationService creates a new instance of NotificationService.
+func NewNotificationService(notificationRepo domain.NotificationRepository) NotificationService {
+	return &notificationService{notificationRepo: notificationRepo}
+}
+
+// GetNotifications returns a page of the user's notifications, newest first.
+func (s *notificationService) GetNotifications(userID string, unreadOnly bool, limit, offset int) ([]*domain.Notification, error) {
+	limit, offset = normalizePage(limit, offset)
+	return s.notificationRepo.FindByUser(userID, unreadOnly, limit, offset)
+}
+
+// MarkRead marks one of the user's notifications as read.
+func (s *notificationService) MarkRead(userID, notificationID string) error {
+	return s.notificationRepo.MarkRead(userID, notificationID)
+}
+
+// deliverNotification stores the notification unless it concerns a room the
+// recipient has muted.
+func deliverNotification(notificationRepo domain.NotificationRepository, chatStateRepo domain.ChatStateRepository, notification *domain.Notification) error {
+	if notification.RoomID != nil {
+		state, err := chatStateRepo.Get(notification.UserID, domain.ChatRef{Kind: domain.ChatRoom, ID: *notification.RoomID})
+		if err != nil {
+			return err
+		}
+		if state != nil && state.Muted(time.Now()) {
+			return nil
+		}
+	}
+	return notificationRepo.Create(notification)
+}
+
+// deliverRoomNotifications stores notifications about a room in one batch,
+// skipping recipients who have muted the room.
+func deliverRoomNotifications(notificationRepo domain.NotificationRepository, chatStateRepo domain.ChatStateRepository, roomID string, notifications []*domain.Notification) error {
+	userIDs := make([]string, len(notifications))
+	for i, notification := range notifications {
+		userIDs[i] = notification.UserID
+	}
+	muted, err := chatStateRepo.FindMuted(domain.ChatRef{Kind: domain.ChatRoom, ID: roomID}, userIDs, time.Now())
+	if err != nil {
+		return err
+	}
+	skip := make(map[string]bool, len(muted))
+	for _, userID := range muted {
+		skip[userID] = true
+	}
+	var deliver []*domain.Notification
+	for _, notification := range notifications {
+		if !skip[notification.UserID] {
+			deliver = append(deliver, notification)
+		}
+	}
+	if len(deliver) == 0 {
+		return nil
+	}
+	return notificationRepo.CreateBatch(deliver)
+}
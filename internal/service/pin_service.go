@@ -0,0 +1,240 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"social_media/internal/domain"
+)
+
+const maxPinnedMessages = 50
+
+var (
+	ErrMessageNotFound = errors.New("message not found")
+	ErrAlreadyPinned   = errors.New("message is already pinned")
+	ErrNotPinned       = errors.New("message is not pinned")
+)
+
+// PinService pins messages to the top of conversations and rooms. Any participant
+// may pin in a conversation; in rooms it takes the pin_messages admin permission.
+type PinService interface {
+	// PinMessage adds the message below the existing pins and records the event in the chat history.
+	PinMessage(userID string, chat domain.ChatRef, messageID string) (*domain.PinnedMessage, error)
+	UnpinMessage(userID string, chat domain.ChatRef, messageID string) error
+	// ReorderPins sets the pin order; messageIDs must list every pinned message exactly once.
+	ReorderPins(userID string, chat domain.ChatRef, messageIDs []string) error
+	GetPins(userID string, chat domain.ChatRef) ([]*domain.PinnedMessage, error)
+}
+
+type pinService struct {
+	convoRepo       domain.ConversationRepository
+	messageRepo     domain.MessageRepository
+	roomRepo        domain.RoomRepository
+	membershipRepo  domain.RoomMembershipRepository
+	restrictionRepo domain.RoomRestrictionRepository
+	roomMessageRepo domain.RoomMessageRepository
+	auditRepo       domain.RoomAuditRepository
+	pinRepo         domain.PinRepository
+}
+
+// NewPinService creates a new instance of PinService.
+func NewPinService(
+	convoRepo domain.ConversationRepository,
+	messageRepo domain.MessageRepository,
+	roomRepo domain.RoomRepository,
+	membershipRepo domain.RoomMembershipRepository,
+	restrictionRepo domain.RoomRestrictionRepository,
+	roomMessageRepo domain.RoomMessageRepository,
+	auditRepo domain.RoomAuditRepository,
+	pinRepo domain.PinRepository,
+) PinService {
+	return &pinService{
+		convoRepo:       convoRepo,
+		messageRepo:     messageRepo,
+		roomRepo:        roomRepo,
+		membershipRepo:  membershipRepo,
+		restrictionRepo: restrictionRepo,
+		roomMessageRepo: roomMessageRepo,
+		auditRepo:       auditRepo,
+		pinRepo:         pinRepo,
+	}
+}
+
+func (s *pinService) PinMessage(userID string, chat domain.ChatRef, messageID string) (*domain.PinnedMessage, error) {
+	if err := s.checkCanPin(userID, chat); err != nil {
+		return nil, err
+	}
+	pin, err := s.pinnable(chat, messageID)
+	if err != nil {
+		return nil, err
+	}
+	pins, err := s.pinRepo.FindByChat(chat)
+	if err != nil {
+		return nil, err
+	}
+	if len(pins) >= maxPinnedMessages {
+		return nil, fmt.Errorf("cannot pin more than %d messages", maxPinnedMessages)
+	}
+	for _, existing := range pins {
+		if existing.MessageID == messageID {
+			return nil, ErrAlreadyPinned
+		}
+		if existing.Position >= pin.Position {
+			pin.Position = existing.Position + 1
+		}
+	}
+	pin.PinnedBy = userID
+	pin.PinnedAt = time.Now()
+	if err := s.pinRepo.Add(pin); err != nil {
+		return nil, err
+	}
+	if err := s.recordPin(userID, chat, messageID); err != nil {
+		return nil, err
+	}
+	if chat.Kind == domain.ChatRoom {
+		if err := appendAudit(s.auditRepo, chat.ID, userID, domain.AuditPinMessage, messageID, nil, pin); err != nil {
+			return nil, err
+		}
+	}
+	return pin, nil
+}
+
+// pinnable loads a message of the chat that may be pinned, as an unsaved pin.
+func (s *pinService) pinnable(chat domain.ChatRef, messageID string) (*domain.PinnedMessage, error) {
+	pin := &domain.PinnedMessage{Chat: chat, MessageID: messageID}
+	var kind domain.MessageKind
+	switch chat.Kind {
+	case domain.ChatConversation:
+		message, err := s.messageRepo.FindByID(messageID)
+		if err != nil {
+			return nil, err
+		}
+		if message == nil || message.ConversationID != chat.ID {
+			return nil, ErrMessageNotFound
+		}
+		pin.SenderID, pin.Content, kind = message.SenderID, message.Content, message.Kind
+	case domain.ChatRoom:
+		message, err := s.roomMessageRepo.FindByID(messageID)
+		if err != nil {
+			return nil, err
+		}
+		if message == nil || message.RoomID != chat.ID {
+			return nil, ErrMessageNotFound
+		}
+		pin.SenderID, pin.Content, kind = message.SenderID, message.Content, message.Kind
+	}
+	if kind.IsService() {
+		return nil, errors.New("service messages cannot be pinned")
+	}
+	return pin, nil
+}
+
+// recordPin adds a service message announcing the pin to the chat history.
+func (s *pinService) recordPin(userID string, chat domain.ChatRef, messageID string) error {
+	now := time.Now()
+	if chat.Kind == domain.ChatRoom {
+		return s.roomMessageRepo.Create(&domain.RoomMessage{
+			ID:          uuid.New().String(),
+			RoomID:      chat.ID,
+			SenderID:    userID,
+			Kind:        domain.MessageKindPinned,
+			ReferenceID: &messageID,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+	}
+	return s.messageRepo.Create(&domain.Message{
+		ID:             uuid.New().String(),
+		ConversationID: chat.ID,
+		SenderID:       userID,
+		Kind:           domain.MessageKindPinned,
+		ReferenceID:    &messageID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	})
+}
+
+func (s *pinService) UnpinMessage(userID string, chat domain.ChatRef, messageID string) error {
+	if err := s.checkCanPin(userID, chat); err != nil {
+		return err
+	}
+	pins, err := s.pinRepo.FindByChat(chat)
+	if err != nil {
+		return err
+	}
+	var pin *domain.PinnedMessage
+	for _, existing := range pins {
+		if existing.MessageID == messageID {
+			pin = existing
+		}
+	}
+	if pin == nil {
+		return ErrNotPinned
+	}
+	if err := s.pinRepo.Remove(chat, messageID); err != nil {
+		return err
+	}
+	if chat.Kind == domain.ChatRoom {
+		return appendAudit(s.auditRepo, chat.ID, userID, domain.AuditUnpinMessage, messageID, pin, nil)
+	}
+	return nil
+}
+
+func (s *pinService) ReorderPins(userID string, chat domain.ChatRef, messageIDs []string) error {
+	if err := s.checkCanPin(userID, chat); err != nil {
+		return err
+	}
+	pins, err := s.pinRepo.FindByChat(chat)
+	if err != nil {
+		return err
+	}
+	pinned := make(map[string]bool, len(pins))
+	for _, pin := range pins {
+		pinned[pin.MessageID] = true
+	}
+	if len(messageIDs) != len(pinned) {
+		return errors.New("order must list every pinned message exactly once")
+	}
+	seen := make(map[string]bool, len(messageIDs))
+	for _, messageID := range messageIDs {
+		if !pinned[messageID] || seen[messageID] {
+			return errors.New("order must list every pinned message exactly once")
+		}
+		seen[messageID] = true
+	}
+	return s.pinRepo.Reorder(chat, messageIDs)
+}
+
+// GetPins lists a chat's pins to anyone who can read the chat.
+func (s *pinService) GetPins(userID string, chat domain.ChatRef) ([]*domain.PinnedMessage, error) {
+	if err := checkReadAccess(s.convoRepo, s.roomRepo, s.membershipRepo, s.restrictionRepo, userID, chat); err != nil {
+		return nil, err
+	}
+	return s.pinRepo.FindByChat(chat)
+}
+
+// checkCanPin checks that the user may change the chat's pins.
+func (s *pinService) checkCanPin(userID string, chat domain.ChatRef) error {
+	switch chat.Kind {
+	case domain.ChatConversation:
+		return s.checkParticipant(userID, chat.ID)
+	case domain.ChatRoom:
+		_, err := requirePermission(s.membershipRepo, chat.ID, userID, domain.PermPinMessages, "not authorized to pin messages")
+		return err
+	default:
+		return ErrChatNotFound
+	}
+}
+
+func (s *pinService) checkParticipant(userID, convoID string) error {
+	convo, err := s.convoRepo.FindByID(convoID)
+	if err != nil {
+		return err
+	}
+	if convo == nil || !convo.HasParticipant(userID) {
+		return ErrChatNotFound
+	}
+	return nil
+}
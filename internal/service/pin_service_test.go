@@ -0,0 +1,104 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+	"social_media/internal/mocks"
+)
+
+// Test 1: An admin with the pin permission pins a room message below the existing
+// pins; the pin is announced in the history and recorded in the audit log.
+func TestPinRoomMessage(t *testing.T) {
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	pinRepoMock := new(mocks.PinRepositoryMock)
+	pinService := NewPinService(new(mocks.ConversationRepositoryMock), new(mocks.MessageRepositoryMock), new(mocks.RoomRepositoryMock),
+		membershipRepoMock, new(mocks.RoomRestrictionRepositoryMock), roomMessageRepoMock, auditRepoMock, pinRepoMock)
+
+	chat := domain.ChatRef{Kind: domain.ChatRoom, ID: "room1"}
+	membershipRepoMock.On("GetMembership", "room1", "admin1").Return(&domain.RoomMembership{Role: domain.RoleAdmin, Permissions: domain.PermPinMessages}, nil)
+	roomMessageRepoMock.On("FindByID", "msg2").Return(&domain.RoomMessage{ID: "msg2", RoomID: "room1", SenderID: "user1", Content: "Read the rules", Kind: domain.MessageKindText}, nil)
+	pinRepoMock.On("FindByChat", chat).Return([]*domain.PinnedMessage{{Chat: chat, MessageID: "msg1", Position: 3}}, nil)
+	pinRepoMock.On("Add", mock.MatchedBy(func(p *domain.PinnedMessage) bool {
+		return p.MessageID == "msg2" && p.Position == 4 && p.PinnedBy == "admin1"
+	})).Return(nil)
+	roomMessageRepoMock.On("Create", mock.MatchedBy(func(m *domain.RoomMessage) bool {
+		return m.Kind == domain.MessageKindPinned && m.ReferenceID != nil && *m.ReferenceID == "msg2"
+	})).Return(nil)
+	auditRepoMock.On("Append", mock.MatchedBy(func(e *domain.RoomAuditEntry) bool {
+		return e.Action == domain.AuditPinMessage && e.TargetID != nil && *e.TargetID == "msg2"
+	})).Return(nil)
+
+	pin, err := pinService.PinMessage("admin1", chat, "msg2")
+	assert.Nil(t, err)
+	assert.Equal(t, "Read the rules", pin.Content)
+	roomMessageRepoMock.AssertExpectations(t)
+	auditRepoMock.AssertExpectations(t)
+}
+
+// Test 2: Admins without the pin permission cannot pin in rooms.
+func TestPinRoomMessageWithoutPermission(t *testing.T) {
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	pinRepoMock := new(mocks.PinRepositoryMock)
+	pinService := NewPinService(new(mocks.ConversationRepositoryMock), new(mocks.MessageRepositoryMock), new(mocks.RoomRepositoryMock),
+		membershipRepoMock, new(mocks.RoomRestrictionRepositoryMock), new(mocks.RoomMessageRepositoryMock), new(mocks.RoomAuditRepositoryMock), pinRepoMock)
+
+	membershipRepoMock.On("GetMembership", "room1", "admin1").Return(&domain.RoomMembership{Role: domain.RoleAdmin, Permissions: domain.PermDeleteMessages}, nil)
+
+	_, err := pinService.PinMessage("admin1", domain.ChatRef{Kind: domain.ChatRoom, ID: "room1"}, "msg1")
+	assert.ErrorIs(t, err, ErrForbidden)
+	pinRepoMock.AssertNotCalled(t, "Add", mock.Anything)
+}
+
+// Test 3: A conversation participant cannot pin the same message twice.
+func TestPinConversationMessageAlreadyPinned(t *testing.T) {
+	convoRepoMock := new(mocks.ConversationRepositoryMock)
+	messageRepoMock := new(mocks.MessageRepositoryMock)
+	pinRepoMock := new(mocks.PinRepositoryMock)
+	pinService := NewPinService(convoRepoMock, messageRepoMock, new(mocks.RoomRepositoryMock),
+		new(mocks.RoomMembershipRepositoryMock), new(mocks.RoomRestrictionRepositoryMock), new(mocks.RoomMessageRepositoryMock), new(mocks.RoomAuditRepositoryMock), pinRepoMock)
+
+	chat := domain.ChatRef{Kind: domain.ChatConversation, ID: "c1"}
+	convoRepoMock.On("FindByID", "c1").Return(&domain.Conversation{ID: "c1", Participants: []string{"user1", "user2"}}, nil)
+	messageRepoMock.On("FindByID", "msg1").Return(&domain.Message{ID: "msg1", ConversationID: "c1", SenderID: "user2", Kind: domain.MessageKindText}, nil)
+	pinRepoMock.On("FindByChat", chat).Return([]*domain.PinnedMessage{{Chat: chat, MessageID: "msg1", PinnedAt: time.Now()}}, nil)
+
+	_, err := pinService.PinMessage("user1", chat, "msg1")
+	assert.ErrorIs(t, err, ErrAlreadyPinned)
+}
+
+// Test 4: Reordering must list every pinned message exactly once.
+func TestReorderPinsIncomplete(t *testing.T) {
+	convoRepoMock := new(mocks.ConversationRepositoryMock)
+	pinRepoMock := new(mocks.PinRepositoryMock)
+	pinService := NewPinService(convoRepoMock, new(mocks.MessageRepositoryMock), new(mocks.RoomRepositoryMock),
+		new(mocks.RoomMembershipRepositoryMock), new(mocks.RoomRestrictionRepositoryMock), new(mocks.RoomMessageRepositoryMock), new(mocks.RoomAuditRepositoryMock), pinRepoMock)
+
+	chat := domain.ChatRef{Kind: domain.ChatConversation, ID: "c1"}
+	convoRepoMock.On("FindByID", "c1").Return(&domain.Conversation{ID: "c1", Participants: []string{"user1", "user2"}}, nil)
+	pinRepoMock.On("FindByChat", chat).Return([]*domain.PinnedMessage{{MessageID: "msg1"}, {MessageID: "msg2"}}, nil)
+
+	err := pinService.ReorderPins("user1", chat, []string{"msg2", "msg2"})
+	assert.NotNil(t, err)
+	pinRepoMock.AssertNotCalled(t, "Reorder", mock.Anything, mock.Anything)
+}
+
+// Test 5: Pins of a private room are hidden from non-members.
+func TestGetPinsPrivateRoomNonMember(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	pinService := NewPinService(new(mocks.ConversationRepositoryMock), new(mocks.MessageRepositoryMock), roomRepoMock,
+		membershipRepoMock, new(mocks.RoomRestrictionRepositoryMock), new(mocks.RoomMessageRepositoryMock), new(mocks.RoomAuditRepositoryMock), new(mocks.PinRepositoryMock))
+
+	roomRepoMock.On("FindByID", "room1").Return(&domain.Room{ID: "room1"}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user1").Return(nil, nil)
+
+	pins, err := pinService.GetPins("user1", domain.ChatRef{Kind: domain.ChatRoom, ID: "room1"})
+	assert.ErrorIs(t, err, ErrChatNotFound)
+	assert.Nil(t, pins)
+}
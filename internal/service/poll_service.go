@@ -0,0 +1,339 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"social_media/internal/domain"
+)
+
+// pollUpdateBuffer is how many updates a subscriber may fall behind by before
+// further updates are dropped for it.
+const pollUpdateBuffer = 16
+
+var (
+	ErrPollNotFound = errors.New("poll not found")
+	ErrPollClosed   = domain.ErrPollClosed
+	ErrQuizAnswered = domain.ErrQuizAnswered
+	ErrNotVoted     = errors.New("you have not voted in this poll")
+)
+
+// PollService manages polls posted in rooms. Tally changes are pushed to the
+// room's subscribers.
+type PollService interface {
+	// CreatePoll posts a poll to the room under the same rules as sending a message.
+	CreatePoll(roomID, senderID string, poll *domain.Poll) (*domain.RoomMessage, error)
+	GetPoll(roomID, messageID, userID string) (*domain.Poll, error)
+	// Vote replaces the user's choice. Quiz answers are final.
+	Vote(roomID, messageID, userID string, options []int) (*domain.Poll, error)
+	RetractVote(roomID, messageID, userID string) (*domain.Poll, error)
+	// ClosePoll ends voting; it is open to the poll's author and to admins allowed to delete messages.
+	ClosePoll(roomID, messageID, userID string) (*domain.Poll, error)
+	// CloseExpired closes polls whose deadline passed and returns how many it closed.
+	CloseExpired() (int, error)
+	// Subscribe streams updated polls of the room until the returned cancel func is called.
+	Subscribe(roomID, userID string) (<-chan *domain.Poll, func(), error)
+}
+
+type pollService struct {
+	roomRepo        domain.RoomRepository
+	membershipRepo  domain.RoomMembershipRepository
+	restrictionRepo domain.RoomRestrictionRepository
+	roomMessageRepo domain.RoomMessageRepository
+	pollRepo        domain.PollRepository
+	roomService     RoomService
+	updates         *pollUpdates
+}
+
+// NewPollService creates a new instance of PollService.
+func NewPollService(
+	roomRepo domain.RoomRepository,
+	membershipRepo domain.RoomMembershipRepository,
+	restrictionRepo domain.RoomRestrictionRepository,
+	roomMessageRepo domain.RoomMessageRepository,
+	pollRepo domain.PollRepository,
+	roomService RoomService,
+) PollService {
+	return &pollService{
+		roomRepo:        roomRepo,
+		membershipRepo:  membershipRepo,
+		restrictionRepo: restrictionRepo,
+		roomMessageRepo: roomMessageRepo,
+		pollRepo:        pollRepo,
+		roomService:     roomService,
+		updates:         &pollUpdates{subscribers: make(map[string]map[chan *domain.Poll]struct{})},
+	}
+}
+
+func (s *pollService) CreatePoll(roomID, senderID string, poll *domain.Poll) (*domain.RoomMessage, error) {
+	return s.roomService.SendPoll(roomID, senderID, poll)
+}
+
+func (s *pollService) GetPoll(roomID, messageID, userID string) (*domain.Poll, error) {
+	// Only room access is checked, so no conversation repository is needed.
+	if err := checkReadAccess(nil, s.roomRepo, s.membershipRepo, s.restrictionRepo, userID, domain.ChatRef{Kind: domain.ChatRoom, ID: roomID}); err != nil {
+		return nil, err
+	}
+	poll, err := s.findPoll(roomID, messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+	hideQuizAnswer(poll, time.Now())
+	return poll, nil
+}
+
+func (s *pollService) Vote(roomID, messageID, userID string, options []int) (*domain.Poll, error) {
+	poll, err := s.openPoll(roomID, messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if options, err = normalizeVote(poll, options); err != nil {
+		return nil, err
+	}
+	if poll.Quiz && len(poll.MyVotes) > 0 {
+		return nil, ErrQuizAnswered
+	}
+	if err := s.pollRepo.SetVotes(messageID, userID, options); err != nil {
+		return nil, err
+	}
+	return s.publish(messageID, userID)
+}
+
+func (s *pollService) RetractVote(roomID, messageID, userID string) (*domain.Poll, error) {
+	poll, err := s.openPoll(roomID, messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(poll.MyVotes) == 0 {
+		return nil, ErrNotVoted
+	}
+	if poll.Quiz {
+		return nil, ErrQuizAnswered
+	}
+	if err := s.pollRepo.SetVotes(messageID, userID, nil); err != nil {
+		return nil, err
+	}
+	return s.publish(messageID, userID)
+}
+
+func (s *pollService) ClosePoll(roomID, messageID, userID string) (*domain.Poll, error) {
+	poll, err := s.findPoll(roomID, messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if poll.IsClosed(time.Now()) {
+		return nil, ErrPollClosed
+	}
+	message, err := s.roomMessageRepo.FindByID(messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message == nil || message.SenderID != userID {
+		if _, err := requirePermission(s.membershipRepo, roomID, userID, domain.PermDeleteMessages, "not authorized to close this poll"); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.pollRepo.Close(messageID, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.publish(messageID, userID)
+}
+
+func (s *pollService) CloseExpired() (int, error) {
+	messageIDs, err := s.pollRepo.CloseExpired(time.Now())
+	if err != nil {
+		return 0, err
+	}
+	for _, messageID := range messageIDs {
+		if _, err := s.publish(messageID, ""); err != nil {
+			return 0, err
+		}
+	}
+	return len(messageIDs), nil
+}
+
+func (s *pollService) Subscribe(roomID, userID string) (<-chan *domain.Poll, func(), error) {
+	if err := checkReadAccess(nil, s.roomRepo, s.membershipRepo, s.restrictionRepo, userID, domain.ChatRef{Kind: domain.ChatRoom, ID: roomID}); err != nil {
+		return nil, nil, err
+	}
+	updates, cancel := s.updates.subscribe(roomID)
+	return updates, cancel, nil
+}
+
+// findPoll loads a poll of the room as seen by userID.
+func (s *pollService) findPoll(roomID, messageID, userID string) (*domain.Poll, error) {
+	poll, err := s.pollRepo.FindByMessage(messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if poll == nil || poll.RoomID != roomID {
+		return nil, ErrPollNotFound
+	}
+	return poll, nil
+}
+
+// openPoll loads a poll userID may vote in: they must be a member of the room
+// who is not banned, and the poll must still be open.
+func (s *pollService) openPoll(roomID, messageID, userID string) (*domain.Poll, error) {
+	membership, err := roomMembership(s.membershipRepo, s.restrictionRepo, roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if membership == nil {
+		return nil, ErrNotRoomMember
+	}
+	if membership.Role == domain.RoleBanned {
+		return nil, ErrBanned
+	}
+	poll, err := s.findPoll(roomID, messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if poll.IsClosed(time.Now()) {
+		return nil, ErrPollClosed
+	}
+	return poll, nil
+}
+
+// publish reloads the poll, pushes it to the room's subscribers and returns it
+// as seen by viewerID.
+func (s *pollService) publish(messageID, viewerID string) (*domain.Poll, error) {
+	poll, err := s.pollRepo.FindByMessage(messageID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if poll == nil {
+		return nil, ErrPollNotFound
+	}
+	now := time.Now()
+	update := *poll
+	update.MyVotes = nil
+	hideQuizAnswer(&update, now)
+	s.updates.publish(&update)
+
+	hideQuizAnswer(poll, now)
+	return poll, nil
+}
+
+// hideQuizAnswer removes the correct answer of an open quiz the viewer has not answered.
+func hideQuizAnswer(poll *domain.Poll, now time.Time) {
+	if poll.Quiz && len(poll.MyVotes) == 0 && !poll.IsClosed(now) {
+		poll.CorrectOption = nil
+	}
+}
+
+// validatePoll trims a new poll's text and checks its settings. Tallies and
+// state supplied by the client are discarded.
+func validatePoll(poll *domain.Poll, now time.Time) error {
+	poll.Question = strings.TrimSpace(poll.Question)
+	if poll.Question == "" {
+		return errors.New("poll question cannot be empty")
+	}
+	if len([]rune(poll.Question)) > domain.MaxPollQuestionRunes {
+		return fmt.Errorf("poll question must be at most %d characters", domain.MaxPollQuestionRunes)
+	}
+	if len(poll.Options) < 2 || len(poll.Options) > domain.MaxPollOptions {
+		return fmt.Errorf("a poll needs between 2 and %d options", domain.MaxPollOptions)
+	}
+	seen := make(map[string]bool, len(poll.Options))
+	for i, option := range poll.Options {
+		text := strings.TrimSpace(option.Text)
+		if text == "" {
+			return errors.New("poll options cannot be empty")
+		}
+		if len([]rune(text)) > domain.MaxPollOptionRunes {
+			return fmt.Errorf("poll options must be at most %d characters", domain.MaxPollOptionRunes)
+		}
+		if seen[strings.ToLower(text)] {
+			return errors.New("poll options must be different")
+		}
+		seen[strings.ToLower(text)] = true
+		poll.Options[i] = domain.PollOption{Text: text}
+	}
+	if poll.Quiz {
+		if poll.MultipleChoice {
+			return errors.New("a quiz has a single correct answer")
+		}
+		if poll.CorrectOption == nil || *poll.CorrectOption < 0 || *poll.CorrectOption >= len(poll.Options) {
+			return errors.New("a quiz needs a valid correct option")
+		}
+	} else if poll.CorrectOption != nil {
+		return errors.New("only quizzes have a correct option")
+	}
+	if poll.CloseAt != nil && !poll.CloseAt.After(now) {
+		return errors.New("close_at must be in the future")
+	}
+	poll.TotalVoters, poll.ClosedAt, poll.MyVotes = 0, nil, nil
+	return nil
+}
+
+// normalizeVote checks a vote against the poll and returns its options sorted
+// without duplicates.
+func normalizeVote(poll *domain.Poll, options []int) ([]int, error) {
+	if len(options) == 0 {
+		return nil, errors.New("choose at least one option")
+	}
+	seen := make(map[int]bool, len(options))
+	var normalized []int
+	for _, option := range options {
+		if option < 0 || option >= len(poll.Options) {
+			return nil, fmt.Errorf("invalid option %d", option)
+		}
+		if !seen[option] {
+			seen[option] = true
+			normalized = append(normalized, option)
+		}
+	}
+	if !poll.MultipleChoice && len(normalized) > 1 {
+		return nil, errors.New("this poll allows a single choice")
+	}
+	sort.Ints(normalized)
+	return normalized, nil
+}
+
+// pollUpdates fans poll changes out to the subscribers of each room.
+type pollUpdates struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan *domain.Poll]struct{}
+}
+
+func (u *pollUpdates) subscribe(roomID string) (chan *domain.Poll, func()) {
+	updates := make(chan *domain.Poll, pollUpdateBuffer)
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.subscribers[roomID] == nil {
+		u.subscribers[roomID] = make(map[chan *domain.Poll]struct{})
+	}
+	u.subscribers[roomID][updates] = struct{}{}
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			u.mu.Lock()
+			defer u.mu.Unlock()
+			delete(u.subscribers[roomID], updates)
+			if len(u.subscribers[roomID]) == 0 {
+				delete(u.subscribers, roomID)
+			}
+			close(updates)
+		})
+	}
+	return updates, cancel
+}
+
+// publish never blocks: a subscriber that is behind misses the update, and the
+// next one carries the full tallies anyway.
+func (u *pollUpdates) publish(poll *domain.Poll) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for updates := range u.subscribers[poll.RoomID] {
+		select {
+		case updates <- poll:
+		default:
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+	"social_media/internal/mocks"
+)
+
+// Test 1: A member posts a quiz; its text is trimmed and it is stored as a poll message.
+func TestCreateQuizPoll(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, new(mocks.UserRepositoryMock), new(mocks.RoomAuditRepositoryMock), restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+	pollService := NewPollService(roomRepoMock, membershipRepoMock, restrictionRepoMock, roomMessageRepoMock, new(mocks.PollRepositoryMock), roomService)
+
+	membershipRepoMock.On("GetMembership", "room1", "user1").Return(&domain.RoomMembership{RoomID: "room1", UserID: "user1", Role: domain.RoleMember}, nil)
+	restrictionRepoMock.On("Find", "room1", "user1", domain.RestrictionMute).Return(nil, nil)
+	roomRepoMock.On("FindByID", "room1").Return(&domain.Room{ID: "room1", Type: domain.RoomTypeGroup}, nil)
+	roomMessageRepoMock.On("Create", mock.MatchedBy(func(m *domain.RoomMessage) bool {
+		return m.Kind == domain.MessageKindPoll && m.Content == "Capital of France?" && m.Poll != nil && m.Poll.MessageID == m.ID
+	})).Return(nil)
+
+	correct := 1
+	message, err := pollService.CreatePoll("room1", "user1", &domain.Poll{
+		Question:      "  Capital of France? ",
+		Options:       []domain.PollOption{{Text: "Lyon"}, {Text: " Paris "}},
+		Quiz:          true,
+		CorrectOption: &correct,
+		TotalVoters:   42,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "Paris", message.Poll.Options[1].Text)
+	assert.Equal(t, 0, message.Poll.TotalVoters)
+	roomMessageRepoMock.AssertExpectations(t)
+}
+
+// Test 2: Polls with duplicate options, or quizzes without a correct option, are rejected.
+func TestCreatePollInvalid(t *testing.T) {
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	roomService := NewRoomService(new(mocks.RoomRepositoryMock), new(mocks.RoomMembershipRepositoryMock), roomMessageRepoMock, new(mocks.UserRepositoryMock), new(mocks.RoomAuditRepositoryMock), new(mocks.RoomRestrictionRepositoryMock), new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+	pollService := NewPollService(new(mocks.RoomRepositoryMock), new(mocks.RoomMembershipRepositoryMock), new(mocks.RoomRestrictionRepositoryMock), roomMessageRepoMock, new(mocks.PollRepositoryMock), roomService)
+
+	_, err := pollService.CreatePoll("room1", "user1", &domain.Poll{
+		Question: "Lunch?",
+		Options:  []domain.PollOption{{Text: "Pizza"}, {Text: "pizza"}},
+	})
+	assert.NotNil(t, err)
+
+	_, err = pollService.CreatePoll("room1", "user1", &domain.Poll{
+		Question: "2 + 2?",
+		Options:  []domain.PollOption{{Text: "4"}, {Text: "5"}},
+		Quiz:     true,
+	})
+	assert.NotNil(t, err)
+	roomMessageRepoMock.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// Test 3: A vote is stored with its options sorted and pushed to the room's
+// subscribers, who do not see the voter's choice or the quiz answer.
+func TestVotePublishesUpdate(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	pollRepoMock := new(mocks.PollRepositoryMock)
+	pollService := NewPollService(roomRepoMock, membershipRepoMock, new(mocks.RoomRestrictionRepositoryMock), new(mocks.RoomMessageRepositoryMock), pollRepoMock, nil)
+
+	correct := 2
+	roomRepoMock.On("FindByID", "room1").Return(&domain.Room{ID: "room1", Type: domain.RoomTypeGroup}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user1").Return(&domain.RoomMembership{Role: domain.RoleMember}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user2").Return(&domain.RoomMembership{Role: domain.RoleMember}, nil)
+	options := []domain.PollOption{{Text: "A"}, {Text: "B"}, {Text: "C"}}
+	pollRepoMock.On("FindByMessage", "msg1", "user1").Return(&domain.Poll{MessageID: "msg1", RoomID: "room1", Options: options, Quiz: true, CorrectOption: &correct}, nil).Once()
+	pollRepoMock.On("SetVotes", "msg1", "user1", []int{2}).Return(nil)
+	pollRepoMock.On("FindByMessage", "msg1", "user1").Return(&domain.Poll{MessageID: "msg1", RoomID: "room1", Options: options, Quiz: true, CorrectOption: &correct, TotalVoters: 1, MyVotes: []int{2}}, nil)
+
+	updates, cancel, err := pollService.Subscribe("room1", "user2")
+	assert.Nil(t, err)
+	defer cancel()
+
+	poll, err := pollService.Vote("room1", "msg1", "user1", []int{2, 2})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{2}, poll.MyVotes)
+	assert.Equal(t, &correct, poll.CorrectOption)
+
+	select {
+	case update := <-updates:
+		assert.Equal(t, 1, update.TotalVoters)
+		assert.Nil(t, update.MyVotes)
+		assert.Nil(t, update.CorrectOption)
+	default:
+		t.Fatal("expected a poll update")
+	}
+	pollRepoMock.AssertExpectations(t)
+}
+
+// Test 4: Banned members cannot vote, until a timed ban expires; it is lifted when checked.
+func TestVoteBannedMember(t *testing.T) {
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	pollRepoMock := new(mocks.PollRepositoryMock)
+	pollService := NewPollService(new(mocks.RoomRepositoryMock), membershipRepoMock, restrictionRepoMock, new(mocks.RoomMessageRepositoryMock), pollRepoMock, nil)
+
+	expired := time.Now().Add(-time.Minute)
+	membershipRepoMock.On("GetMembership", "room1", "user1").Return(&domain.RoomMembership{Role: domain.RoleBanned}, nil)
+	restrictionRepoMock.On("Find", "room1", "user1", domain.RestrictionBan).Return(&domain.RoomRestriction{RoomID: "room1", UserID: "user1", Kind: domain.RestrictionBan}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user2").Return(&domain.RoomMembership{Role: domain.RoleBanned}, nil).Once()
+	restrictionRepoMock.On("Find", "room1", "user2", domain.RestrictionBan).Return(&domain.RoomRestriction{
+		RoomID: "room1", UserID: "user2", Kind: domain.RestrictionBan, PriorRole: domain.RoleMember, ExpiresAt: &expired,
+	}, nil)
+	membershipRepoMock.On("UpdateMemberRole", "room1", "user2", domain.RoleMember).Return(nil)
+	restrictionRepoMock.On("Delete", "room1", "user2", domain.RestrictionBan).Return(nil)
+	membershipRepoMock.On("GetMembership", "room1", "user2").Return(&domain.RoomMembership{Role: domain.RoleMember}, nil)
+	pollRepoMock.On("FindByMessage", "msg1", "user2").Return(&domain.Poll{MessageID: "msg1", RoomID: "room1", Options: []domain.PollOption{{Text: "A"}, {Text: "B"}}}, nil)
+	pollRepoMock.On("SetVotes", "msg1", "user2", []int{0}).Return(nil)
+
+	_, err := pollService.Vote("room1", "msg1", "user1", []int{0})
+	assert.ErrorIs(t, err, ErrBanned)
+	pollRepoMock.AssertNotCalled(t, "SetVotes", "msg1", "user1", mock.Anything)
+
+	_, err = pollService.Vote("room1", "msg1", "user2", []int{0})
+	assert.Nil(t, err)
+	restrictionRepoMock.AssertExpectations(t)
+}
+
+// Test 5: Single-choice polls take one option, and polls past their deadline take none.
+func TestVoteRejected(t *testing.T) {
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	pollRepoMock := new(mocks.PollRepositoryMock)
+	pollService := NewPollService(new(mocks.RoomRepositoryMock), membershipRepoMock, new(mocks.RoomRestrictionRepositoryMock), new(mocks.RoomMessageRepositoryMock), pollRepoMock, nil)
+
+	past := time.Now().Add(-time.Minute)
+	options := []domain.PollOption{{Text: "A"}, {Text: "B"}}
+	membershipRepoMock.On("GetMembership", "room1", "user1").Return(&domain.RoomMembership{Role: domain.RoleMember}, nil)
+	pollRepoMock.On("FindByMessage", "msg1", "user1").Return(&domain.Poll{MessageID: "msg1", RoomID: "room1", Options: options}, nil)
+	pollRepoMock.On("FindByMessage", "msg2", "user1").Return(&domain.Poll{MessageID: "msg2", RoomID: "room1", Options: options, CloseAt: &past}, nil)
+
+	_, err := pollService.Vote("room1", "msg1", "user1", []int{0, 1})
+	assert.NotNil(t, err)
+
+	_, err = pollService.Vote("room1", "msg2", "user1", []int{0})
+	assert.ErrorIs(t, err, ErrPollClosed)
+	pollRepoMock.AssertNotCalled(t, "SetVotes", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test 6: Votes can be retracted, except quiz answers.
+func TestRetractVote(t *testing.T) {
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	pollRepoMock := new(mocks.PollRepositoryMock)
+	pollService := NewPollService(new(mocks.RoomRepositoryMock), membershipRepoMock, new(mocks.RoomRestrictionRepositoryMock), new(mocks.RoomMessageRepositoryMock), pollRepoMock, nil)
+
+	correct := 0
+	options := []domain.PollOption{{Text: "A", Votes: 1}, {Text: "B"}}
+	membershipRepoMock.On("GetMembership", "room1", "user1").Return(&domain.RoomMembership{Role: domain.RoleMember}, nil)
+	pollRepoMock.On("FindByMessage", "msg1", "user1").Return(&domain.Poll{MessageID: "msg1", RoomID: "room1", Options: options, TotalVoters: 1, MyVotes: []int{0}}, nil).Once()
+	pollRepoMock.On("SetVotes", "msg1", "user1", []int(nil)).Return(nil)
+	pollRepoMock.On("FindByMessage", "msg1", "user1").Return(&domain.Poll{MessageID: "msg1", RoomID: "room1", Options: []domain.PollOption{{Text: "A"}, {Text: "B"}}}, nil).Once()
+	pollRepoMock.On("FindByMessage", "quiz1", "user1").Return(&domain.Poll{MessageID: "quiz1", RoomID: "room1", Options: options, Quiz: true, CorrectOption: &correct, MyVotes: []int{0}}, nil)
+
+	poll, err := pollService.RetractVote("room1", "msg1", "user1")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, poll.TotalVoters)
+
+	_, err = pollService.RetractVote("room1", "quiz1", "user1")
+	assert.ErrorIs(t, err, ErrQuizAnswered)
+	pollRepoMock.AssertNumberOfCalls(t, "SetVotes", 1)
+}
+
+// Test 7: A vote that loses a race with closing the poll, or with the user's
+// other quiz answer, is rejected by the repository and nothing is published.
+func TestVoteRejectedUnderLock(t *testing.T) {
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	pollRepoMock := new(mocks.PollRepositoryMock)
+	pollService := NewPollService(new(mocks.RoomRepositoryMock), membershipRepoMock, new(mocks.RoomRestrictionRepositoryMock), new(mocks.RoomMessageRepositoryMock), pollRepoMock, nil)
+
+	correct := 0
+	options := []domain.PollOption{{Text: "A"}, {Text: "B"}}
+	membershipRepoMock.On("GetMembership", "room1", "user1").Return(&domain.RoomMembership{Role: domain.RoleMember}, nil)
+	pollRepoMock.On("FindByMessage", "msg1", "user1").Return(&domain.Poll{MessageID: "msg1", RoomID: "room1", Options: options}, nil)
+	pollRepoMock.On("FindByMessage", "quiz1", "user1").Return(&domain.Poll{MessageID: "quiz1", RoomID: "room1", Options: options, Quiz: true, CorrectOption: &correct}, nil)
+	pollRepoMock.On("SetVotes", "msg1", "user1", []int{1}).Return(domain.ErrPollClosed)
+	pollRepoMock.On("SetVotes", "quiz1", "user1", []int{1}).Return(domain.ErrQuizAnswered)
+
+	_, err := pollService.Vote("room1", "msg1", "user1", []int{1})
+	assert.ErrorIs(t, err, ErrPollClosed)
+
+	_, err = pollService.Vote("room1", "quiz1", "user1", []int{1})
+	assert.ErrorIs(t, err, ErrQuizAnswered)
+	pollRepoMock.AssertNumberOfCalls(t, "FindByMessage", 2)
+}
+
+// Test 8: Users who never joined a room cannot post polls to it.
+func TestCreatePollNonMember(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, new(mocks.UserRepositoryMock), new(mocks.RoomAuditRepositoryMock), restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+	pollService := NewPollService(roomRepoMock, membershipRepoMock, restrictionRepoMock, roomMessageRepoMock, new(mocks.PollRepositoryMock), roomService)
+
+	membershipRepoMock.On("GetMembership", "room1", "stranger").Return(nil, nil)
+
+	message, err := pollService.CreatePoll("room1", "stranger", &domain.Poll{
+		Question: "Lunch?",
+		Options:  []domain.PollOption{{Text: "Yes"}, {Text: "No"}},
+	})
+	assert.Nil(t, message)
+	assert.ErrorIs(t, err, ErrNotRoomMember)
+	roomMessageRepoMock.AssertNotCalled(t, "Create", mock.Anything)
+}
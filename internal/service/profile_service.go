@@ -2,6 +2,7 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 
 	"golang.org/x/crypto/bcrypt"
@@ -12,9 +13,19 @@ import (
 type ProfileService interface {
 	GetProfile(userID string) (*domain.User, error)
 	UpdateProfile(userID, name, username, password string) (*domain.User, error)
+	SetDiscoverable(userID string, discoverable bool) (*domain.User, error)
+	UpdatePrivacy(userID string, update PrivacyUpdate) (*domain.User, error)
 	DeleteProfile(userID string) error
 }
 
+// PrivacyUpdate holds a partial privacy settings update. Nil fields are left unchanged.
+type PrivacyUpdate struct {
+	PhoneVisibility *domain.PrivacyAudience
+	FindByPhone     *domain.PrivacyAudience
+	Messages        *domain.PrivacyAudience
+	GroupInvites    *domain.PrivacyAudience
+}
+
 type profileService struct {
 	userRepo domain.UserRepository
 }
@@ -80,6 +91,49 @@ func (s *profileService) UpdateProfile(userID, name, username, password string)
 	return user, nil
 }
 
+// SetDiscoverable opts the user in to or out of the user directory.
+func (s *profileService) SetDiscoverable(userID string, discoverable bool) (*domain.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		return nil, errors.New("user not found")
+	}
+	user.Discoverable = discoverable
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// UpdatePrivacy applies the given privacy settings.
+func (s *profileService) UpdatePrivacy(userID string, update PrivacyUpdate) (*domain.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		return nil, errors.New("user not found")
+	}
+	settings := []struct {
+		value *domain.PrivacyAudience
+		dst   *domain.PrivacyAudience
+	}{
+		{update.PhoneVisibility, &user.Privacy.PhoneVisibility},
+		{update.FindByPhone, &user.Privacy.FindByPhone},
+		{update.Messages, &user.Privacy.Messages},
+		{update.GroupInvites, &user.Privacy.GroupInvites},
+	}
+	for _, setting := range settings {
+		if setting.value == nil {
+			continue
+		}
+		if !setting.value.Valid() {
+			return nil, fmt.Errorf("invalid privacy audience %q", *setting.value)
+		}
+		*setting.dst = *setting.value
+	}
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
 // DeleteProfile deletes the user account.
 func (s *profileService) DeleteProfile(userID string) error {
 	user, err := s.userRepo.FindByID(userID)
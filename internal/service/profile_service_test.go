@@ -104,3 +104,33 @@ func TestDeleteProfileSuccess(t *testing.T) {
 func ptr(s string) *string {
 	return &s
 }
+
+// Test 7: Opt out of the user directory.
+func TestSetDiscoverableSuccess(t *testing.T) {
+	userRepoMock := new(mocks.UserRepositoryMock)
+	profileService := NewProfileService(userRepoMock)
+
+	existingUser := &domain.User{ID: "user1", Name: "Alice", Discoverable: true}
+	userRepoMock.On("FindByID", "user1").Return(existingUser, nil)
+	userRepoMock.On("Update", existingUser).Return(nil)
+
+	updatedUser, err := profileService.SetDiscoverable("user1", false)
+	assert.Nil(t, err)
+	assert.False(t, updatedUser.Discoverable)
+	userRepoMock.AssertExpectations(t)
+}
+
+// Test 8: Privacy updates reject unknown audiences.
+func TestUpdatePrivacyInvalidAudience(t *testing.T) {
+	userRepoMock := new(mocks.UserRepositoryMock)
+	profileService := NewProfileService(userRepoMock)
+
+	existingUser := &domain.User{ID: "user1", Privacy: domain.DefaultPrivacySettings()}
+	userRepoMock.On("FindByID", "user1").Return(existingUser, nil)
+
+	friends := domain.PrivacyAudience("friends")
+	updatedUser, err := profileService.UpdatePrivacy("user1", PrivacyUpdate{Messages: &friends})
+	assert.Nil(t, updatedUser)
+	assert.EqualError(t, err, `invalid privacy audience "friends"`)
+	userRepoMock.AssertNotCalled(t, "Update", mock.Anything)
+}
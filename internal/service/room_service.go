@@ -2,42 +2,139 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
 	"social_media/internal/domain"
 )
 
+// Errors returned by RoomService moderation. Handlers map them to status codes.
+var (
+	ErrRoomNotFound  = errors.New("room not found")
+	ErrNotRoomMember = errors.New("user is not a member of this room")
+	// ErrForbidden is matched (via errors.Is) by every authorization failure.
+	ErrForbidden              = errors.New("forbidden")
+	ErrInsufficientRank       = forbiddenError("cannot moderate a member with an equal or higher role")
+	ErrInvalidRoleTransition  = errors.New("invalid role transition")
+	ErrPermissionNotHeld      = forbiddenError("cannot grant permissions you do not have")
+	ErrInvalidPassword        = forbiddenError("invalid password")
+	ErrGroupInvitesRestricted = forbiddenError("this user does not allow you to add them to groups")
+	ErrOwnerMustTransfer      = errors.New("owner must transfer ownership before leaving")
+	ErrBanned                 = errors.New("you are banned from this room")
+	ErrMuted                  = errors.New("you are muted in this room")
+)
+
+// expiredRestrictionBatch is how many expired restrictions the sweeper lifts per query.
+const expiredRestrictionBatch = 100
+
+// forbiddenError is an authorization failure with a specific message.
+type forbiddenError string
+
+func (e forbiddenError) Error() string { return string(e) }
+
+func (e forbiddenError) Is(target error) bool { return target == ErrForbidden }
+
+// requirePermission loads the user's membership and checks that it grants perm,
+// failing with a forbiddenError carrying message otherwise.
+func requirePermission(
+	membershipRepo domain.RoomMembershipRepository,
+	roomID, userID string,
+	perm domain.AdminPermissions,
+	message string,
+) (*domain.RoomMembership, error) {
+	membership, err := membershipRepo.GetMembership(roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if membership == nil || !membership.Can(perm) {
+		return nil, forbiddenError(message)
+	}
+	return membership, nil
+}
+
+// RoomUpdate holds a partial room update. Nil fields are left unchanged.
+type RoomUpdate struct {
+	Name          *string
+	Username      *string // an empty string clears the username
+	Description   *string
+	PhotoURL      *string // must be an http(s) URL; an empty string clears the photo
+	Rules         *string
+	JoinByRequest *bool
+}
+
 type RoomService interface {
 	CreateRoom(ownerID, name, username string, roomType domain.RoomType) (*domain.Room, error)
-	UpdateRoom(roomID, updaterID, newName, newUsername string) (*domain.Room, error)
+	GetRoom(roomID, userID string) (*domain.RoomDetails, error)
+	GetPublicRoom(username string) (*domain.RoomDetails, error)
+	SearchPublicRooms(query string, limit, offset int) ([]*domain.RoomDetails, error)
+	JoinRoom(roomID, userID string) error
+	UpdateRoom(roomID, updaterID string, update RoomUpdate) (*domain.Room, error)
 	DeleteRoom(roomID, requesterID string) error
+	TransferOwnership(roomID, ownerID, newOwnerID, password string) error
 	AddMember(roomID, requesterID, userID string) error
+	// AdmitMember adds a user who asked to join, so their group invite privacy does not apply.
+	AdmitMember(roomID, requesterID, userID string) error
 	RemoveMember(roomID, requesterID, userID string) error
-	PromoteMember(roomID, requesterID, userID string) error
-	BanMember(roomID, requesterID, userID string) error
+	PromoteMember(roomID, requesterID, userID string, permissions domain.AdminPermissions) error
+	DemoteMember(roomID, requesterID, userID string) error
+	SetAdminPermissions(roomID, requesterID, userID string, permissions domain.AdminPermissions) error
+	// BanMember bans the user until the given time, or indefinitely if until is nil.
+	BanMember(roomID, requesterID, userID, reason string, until *time.Time) error
 	UnbanMember(roomID, requesterID, userID string) error
-	SendMessage(roomID, senderID, content string) (*domain.RoomMessage, error)
+	// MuteMember blocks the user from sending messages until the given time, or indefinitely if until is nil.
+	MuteMember(roomID, requesterID, userID, reason string, until *time.Time) error
+	UnmuteMember(roomID, requesterID, userID string) error
+	// ExpireRestrictions lifts every ban and mute past its deadline and returns how many were lifted.
+	ExpireRestrictions() (int, error)
+	SendMessage(roomID, senderID, content string, entities []domain.MessageEntity) (*domain.RoomMessage, error)
+	// ForwardMessages posts copies of messages, attributed to their origin, to the room.
+	ForwardMessages(roomID, senderID string, forwards []domain.Forward) ([]*domain.RoomMessage, error)
+	// SendPoll posts a poll to the room under the same rules as SendMessage.
+	SendPoll(roomID, senderID string, poll *domain.Poll) (*domain.RoomMessage, error)
 	DeleteMessage(roomID, requesterID, messageID string) error
-	GetMessages(roomID string) ([]*domain.RoomMessage, error)
+	// GetMessages returns the room's history to a user who may read the room.
+	GetMessages(roomID, userID string) ([]*domain.RoomMessage, error)
 	GetMembers(roomID string) ([]*domain.RoomMembership, error)
 }
 
 type roomService struct {
-	roomRepo       domain.RoomRepository
-	membershipRepo domain.RoomMembershipRepository
-	messageRepo    domain.RoomMessageRepository
+	roomRepo         domain.RoomRepository
+	membershipRepo   domain.RoomMembershipRepository
+	messageRepo      domain.RoomMessageRepository
+	userRepo         domain.UserRepository
+	auditRepo        domain.RoomAuditRepository
+	restrictionRepo  domain.RoomRestrictionRepository
+	contactRepo      domain.ContactRepository
+	notificationRepo domain.NotificationRepository
+	chatStateRepo    domain.ChatStateRepository // suppresses mention notifications for muted rooms
 }
 
 func NewRoomService(
 	roomRepo domain.RoomRepository,
 	membershipRepo domain.RoomMembershipRepository,
 	messageRepo domain.RoomMessageRepository,
+	userRepo domain.UserRepository,
+	auditRepo domain.RoomAuditRepository,
+	restrictionRepo domain.RoomRestrictionRepository,
+	contactRepo domain.ContactRepository,
+	notificationRepo domain.NotificationRepository,
+	chatStateRepo domain.ChatStateRepository,
 ) RoomService {
 	return &roomService{
-		roomRepo:       roomRepo,
-		membershipRepo: membershipRepo,
-		messageRepo:    messageRepo,
+		roomRepo:         roomRepo,
+		membershipRepo:   membershipRepo,
+		messageRepo:      messageRepo,
+		userRepo:         userRepo,
+		auditRepo:        auditRepo,
+		restrictionRepo:  restrictionRepo,
+		contactRepo:      contactRepo,
+		notificationRepo: notificationRepo,
+		chatStateRepo:    chatStateRepo,
 	}
 }
 
@@ -70,48 +167,211 @@ func (s *roomService) CreateRoom(ownerID, name, username string, roomType domain
 	return room, nil
 }
 
-func (s *roomService) UpdateRoom(roomID, updaterID, newName, newUsername string) (*domain.Room, error) {
+// GetRoom returns the room card. Private rooms are only shown to their members;
+// anyone else gets ErrRoomNotFound.
+func (s *roomService) GetRoom(roomID, userID string) (*domain.RoomDetails, error) {
+	room, err := s.roomRepo.FindByID(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+	if !room.IsPublic() {
+		membership, err := roomMembership(s.membershipRepo, s.restrictionRepo, roomID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if membership != nil && membership.Role == domain.RoleBanned {
+			return nil, ErrBanned
+		}
+		if membership == nil {
+			return nil, ErrRoomNotFound
+		}
+	}
+	count, err := s.membershipRepo.CountMembers(roomID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.RoomDetails{Room: *room, MemberCount: count}, nil
+}
+
+// GetPublicRoom returns a preview of the public room with the given username.
+func (s *roomService) GetPublicRoom(username string) (*domain.RoomDetails, error) {
+	room, err := s.roomRepo.FindByUsername(username)
+	if err != nil || room == nil {
+		return nil, ErrRoomNotFound
+	}
+	count, err := s.membershipRepo.CountMembers(room.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.RoomDetails{Room: *room, MemberCount: count}, nil
+}
+
+// SearchPublicRooms searches the public room directory.
+func (s *roomService) SearchPublicRooms(query string, limit, offset int) ([]*domain.RoomDetails, error) {
+	limit, offset = normalizePage(limit, offset)
+	return s.roomRepo.SearchPublic(strings.TrimSpace(query), limit, offset)
+}
+
+// JoinRoom lets a user join a public group or subscribe to a public channel.
+func (s *roomService) JoinRoom(roomID, userID string) error {
 	room, err := s.roomRepo.FindByID(roomID)
 	if err != nil || room == nil {
-		return nil, errors.New("room not found")
+		return ErrRoomNotFound
+	}
+	if !room.IsPublic() {
+		return errors.New("room is private")
+	}
+	if room.JoinByRequest {
+		return errors.New("room requires a join request")
 	}
-	role, err := s.membershipRepo.GetMemberRole(roomID, updaterID)
+	existingRole, err := s.membershipRepo.GetMemberRole(roomID, userID)
 	if err != nil {
+		return err
+	}
+	if existingRole == domain.RoleBanned {
+		// Lifting an expired ban restores the membership, so the user is back in.
+		return s.checkBan(roomID, userID)
+	}
+	if existingRole != "" {
+		return errors.New("user already a member")
+	}
+	membership := &domain.RoomMembership{
+		RoomID:    roomID,
+		UserID:    userID,
+		Role:      domain.RoleMember,
+		CreatedAt: time.Now(),
+	}
+	return s.membershipRepo.AddMember(membership)
+}
+
+func (s *roomService) UpdateRoom(roomID, updaterID string, update RoomUpdate) (*domain.Room, error) {
+	room, err := s.roomRepo.FindByID(roomID)
+	if err != nil || room == nil {
+		return nil, ErrRoomNotFound
+	}
+	if _, err := requirePermission(s.membershipRepo, roomID, updaterID, domain.PermChangeInfo, "not authorized to update room"); err != nil {
 		return nil, err
 	}
-	if role != domain.RoleOwner && role != domain.RoleAdmin {
-		return nil, errors.New("not authorized to update room")
+	before := *room
+	if update.Name != nil {
+		name := strings.TrimSpace(*update.Name)
+		if name == "" {
+			return nil, errors.New("room name cannot be empty")
+		}
+		room.Name = name
+	}
+	if update.Username != nil {
+		if *update.Username == "" {
+			room.Username = nil
+		} else {
+			username := *update.Username
+			room.Username = &username
+		}
+	}
+	if update.Description != nil {
+		room.Description = *update.Description
+	}
+	if update.PhotoURL != nil {
+		if *update.PhotoURL != "" && !isHTTPURL(*update.PhotoURL) {
+			return nil, errors.New("invalid photo url")
+		}
+		room.PhotoURL = *update.PhotoURL
+	}
+	if update.Rules != nil {
+		room.Rules = *update.Rules
 	}
-	room.Name = newName
-	if newUsername != "" {
-		room.Username = &newUsername
+	if update.JoinByRequest != nil {
+		room.JoinByRequest = *update.JoinByRequest
 	}
 	room.UpdatedAt = time.Now()
 	if err := s.roomRepo.Update(room); err != nil {
 		return nil, err
 	}
+	if err := appendAudit(s.auditRepo, roomID, updaterID, domain.AuditUpdateRoom, "", before, room); err != nil {
+		return nil, err
+	}
 	return room, nil
 }
 
 func (s *roomService) DeleteRoom(roomID, requesterID string) error {
 	room, err := s.roomRepo.FindByID(roomID)
 	if err != nil || room == nil {
-		return errors.New("room not found")
+		return ErrRoomNotFound
 	}
 	role, err := s.membershipRepo.GetMemberRole(roomID, requesterID)
 	if err != nil {
 		return err
 	}
-	if role != domain.RoleOwner && role != domain.RoleAdmin {
-		return errors.New("not authorized to delete room")
+	// Deleting a room is never delegated to admins.
+	if role != domain.RoleOwner {
+		return forbiddenError("not authorized to delete room")
 	}
 	return s.roomRepo.Delete(roomID)
 }
 
+// TransferOwnership hands the room to another member once the owner has
+// re-confirmed their password. The previous owner stays on as an admin.
+func (s *roomService) TransferOwnership(roomID, ownerID, newOwnerID, password string) error {
+	room, err := s.roomRepo.FindByID(roomID)
+	if err != nil || room == nil {
+		return ErrRoomNotFound
+	}
+	role, err := s.membershipRepo.GetMemberRole(roomID, ownerID)
+	if err != nil {
+		return err
+	}
+	if role != domain.RoleOwner {
+		return forbiddenError("only owner can transfer ownership")
+	}
+	owner, err := s.userRepo.FindByID(ownerID)
+	if err != nil || owner == nil {
+		return errors.New("user not found")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(owner.Password), []byte(password)); err != nil {
+		return ErrInvalidPassword
+	}
+	if newOwnerID == ownerID {
+		return fmt.Errorf("%w: already the owner", ErrInvalidRoleTransition)
+	}
+	targetRole, err := s.membershipRepo.GetMemberRole(roomID, newOwnerID)
+	if err != nil {
+		return err
+	}
+	if targetRole == "" {
+		return ErrNotRoomMember
+	}
+	if !targetRole.CanTransitionTo(domain.RoleOwner) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidRoleTransition, targetRole, domain.RoleOwner)
+	}
+	return s.transferOwnership(roomID, ownerID, newOwnerID)
+}
+
+// transferOwnership moves ownership and records it in the audit log.
+func (s *roomService) transferOwnership(roomID, ownerID, newOwnerID string) error {
+	if err := s.roomRepo.TransferOwnership(roomID, ownerID, newOwnerID); err != nil {
+		return err
+	}
+	return appendAudit(s.auditRepo, roomID, ownerID, domain.AuditTransferOwnership, newOwnerID,
+		ownerSnapshot{OwnerID: ownerID}, ownerSnapshot{OwnerID: newOwnerID})
+}
+
 func (s *roomService) AddMember(roomID, requesterID, userID string) error {
+	return s.addMember(roomID, requesterID, userID, true)
+}
+
+func (s *roomService) AdmitMember(roomID, requesterID, userID string) error {
+	return s.addMember(roomID, requesterID, userID, false)
+}
+
+// addMember adds userID as a member. When checkPrivacy is set, users whose
+// privacy settings exclude the requester cannot be added.
+func (s *roomService) addMember(roomID, requesterID, userID string, checkPrivacy bool) error {
 	room, err := s.roomRepo.FindByID(roomID)
 	if err != nil || room == nil {
-		return errors.New("room not found")
+		return ErrRoomNotFound
 	}
 	// Check if user is already a member.
 	existingRole, err := s.membershipRepo.GetMemberRole(roomID, userID)
@@ -121,14 +381,26 @@ func (s *roomService) AddMember(roomID, requesterID, userID string) error {
 	if existingRole != "" {
 		return errors.New("user already a member")
 	}
-	// In channels, only owner/admin can add members.
+	// In channels, only admins allowed to invite users can add members.
 	if room.Type == domain.RoomTypeChannel {
-		reqRole, err := s.membershipRepo.GetMemberRole(roomID, requesterID)
+		if _, err := requirePermission(s.membershipRepo, roomID, requesterID, domain.PermInviteUsers, "not authorized to add member to channel"); err != nil {
+			return err
+		}
+	}
+	if checkPrivacy {
+		user, err := s.userRepo.FindByID(userID)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return errors.New("user not found")
+		}
+		allowed, err := audienceAllows(s.contactRepo, user.Privacy.GroupInvites, userID, requesterID)
 		if err != nil {
 			return err
 		}
-		if reqRole != domain.RoleOwner && reqRole != domain.RoleAdmin {
-			return errors.New("not authorized to add member to channel")
+		if !allowed {
+			return ErrGroupInvitesRestricted
 		}
 	}
 	membership := &domain.RoomMembership{
@@ -141,82 +413,671 @@ func (s *roomService) AddMember(roomID, requesterID, userID string) error {
 }
 
 func (s *roomService) RemoveMember(roomID, requesterID, userID string) error {
-	// If not self-removal, only owner/admin can remove a member.
-	if requesterID != userID {
-		reqRole, err := s.membershipRepo.GetMemberRole(roomID, requesterID)
+	if requesterID == userID {
+		// A ban is stored as the membership itself, so leaving would lift it.
+		role, err := s.membershipRepo.GetMemberRole(roomID, userID)
 		if err != nil {
 			return err
 		}
-		if reqRole != domain.RoleOwner && reqRole != domain.RoleAdmin {
-			return errors.New("not authorized to remove member")
+		if role == "" {
+			return ErrNotRoomMember
 		}
+		if role == domain.RoleBanned {
+			return fmt.Errorf("%w: banned users cannot leave", ErrInvalidRoleTransition)
+		}
+		// A leaving owner hands the room to the longest-serving admin.
+		if role == domain.RoleOwner {
+			if err := s.passOwnership(roomID, userID); err != nil {
+				return err
+			}
+		}
+		return s.membershipRepo.RemoveMember(roomID, userID)
+	}
+	// If not self-removal, only admins allowed to ban users can remove a lower-ranked member.
+	requester, err := requirePermission(s.membershipRepo, roomID, requesterID, domain.PermBanUsers, "not authorized to remove member")
+	if err != nil {
+		return err
+	}
+	target, err := s.moderationTarget(roomID, requester.Role, userID)
+	if err != nil {
+		return err
+	}
+	if target.Role == domain.RoleBanned {
+		return fmt.Errorf("%w: banned users must be unbanned, not removed", ErrInvalidRoleTransition)
+	}
+	if err := s.membershipRepo.RemoveMember(roomID, userID); err != nil {
+		return err
+	}
+	return appendAudit(s.auditRepo, roomID, requesterID, domain.AuditRemove, userID, snapshotOf(target), nil)
+}
+
+// PromoteMember makes a member an admin with the given permissions. Admins
+// allowed to manage admins may only grant permissions they hold themselves.
+func (s *roomService) PromoteMember(roomID, requesterID, userID string, permissions domain.AdminPermissions) error {
+	requester, err := requirePermission(s.membershipRepo, roomID, requesterID, domain.PermManageAdmins, "not authorized to promote member")
+	if err != nil {
+		return err
+	}
+	if !requester.Can(permissions) {
+		return ErrPermissionNotHeld
+	}
+	target, err := s.checkTransition(roomID, requester.Role, userID, domain.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if err := s.membershipRepo.SetAdmin(roomID, userID, permissions, requesterID); err != nil {
+		return err
+	}
+	after := &domain.RoomMembership{Role: domain.RoleAdmin, Permissions: permissions}
+	return appendAudit(s.auditRepo, roomID, requesterID, domain.AuditPromote, userID, snapshotOf(target), snapshotOf(after))
+}
+
+// DemoteMember turns an admin back into a regular member. The owner can demote
+// any admin; admins allowed to manage admins only those they promoted.
+func (s *roomService) DemoteMember(roomID, requesterID, userID string) error {
+	requester, err := requirePermission(s.membershipRepo, roomID, requesterID, domain.PermManageAdmins, "not authorized to demote member")
+	if err != nil {
+		return err
+	}
+	target, err := s.membershipRepo.GetMembership(roomID, userID)
+	if err != nil {
+		return err
 	}
-	return s.membershipRepo.RemoveMember(roomID, userID)
+	if target == nil {
+		return ErrNotRoomMember
+	}
+	if target.Role != domain.RoleAdmin {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidRoleTransition, target.Role, domain.RoleMember)
+	}
+	if requester.Role != domain.RoleOwner && (target.PromotedBy == nil || *target.PromotedBy != requesterID) {
+		return forbiddenError("can only demote admins you promoted")
+	}
+	if err := s.membershipRepo.UpdateMemberRole(roomID, userID, domain.RoleMember); err != nil {
+		return err
+	}
+	after := &domain.RoomMembership{Role: domain.RoleMember}
+	return appendAudit(s.auditRepo, roomID, requesterID, domain.AuditDemote, userID, snapshotOf(target), snapshotOf(after))
 }
 
-func (s *roomService) PromoteMember(roomID, requesterID, userID string) error {
-	reqRole, err := s.membershipRepo.GetMemberRole(roomID, requesterID)
+// SetAdminPermissions replaces the permissions of an existing, lower-ranked admin.
+func (s *roomService) SetAdminPermissions(roomID, requesterID, userID string, permissions domain.AdminPermissions) error {
+	requester, err := requirePermission(s.membershipRepo, roomID, requesterID, domain.PermManageAdmins, "not authorized to edit admin permissions")
 	if err != nil {
 		return err
 	}
-	if reqRole != domain.RoleOwner {
-		return errors.New("only owner can promote member")
+	if !requester.Can(permissions) {
+		return ErrPermissionNotHeld
+	}
+	target, err := s.moderationTarget(roomID, requester.Role, userID)
+	if err != nil {
+		return err
+	}
+	if target.Role != domain.RoleAdmin {
+		return errors.New("user is not an admin")
+	}
+	if err := s.membershipRepo.UpdateMemberPermissions(roomID, userID, permissions); err != nil {
+		return err
 	}
-	return s.membershipRepo.UpdateMemberRole(roomID, userID, domain.RoleAdmin)
+	after := &domain.RoomMembership{Role: domain.RoleAdmin, Permissions: permissions}
+	return appendAudit(s.auditRepo, roomID, requesterID, domain.AuditUpdatePermissions, userID, snapshotOf(target), snapshotOf(after))
 }
 
-func (s *roomService) BanMember(roomID, requesterID, userID string) error {
-	reqRole, err := s.membershipRepo.GetMemberRole(roomID, requesterID)
+func (s *roomService) BanMember(roomID, requesterID, userID, reason string, until *time.Time) error {
+	requester, err := requirePermission(s.membershipRepo, roomID, requesterID, domain.PermBanUsers, "not authorized to ban member")
 	if err != nil {
 		return err
 	}
-	if reqRole != domain.RoleOwner && reqRole != domain.RoleAdmin {
-		return errors.New("not authorized to ban member")
+	if until != nil && !until.After(time.Now()) {
+		return errors.New("ban end must be in the future")
 	}
-	return s.membershipRepo.UpdateMemberRole(roomID, userID, domain.RoleBanned)
+	target, err := s.checkTransition(roomID, requester.Role, userID, domain.RoleBanned)
+	if err != nil {
+		return err
+	}
+	restriction := newRestriction(roomID, userID, requesterID, target, domain.RestrictionBan, reason, until)
+	if err := s.restrictionRepo.Upsert(restriction); err != nil {
+		return err
+	}
+	if err := s.membershipRepo.UpdateMemberRole(roomID, userID, domain.RoleBanned); err != nil {
+		return err
+	}
+	return appendAudit(s.auditRepo, roomID, requesterID, domain.AuditBan, userID, snapshotOf(target), restriction)
 }
 
+// UnbanMember lifts a ban, restoring the role the user held before it. The
+// requester must outrank that role, as they would have to in order to ban.
 func (s *roomService) UnbanMember(roomID, requesterID, userID string) error {
-	reqRole, err := s.membershipRepo.GetMemberRole(roomID, requesterID)
+	requester, err := requirePermission(s.membershipRepo, roomID, requesterID, domain.PermBanUsers, "not authorized to unban member")
+	if err != nil {
+		return err
+	}
+	target, err := s.moderationTarget(roomID, requester.Role, userID)
+	if err != nil {
+		return err
+	}
+	// Only banned users can be unbanned; role changes go through promote and demote.
+	if target.Role != domain.RoleBanned {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidRoleTransition, target.Role, domain.RoleMember)
+	}
+	restriction, err := s.restrictionRepo.Find(roomID, userID, domain.RestrictionBan)
 	if err != nil {
 		return err
 	}
-	if reqRole != domain.RoleOwner && reqRole != domain.RoleAdmin {
-		return errors.New("not authorized to unban member")
+	after := &domain.RoomMembership{Role: domain.RoleMember}
+	if restriction == nil {
+		// Bans from before restrictions were recorded restore a plain member.
+		if err := s.membershipRepo.UpdateMemberRole(roomID, userID, domain.RoleMember); err != nil {
+			return err
+		}
+	} else {
+		if requester.Role.Rank() <= restriction.PriorRole.Rank() {
+			return ErrInsufficientRank
+		}
+		if err := liftRestriction(s.membershipRepo, s.restrictionRepo, restriction); err != nil {
+			return err
+		}
+		after = &domain.RoomMembership{Role: restriction.PriorRole, Permissions: restriction.PriorPermissions}
 	}
-	return s.membershipRepo.UpdateMemberRole(roomID, userID, domain.RoleMember)
+	return appendAudit(s.auditRepo, roomID, requesterID, domain.AuditUnban, userID, snapshotOf(target), snapshotOf(after))
 }
 
-func (s *roomService) SendMessage(roomID, senderID, content string) (*domain.RoomMessage, error) {
-	// Check ban status.
-	banned, err := s.membershipRepo.IsUserBanned(roomID, senderID)
+func (s *roomService) MuteMember(roomID, requesterID, userID, reason string, until *time.Time) error {
+	requester, err := requirePermission(s.membershipRepo, roomID, requesterID, domain.PermBanUsers, "not authorized to mute member")
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if until != nil && !until.After(time.Now()) {
+		return errors.New("mute end must be in the future")
+	}
+	target, err := s.moderationTarget(roomID, requester.Role, userID)
+	if err != nil {
+		return err
+	}
+	if target.Role == domain.RoleBanned {
+		return fmt.Errorf("%w: banned users cannot be muted", ErrInvalidRoleTransition)
+	}
+	restriction := newRestriction(roomID, userID, requesterID, target, domain.RestrictionMute, reason, until)
+	if err := s.restrictionRepo.Upsert(restriction); err != nil {
+		return err
+	}
+	return appendAudit(s.auditRepo, roomID, requesterID, domain.AuditMute, userID, nil, restriction)
+}
+
+func (s *roomService) UnmuteMember(roomID, requesterID, userID string) error {
+	requester, err := requirePermission(s.membershipRepo, roomID, requesterID, domain.PermBanUsers, "not authorized to unmute member")
+	if err != nil {
+		return err
+	}
+	if _, err := s.moderationTarget(roomID, requester.Role, userID); err != nil {
+		return err
+	}
+	restriction, err := s.restrictionRepo.Find(roomID, userID, domain.RestrictionMute)
+	if err != nil {
+		return err
+	}
+	if restriction == nil {
+		return errors.New("user is not muted")
+	}
+	if err := liftRestriction(s.membershipRepo, s.restrictionRepo, restriction); err != nil {
+		return err
+	}
+	return appendAudit(s.auditRepo, roomID, requesterID, domain.AuditUnmute, userID, restriction, nil)
+}
+
+func (s *roomService) ExpireRestrictions() (int, error) {
+	lifted := 0
+	for {
+		restrictions, err := s.restrictionRepo.FindExpired(time.Now(), expiredRestrictionBatch)
+		if err != nil {
+			return lifted, err
+		}
+		// Keep going past failures so one bad row does not hold up the rest of the batch.
+		var firstErr error
+		for _, restriction := range restrictions {
+			if err := liftRestriction(s.membershipRepo, s.restrictionRepo, restriction); err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			lifted++
+		}
+		if firstErr != nil || len(restrictions) < expiredRestrictionBatch {
+			return lifted, firstErr
+		}
+	}
+}
+
+// checkBan returns ErrBanned while a ban is in force, lifting it first if it
+// has expired.
+func (s *roomService) checkBan(roomID, userID string) error {
+	banned, err := activeBan(s.membershipRepo, s.restrictionRepo, roomID, userID)
+	if err != nil {
+		return err
 	}
 	if banned {
-		return nil, errors.New("you are banned from this room")
+		return ErrBanned
 	}
-	room, err := s.roomRepo.FindByID(roomID)
-	if err != nil || room == nil {
-		return nil, errors.New("room not found")
+	return nil
+}
+
+// activeBan reports whether a ban on userID is in force in the room. An expired
+// timed ban is lifted first, restoring the user's prior role, so expiry takes
+// effect wherever a ban is checked.
+func activeBan(membershipRepo domain.RoomMembershipRepository, restrictionRepo domain.RoomRestrictionRepository, roomID, userID string) (bool, error) {
+	role, err := membershipRepo.GetMemberRole(roomID, userID)
+	if err != nil || role != domain.RoleBanned {
+		return false, err
 	}
-	// In channels, only owner/admin may send messages.
-	if room.Type == domain.RoomTypeChannel {
-		role, err := s.membershipRepo.GetMemberRole(roomID, senderID)
+	return banInForce(membershipRepo, restrictionRepo, roomID, userID)
+}
+
+// banInForce checks the ban of a user whose role is banned, lifting it if it
+// has expired. A banned role without a restriction record is a permanent ban.
+func banInForce(membershipRepo domain.RoomMembershipRepository, restrictionRepo domain.RoomRestrictionRepository, roomID, userID string) (bool, error) {
+	restriction, err := restrictionRepo.Find(roomID, userID, domain.RestrictionBan)
+	if err != nil {
+		return false, err
+	}
+	if restriction == nil || !restriction.Expired(time.Now()) {
+		return true, nil
+	}
+	return false, liftRestriction(membershipRepo, restrictionRepo, restriction)
+}
+
+// roomMembership returns the user's membership of the room, or nil if they are
+// not a member. A user whose ban expired comes back with their restored role.
+func roomMembership(membershipRepo domain.RoomMembershipRepository, restrictionRepo domain.RoomRestrictionRepository, roomID, userID string) (*domain.RoomMembership, error) {
+	membership, err := membershipRepo.GetMembership(roomID, userID)
+	if err != nil || membership == nil || membership.Role != domain.RoleBanned {
+		return membership, err
+	}
+	banned, err := banInForce(membershipRepo, restrictionRepo, roomID, userID)
+	if err != nil || banned {
+		return membership, err
+	}
+	return membershipRepo.GetMembership(roomID, userID)
+}
+
+// activeRestriction returns the user's restriction of the given kind, or nil
+// if there is none. Expired restrictions are lifted on the way.
+func activeRestriction(
+	membershipRepo domain.RoomMembershipRepository,
+	restrictionRepo domain.RoomRestrictionRepository,
+	roomID, userID string,
+	kind domain.RestrictionKind,
+) (*domain.RoomRestriction, error) {
+	restriction, err := restrictionRepo.Find(roomID, userID, kind)
+	if err != nil || restriction == nil {
+		return nil, err
+	}
+	if restriction.Expired(time.Now()) {
+		return nil, liftRestriction(membershipRepo, restrictionRepo, restriction)
+	}
+	return restriction, nil
+}
+
+// liftRestriction removes a restriction. Lifting a ban restores the prior
+// role first, so a failure leaves the user banned rather than unrecorded.
+func liftRestriction(membershipRepo domain.RoomMembershipRepository, restrictionRepo domain.RoomRestrictionRepository, restriction *domain.RoomRestriction) error {
+	if restriction.Kind == domain.RestrictionBan {
+		if err := membershipRepo.UpdateMemberRole(restriction.RoomID, restriction.UserID, restriction.PriorRole); err != nil {
+			return err
+		}
+		if restriction.PriorRole == domain.RoleAdmin {
+			if err := membershipRepo.UpdateMemberPermissions(restriction.RoomID, restriction.UserID, restriction.PriorPermissions); err != nil {
+				return err
+			}
+		}
+	}
+	return restrictionRepo.Delete(restriction.RoomID, restriction.UserID, restriction.Kind)
+}
+
+// newRestriction builds a restriction on userID, remembering their current membership as the prior role.
+func newRestriction(
+	roomID, userID, actorID string,
+	prior *domain.RoomMembership,
+	kind domain.RestrictionKind,
+	reason string,
+	until *time.Time,
+) *domain.RoomRestriction {
+	return &domain.RoomRestriction{
+		ID:               uuid.New().String(),
+		RoomID:           roomID,
+		UserID:           userID,
+		Kind:             kind,
+		Reason:           reason,
+		PriorRole:        prior.Role,
+		PriorPermissions: prior.Permissions,
+		ActorID:          actorID,
+		ExpiresAt:        until,
+		CreatedAt:        time.Now(),
+	}
+}
+
+// passOwnership transfers the room to its oldest admin, or fails with
+// ErrOwnerMustTransfer when there is none.
+func (s *roomService) passOwnership(roomID, ownerID string) error {
+	members, err := s.membershipRepo.GetMembers(roomID)
+	if err != nil {
+		return err
+	}
+	var successor *domain.RoomMembership
+	for _, m := range members {
+		if m.Role == domain.RoleAdmin && (successor == nil || m.CreatedAt.Before(successor.CreatedAt)) {
+			successor = m
+		}
+	}
+	if successor == nil {
+		return ErrOwnerMustTransfer
+	}
+	return s.transferOwnership(roomID, ownerID, successor.UserID)
+}
+
+// moderationTarget returns the target's membership, checking that they are in
+// the room and rank strictly below the requester.
+func (s *roomService) moderationTarget(roomID string, requesterRole domain.RoomMembershipRole, userID string) (*domain.RoomMembership, error) {
+	target, err := s.membershipRepo.GetMembership(roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, ErrNotRoomMember
+	}
+	if requesterRole.Rank() <= target.Role.Rank() {
+		return nil, ErrInsufficientRank
+	}
+	return target, nil
+}
+
+// checkTransition verifies that a lower-ranked target may move to a new role
+// and returns the target's current membership.
+func (s *roomService) checkTransition(roomID string, requesterRole domain.RoomMembershipRole, userID string, to domain.RoomMembershipRole) (*domain.RoomMembership, error) {
+	target, err := s.moderationTarget(roomID, requesterRole, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !target.Role.CanTransitionTo(to) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidRoleTransition, target.Role, to)
+	}
+	return target, nil
+}
+
+// memberSnapshot is the audit log view of a membership before or after a change.
+type memberSnapshot struct {
+	Role        domain.RoomMembershipRole `json:"role"`
+	Permissions *domain.AdminPermissions  `json:"permissions,omitempty"` // admins only
+}
+
+func snapshotOf(m *domain.RoomMembership) memberSnapshot {
+	snapshot := memberSnapshot{Role: m.Role}
+	if m.Role == domain.RoleAdmin {
+		permissions := m.Permissions
+		snapshot.Permissions = &permissions
+	}
+	return snapshot
+}
+
+// ownerSnapshot is the audit log view of a room's ownership.
+type ownerSnapshot struct {
+	OwnerID string `json:"owner_id"`
+}
+
+// SendMessage posts a message to the room with the sender's formatting entities.
+// Mentioned members are notified and their unread mention counters go up.
+func (s *roomService) SendMessage(roomID, senderID, content string, entities []domain.MessageEntity) (*domain.RoomMessage, error) {
+	entities, err := validateEntities(content, entities)
+	if err != nil {
+		return nil, err
+	}
+	room, err := s.checkCanPost(roomID, senderID)
+	if err != nil {
+		return nil, err
+	}
+	mentions, mentioned, err := s.resolveMentions(room, senderID, content, entities)
+	if err != nil {
+		return nil, err
+	}
+	if len(mentions) > 0 {
+		entities = sortEntities(append(entities, mentions...))
+	}
+	message, err := s.createMessage(roomID, senderID, content, nil, entities, mentioned)
+	if err != nil {
+		return nil, err
+	}
+	if len(mentioned) == 0 {
+		return message, nil
+	}
+	// The message and its mentions are stored; failing to notify must not
+	// report the send as failed, or a retry would post it twice.
+	notifications := make([]*domain.Notification, len(mentioned))
+	for i, userID := range mentioned {
+		notifications[i] = &domain.Notification{
+			ID:          uuid.New().String(),
+			UserID:      userID,
+			Type:        domain.NotificationMention,
+			RoomID:      &message.RoomID,
+			ActorID:     &message.SenderID,
+			ReferenceID: &message.ID,
+			CreatedAt:   message.CreatedAt,
+		}
+	}
+	if err := deliverRoomNotifications(s.notificationRepo, s.chatStateRepo, roomID, notifications); err != nil {
+		log.Printf("Failed to notify users mentioned in message %s: %v", message.ID, err)
+	}
+	return message, nil
+}
+
+// resolveMentions turns the @names in content into mention entities and returns
+// the users to notify, excluding the sender. @username only counts for members of
+// the room, @admins reaches the owner and admins, and @all reaches every member but
+// in groups only admins may use it. Anything else is left as plain text, as are
+// names in code or pre spans and names that would cross a formatting entity.
+func (s *roomService) resolveMentions(room *domain.Room, senderID, content string, formatting []domain.MessageEntity) ([]domain.MessageEntity, []string, error) {
+	var matches []domain.MentionMatch
+	for _, match := range domain.FindMentions(content) {
+		if mentionFits(match, formatting) {
+			matches = append(matches, match)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, nil, nil
+	}
+	var members []*domain.RoomMembership
+	loadMembers := func() error {
+		if members != nil {
+			return nil
+		}
+		var err error
+		members, err = s.membershipRepo.GetMembers(room.ID)
+		return err
+	}
+	users := make(map[string]*domain.RoomMembership) // by username; nil for non-members
+	var entities []domain.MessageEntity
+	var mentioned []string
+	seen := map[string]bool{senderID: true}
+	mention := func(userID string) {
+		if !seen[userID] {
+			seen[userID] = true
+			mentioned = append(mentioned, userID)
+		}
+	}
+
+	for _, match := range matches {
+		entity := domain.MessageEntity{Offset: match.Offset, Length: match.Length}
+		switch {
+		case strings.EqualFold(match.Username, domain.MentionAdmins):
+			if err := loadMembers(); err != nil {
+				return nil, nil, err
+			}
+			entity.Type = domain.EntityMentionAdmins
+			for _, member := range members {
+				if member.Role.Rank() >= domain.RoleAdmin.Rank() {
+					mention(member.UserID)
+				}
+			}
+		case strings.EqualFold(match.Username, domain.MentionAll):
+			if err := loadMembers(); err != nil {
+				return nil, nil, err
+			}
+			if room.Type == domain.RoomTypeGroup && !isAdminMember(members, senderID) {
+				continue
+			}
+			entity.Type = domain.EntityMentionAll
+			for _, member := range members {
+				if member.Role != domain.RoleBanned {
+					mention(member.UserID)
+				}
+			}
+		default:
+			membership, looked := users[match.Username]
+			if !looked {
+				user, err := s.userRepo.FindByUsername(match.Username)
+				if err != nil {
+					return nil, nil, err
+				}
+				if user != nil {
+					if membership, err = s.membershipRepo.GetMembership(room.ID, user.ID); err != nil {
+						return nil, nil, err
+					}
+				}
+				users[match.Username] = membership
+			}
+			if membership == nil || membership.Role == domain.RoleBanned {
+				continue
+			}
+			userID := membership.UserID
+			entity.Type = domain.EntityMention
+			entity.UserID = &userID
+			mention(userID)
+		}
+		entities = append(entities, entity)
+	}
+	return entities, mentioned, nil
+}
+
+// isAdminMember reports whether userID is the owner or an admin among members.
+func isAdminMember(members []*domain.RoomMembership, userID string) bool {
+	for _, member := range members {
+		if member.UserID == userID {
+			return member.Role.Rank() >= domain.RoleAdmin.Rank()
+		}
+	}
+	return false
+}
+
+// ForwardMessages posts the forwards to the room under the same rules as SendMessage.
+func (s *roomService) ForwardMessages(roomID, senderID string, forwards []domain.Forward) ([]*domain.RoomMessage, error) {
+	if _, err := s.checkCanPost(roomID, senderID); err != nil {
+		return nil, err
+	}
+	messages := make([]*domain.RoomMessage, 0, len(forwards))
+	for _, forward := range forwards {
+		origin := forward.Origin
+		message, err := s.createMessage(roomID, senderID, forward.Content, &origin, forward.Entities, nil)
 		if err != nil {
 			return nil, err
 		}
-		if role != domain.RoleOwner && role != domain.RoleAdmin {
-			return nil, errors.New("not authorized to send message in channel")
-		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+func (s *roomService) SendPoll(roomID, senderID string, poll *domain.Poll) (*domain.RoomMessage, error) {
+	now := time.Now()
+	if err := validatePoll(poll, now); err != nil {
+		return nil, err
+	}
+	if _, err := s.checkCanPost(roomID, senderID); err != nil {
+		return nil, err
 	}
 	message := &domain.RoomMessage{
 		ID:        uuid.New().String(),
 		RoomID:    roomID,
 		SenderID:  senderID,
-		Content:   content,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		Content:   poll.Question,
+		Kind:      domain.MessageKindPoll,
+		Poll:      poll,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	poll.MessageID, poll.RoomID, poll.CreatedAt = message.ID, roomID, now
+	if err := s.messageRepo.Create(message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// mentionFits reports whether a mention entity for match can be added to the
+// formatting entities: it must not touch a code or pre span, and any other
+// entity it overlaps must contain it or lie within it.
+func mentionFits(match domain.MentionMatch, formatting []domain.MessageEntity) bool {
+	start, end := match.Offset, match.Offset+match.Length
+	for _, entity := range formatting {
+		entityEnd := entity.Offset + entity.Length
+		if entityEnd <= start || entity.Offset >= end {
+			continue
+		}
+		if entity.Type.IsMonospace() {
+			return false
+		}
+		contains := entity.Offset <= start && entityEnd >= end
+		within := entity.Offset >= start && entityEnd <= end
+		if !contains && !within {
+			return false
+		}
+	}
+	return true
+}
+
+// checkCanPost rejects non-members, banned and muted senders, and in channels
+// anyone without the post permission. It returns the room.
+func (s *roomService) checkCanPost(roomID, senderID string) (*domain.Room, error) {
+	// Check membership, ban and mute status, lifting any that have expired.
+	membership, err := roomMembership(s.membershipRepo, s.restrictionRepo, roomID, senderID)
+	if err != nil {
+		return nil, err
+	}
+	if membership == nil {
+		return nil, ErrNotRoomMember
+	}
+	if membership.Role == domain.RoleBanned {
+		return nil, ErrBanned
+	}
+	mute, err := activeRestriction(s.membershipRepo, s.restrictionRepo, roomID, senderID, domain.RestrictionMute)
+	if err != nil {
+		return nil, err
+	}
+	if mute != nil {
+		return nil, ErrMuted
+	}
+	room, err := s.roomRepo.FindByID(roomID)
+	if err != nil || room == nil {
+		return nil, ErrRoomNotFound
+	}
+	// In channels, only admins allowed to post may send messages.
+	if room.Type == domain.RoomTypeChannel {
+		if _, err := requirePermission(s.membershipRepo, roomID, senderID, domain.PermPostMessages, "not authorized to send message in channel"); err != nil {
+			return nil, err
+		}
+	}
+	return room, nil
+}
+
+// createMessage stores a new message in the room, along with the users it mentions.
+func (s *roomService) createMessage(roomID, senderID, content string, forwardedFrom *domain.ForwardOrigin, entities []domain.MessageEntity, mentioned []string) (*domain.RoomMessage, error) {
+	message := &domain.RoomMessage{
+		ID:            uuid.New().String(),
+		RoomID:        roomID,
+		SenderID:      senderID,
+		Content:       content,
+		Kind:          domain.MessageKindText,
+		ForwardedFrom: forwardedFrom,
+		Entities:      entities,
+		PreviewURL:    domain.PreviewURL(content, entities),
+		Mentioned:     mentioned,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 	}
 	if err := s.messageRepo.Create(message); err != nil {
 		return nil, err
@@ -226,24 +1087,48 @@ func (s *roomService) SendMessage(roomID, senderID, content string) (*domain.Roo
 
 func (s *roomService) DeleteMessage(roomID, requesterID, messageID string) error {
 	message, err := s.messageRepo.FindByID(messageID)
-	if err != nil || message == nil {
+	if err != nil || message == nil || message.RoomID != roomID {
 		return errors.New("message not found")
 	}
-	role, err := s.membershipRepo.GetMemberRole(roomID, requesterID)
-	if err != nil {
+	// Anyone may delete their own message; others need the delete permission.
+	if message.SenderID == requesterID {
+		return s.messageRepo.Delete(messageID)
+	}
+	if _, err := requirePermission(s.membershipRepo, roomID, requesterID, domain.PermDeleteMessages, "not authorized to delete this message"); err != nil {
 		return err
 	}
-	// If requester is not owner/admin, they may only delete their own message.
-	if role != domain.RoleOwner && role != domain.RoleAdmin && message.SenderID != requesterID {
-		return errors.New("not authorized to delete this message")
+	if err := s.messageRepo.Delete(messageID); err != nil {
+		return err
 	}
-	return s.messageRepo.Delete(messageID)
+	return appendAudit(s.auditRepo, roomID, requesterID, domain.AuditDeleteMessage, messageID, message, nil)
 }
 
-func (s *roomService) GetMessages(roomID string) ([]*domain.RoomMessage, error) {
-	return s.messageRepo.FindByRoom(roomID)
+func (s *roomService) GetMessages(roomID, userID string) ([]*domain.RoomMessage, error) {
+	if err := checkReadAccess(nil, s.roomRepo, s.membershipRepo, s.restrictionRepo, userID, domain.ChatRef{Kind: domain.ChatRoom, ID: roomID}); err != nil {
+		return nil, err
+	}
+	messages, err := s.messageRepo.FindByRoom(roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, message := range messages {
+		if message.Poll != nil {
+			hideQuizAnswer(message.Poll, now)
+		}
+	}
+	return messages, nil
 }
 
 func (s *roomService) GetMembers(roomID string) ([]*domain.RoomMembership, error) {
 	return s.membershipRepo.GetMembers(roomID)
 }
+
+// isHTTPURL reports whether raw is an absolute http or https URL.
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -1,11 +1,14 @@
 package service
 
 import (
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
 	"social_media/internal/domain"
 	"social_media/internal/mocks"
 )
@@ -15,7 +18,10 @@ func TestCreateRoomSuccess(t *testing.T) {
 	roomRepoMock := new(mocks.RoomRepositoryMock)
 	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
 	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
-	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
 
 	roomRepoMock.On("Create", mock.AnythingOfType("*domain.Room")).Return(nil).Run(func(args mock.Arguments) {
 		r := args.Get(0).(*domain.Room)
@@ -36,13 +42,17 @@ func TestUpdateRoomUnauthorized(t *testing.T) {
 	roomRepoMock := new(mocks.RoomRepositoryMock)
 	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
 	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
-	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
 
 	room := &domain.Room{ID: "room1", Name: "Test Room", OwnerID: "owner1", UpdatedAt: time.Now()}
 	roomRepoMock.On("FindByID", "room1").Return(room, nil)
-	membershipRepoMock.On("GetMemberRole", "room1", "user2").Return(domain.RoleMember, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user2").Return(&domain.RoomMembership{Role: domain.RoleMember}, nil)
 
-	updatedRoom, err := roomService.UpdateRoom("room1", "user2", "New Room Name", "newusername")
+	update := RoomUpdate{Name: ptr("New Room Name"), Username: ptr("newusername")}
+	updatedRoom, err := roomService.UpdateRoom("room1", "user2", update)
 	assert.Nil(t, updatedRoom)
 	assert.EqualError(t, err, "not authorized to update room")
 	roomRepoMock.AssertExpectations(t)
@@ -54,22 +64,32 @@ func TestUpdateRoomSuccess(t *testing.T) {
 	roomRepoMock := new(mocks.RoomRepositoryMock)
 	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
 	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
-	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
 
 	room := &domain.Room{ID: "room1", Name: "Test Room", OwnerID: "owner1", UpdatedAt: time.Now()}
 	roomRepoMock.On("FindByID", "room1").Return(room, nil)
-	membershipRepoMock.On("GetMemberRole", "room1", "owner1").Return(domain.RoleOwner, nil)
+	membershipRepoMock.On("GetMembership", "room1", "owner1").Return(&domain.RoomMembership{Role: domain.RoleOwner}, nil)
 	roomRepoMock.On("Update", room).Return(nil).Run(func(args mock.Arguments) {
 		r := args.Get(0).(*domain.Room)
 		r.Name = "Updated Room Name"
 	})
+	auditRepoMock.On("Append", mock.MatchedBy(func(e *domain.RoomAuditEntry) bool {
+		return e.Action == domain.AuditUpdateRoom && e.ActorID == "owner1" &&
+			strings.Contains(string(e.Before), `"name":"Test Room"`) &&
+			strings.Contains(string(e.After), `"username":"updatedusername"`)
+	})).Return(nil)
 
-	updatedRoom, err := roomService.UpdateRoom("room1", "owner1", "Updated Room Name", "updatedusername")
+	update := RoomUpdate{Name: ptr("Updated Room Name"), Username: ptr("updatedusername")}
+	updatedRoom, err := roomService.UpdateRoom("room1", "owner1", update)
 	assert.NotNil(t, updatedRoom)
 	assert.Nil(t, err)
 	assert.Equal(t, "Updated Room Name", updatedRoom.Name)
 	roomRepoMock.AssertExpectations(t)
 	membershipRepoMock.AssertExpectations(t)
+	auditRepoMock.AssertExpectations(t)
 }
 
 // Test 4: Delete room unauthorized.
@@ -77,7 +97,10 @@ func TestDeleteRoomUnauthorized(t *testing.T) {
 	roomRepoMock := new(mocks.RoomRepositoryMock)
 	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
 	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
-	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
 
 	room := &domain.Room{ID: "room1", Name: "Test Room", OwnerID: "owner1"}
 	roomRepoMock.On("FindByID", "room1").Return(room, nil)
@@ -94,7 +117,10 @@ func TestAddMemberAlreadyExists(t *testing.T) {
 	roomRepoMock := new(mocks.RoomRepositoryMock)
 	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
 	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
-	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
 
 	room := &domain.Room{ID: "room1", Type: domain.RoomTypeGroup}
 	roomRepoMock.On("FindByID", "room1").Return(room, nil)
@@ -111,10 +137,13 @@ func TestRemoveMemberUnauthorized(t *testing.T) {
 	roomRepoMock := new(mocks.RoomRepositoryMock)
 	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
 	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
-	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
 
 	// Only set expectation for the requester (user3) since the code checks that role.
-	membershipRepoMock.On("GetMemberRole", "room1", "user3").Return(domain.RoleMember, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user3").Return(&domain.RoomMembership{Role: domain.RoleMember}, nil)
 
 	err := roomService.RemoveMember("room1", "user3", "user2")
 	assert.EqualError(t, err, "not authorized to remove member")
@@ -127,13 +156,16 @@ func TestPromoteMemberUnauthorized(t *testing.T) {
 	roomRepoMock := new(mocks.RoomRepositoryMock)
 	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
 	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
-	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
 
 	// Requester is not owner.
-	membershipRepoMock.On("GetMemberRole", "room1", "user3").Return(domain.RoleMember, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user3").Return(&domain.RoomMembership{Role: domain.RoleMember}, nil)
 
-	err := roomService.PromoteMember("room1", "user3", "user2")
-	assert.EqualError(t, err, "only owner can promote member")
+	err := roomService.PromoteMember("room1", "user3", "user2", domain.DefaultAdminPermissions)
+	assert.EqualError(t, err, "not authorized to promote member")
 	membershipRepoMock.AssertExpectations(t)
 }
 
@@ -142,12 +174,15 @@ func TestBanMemberUnauthorized(t *testing.T) {
 	roomRepoMock := new(mocks.RoomRepositoryMock)
 	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
 	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
-	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
 
 	// Requester is not owner/admin.
-	membershipRepoMock.On("GetMemberRole", "room1", "user3").Return(domain.RoleMember, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user3").Return(&domain.RoomMembership{Role: domain.RoleMember}, nil)
 
-	err := roomService.BanMember("room1", "user3", "user2")
+	err := roomService.BanMember("room1", "user3", "user2", "", nil)
 	assert.EqualError(t, err, "not authorized to ban member")
 	membershipRepoMock.AssertExpectations(t)
 }
@@ -157,11 +192,15 @@ func TestSendRoomMessageBannedUser(t *testing.T) {
 	roomRepoMock := new(mocks.RoomRepositoryMock)
 	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
 	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
-	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
 
-	membershipRepoMock.On("IsUserBanned", "room1", "user1").Return(true, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user1").Return(&domain.RoomMembership{RoomID: "room1", UserID: "user1", Role: domain.RoleBanned}, nil)
+	restrictionRepoMock.On("Find", "room1", "user1", domain.RestrictionBan).Return(nil, nil)
 
-	msg, err := roomService.SendMessage("room1", "user1", "Hello in room")
+	msg, err := roomService.SendMessage("room1", "user1", "Hello in room", nil)
 	assert.Nil(t, msg)
 	assert.EqualError(t, err, "you are banned from this room")
 	membershipRepoMock.AssertExpectations(t)
@@ -171,10 +210,14 @@ func TestSendRoomMessageSuccess(t *testing.T) {
 	roomRepoMock := new(mocks.RoomRepositoryMock)
 	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
 	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
-	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
 
-	// Arrange: User is not banned, and room exists.
-	membershipRepoMock.On("IsUserBanned", "room1", "user1").Return(false, nil)
+	// Arrange: User is a member who is not banned, and room exists.
+	membershipRepoMock.On("GetMembership", "room1", "user1").Return(&domain.RoomMembership{RoomID: "room1", UserID: "user1", Role: domain.RoleMember}, nil)
+	restrictionRepoMock.On("Find", "room1", "user1", domain.RestrictionMute).Return(nil, nil)
 	room := &domain.Room{ID: "room1", Type: domain.RoomTypeGroup}
 	roomRepoMock.On("FindByID", "room1").Return(room, nil)
 	roomMessageRepoMock.On("Create", mock.AnythingOfType("*domain.RoomMessage")).Return(nil).Run(func(args mock.Arguments) {
@@ -183,7 +226,7 @@ func TestSendRoomMessageSuccess(t *testing.T) {
 	})
 
 	// Act: User sends a message.
-	msg, err := roomService.SendMessage("room1", "user1", "Hello Room!")
+	msg, err := roomService.SendMessage("room1", "user1", "Hello Room!", nil)
 
 	// Assert: The message is created successfully.
 	assert.NotNil(t, msg)
@@ -198,11 +241,21 @@ func TestUnbanMemberSuccess(t *testing.T) {
 	roomRepoMock := new(mocks.RoomRepositoryMock)
 	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
 	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
-	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
 
-	// Arrange: Requester is admin.
-	membershipRepoMock.On("GetMemberRole", "room1", "admin1").Return(domain.RoleAdmin, nil)
+	// Arrange: Requester is admin and the target is banned.
+	membershipRepoMock.On("GetMembership", "room1", "admin1").Return(&domain.RoomMembership{Role: domain.RoleAdmin, Permissions: domain.DefaultAdminPermissions}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user2").Return(&domain.RoomMembership{Role: domain.RoleBanned}, nil)
+	restrictionRepoMock.On("Find", "room1", "user2", domain.RestrictionBan).Return(
+		&domain.RoomRestriction{RoomID: "room1", UserID: "user2", Kind: domain.RestrictionBan, PriorRole: domain.RoleMember}, nil)
 	membershipRepoMock.On("UpdateMemberRole", "room1", "user2", domain.RoleMember).Return(nil)
+	restrictionRepoMock.On("Delete", "room1", "user2", domain.RestrictionBan).Return(nil)
+	auditRepoMock.On("Append", mock.MatchedBy(func(e *domain.RoomAuditEntry) bool {
+		return e.Action == domain.AuditUnban && *e.TargetID == "user2"
+	})).Return(nil)
 
 	// Act: Admin unbans a member.
 	err := roomService.UnbanMember("room1", "admin1", "user2")
@@ -217,7 +270,10 @@ func TestDeleteRoomSuccess(t *testing.T) {
 	roomRepoMock := new(mocks.RoomRepositoryMock)
 	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
 	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
-	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
 
 	// Arrange: Room exists and requester is owner.
 	room := &domain.Room{ID: "room1", OwnerID: "owner1"}
@@ -234,3 +290,880 @@ func TestDeleteRoomSuccess(t *testing.T) {
 	membershipRepoMock.AssertExpectations(t)
 }
 
+//Test 13 Partial room update keeps omitted fields
+func TestUpdateRoomPartial(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	// Arrange: Room has a name and rules; only the description is patched.
+	room := &domain.Room{ID: "room1", Name: "Test Room", Rules: "Be nice", OwnerID: "owner1"}
+	roomRepoMock.On("FindByID", "room1").Return(room, nil)
+	membershipRepoMock.On("GetMembership", "room1", "owner1").Return(&domain.RoomMembership{Role: domain.RoleOwner}, nil)
+	roomRepoMock.On("Update", room).Return(nil)
+	auditRepoMock.On("Append", mock.AnythingOfType("*domain.RoomAuditEntry")).Return(nil)
+
+	// Act: Owner sets only the description.
+	updatedRoom, err := roomService.UpdateRoom("room1", "owner1", RoomUpdate{Description: ptr("About us")})
+
+	// Assert: Description changes, other fields are untouched.
+	assert.Nil(t, err)
+	assert.Equal(t, "Test Room", updatedRoom.Name)
+	assert.Equal(t, "Be nice", updatedRoom.Rules)
+	assert.Equal(t, "About us", updatedRoom.Description)
+	roomRepoMock.AssertExpectations(t)
+	membershipRepoMock.AssertExpectations(t)
+}
+
+//Test 14 Update room with an invalid photo url
+func TestUpdateRoomInvalidPhotoURL(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	room := &domain.Room{ID: "room1", Name: "Test Room", OwnerID: "owner1"}
+	roomRepoMock.On("FindByID", "room1").Return(room, nil)
+	membershipRepoMock.On("GetMembership", "room1", "owner1").Return(&domain.RoomMembership{Role: domain.RoleOwner}, nil)
+
+	updatedRoom, err := roomService.UpdateRoom("room1", "owner1", RoomUpdate{PhotoURL: ptr("javascript:alert(1)")})
+	assert.Nil(t, updatedRoom)
+	assert.EqualError(t, err, "invalid photo url")
+	roomRepoMock.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+//Test 15 Get room card with member count
+func TestGetRoomSuccess(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	room := &domain.Room{ID: "room1", Name: "Test Room", Description: "About us"}
+	roomRepoMock.On("FindByID", "room1").Return(room, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user1").Return(&domain.RoomMembership{RoomID: "room1", UserID: "user1", Role: domain.RoleMember}, nil)
+	membershipRepoMock.On("CountMembers", "room1").Return(3, nil)
+
+	details, err := roomService.GetRoom("room1", "user1")
+	assert.Nil(t, err)
+	assert.Equal(t, "About us", details.Description)
+	assert.Equal(t, 3, details.MemberCount)
+	roomRepoMock.AssertExpectations(t)
+	membershipRepoMock.AssertExpectations(t)
+}
+
+//Test 16 Join a private room
+func TestJoinRoomPrivate(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	// Arrange: Room has no username, so it is private.
+	room := &domain.Room{ID: "room1", Type: domain.RoomTypeGroup}
+	roomRepoMock.On("FindByID", "room1").Return(room, nil)
+
+	err := roomService.JoinRoom("room1", "user1")
+	assert.EqualError(t, err, "room is private")
+	membershipRepoMock.AssertNotCalled(t, "AddMember", mock.Anything)
+}
+
+//Test 17 Banned user cannot rejoin a public room
+func TestJoinRoomBanned(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	room := &domain.Room{ID: "room1", Username: ptr("public_room"), Type: domain.RoomTypeGroup}
+	roomRepoMock.On("FindByID", "room1").Return(room, nil)
+	membershipRepoMock.On("GetMemberRole", "room1", "user1").Return(domain.RoleBanned, nil)
+	restrictionRepoMock.On("Find", "room1", "user1", domain.RestrictionBan).Return(nil, nil)
+
+	err := roomService.JoinRoom("room1", "user1")
+	assert.EqualError(t, err, "you are banned from this room")
+	membershipRepoMock.AssertNotCalled(t, "AddMember", mock.Anything)
+}
+
+//Test 18 Subscribe to a public channel
+func TestJoinRoomPublicChannel(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	room := &domain.Room{ID: "room1", Username: ptr("news"), Type: domain.RoomTypeChannel}
+	roomRepoMock.On("FindByID", "room1").Return(room, nil)
+	membershipRepoMock.On("GetMemberRole", "room1", "user1").Return(domain.RoomMembershipRole(""), nil)
+	membershipRepoMock.On("AddMember", mock.MatchedBy(func(m *domain.RoomMembership) bool {
+		return m.RoomID == "room1" && m.UserID == "user1" && m.Role == domain.RoleMember
+	})).Return(nil)
+
+	err := roomService.JoinRoom("room1", "user1")
+	assert.Nil(t, err)
+	roomRepoMock.AssertExpectations(t)
+	membershipRepoMock.AssertExpectations(t)
+}
+
+//Test 19 Preview a public room by username
+func TestGetPublicRoomNotFound(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	roomRepoMock.On("FindByUsername", "missing").Return(nil, nil)
+
+	room, err := roomService.GetPublicRoom("missing")
+	assert.Nil(t, room)
+	assert.EqualError(t, err, "room not found")
+	roomRepoMock.AssertExpectations(t)
+}
+
+//Test 20 Join a public room in request-to-join mode
+func TestJoinRoomRequiresRequest(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	room := &domain.Room{ID: "room1", Username: ptr("club"), Type: domain.RoomTypeGroup, JoinByRequest: true}
+	roomRepoMock.On("FindByID", "room1").Return(room, nil)
+
+	err := roomService.JoinRoom("room1", "user1")
+	assert.EqualError(t, err, "room requires a join request")
+	membershipRepoMock.AssertNotCalled(t, "AddMember", mock.Anything)
+}
+
+//Test 21 Admin cannot ban the owner
+func TestBanMemberAdminCannotBanOwner(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	membershipRepoMock.On("GetMembership", "room1", "admin1").Return(&domain.RoomMembership{Role: domain.RoleAdmin, Permissions: domain.DefaultAdminPermissions}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "owner1").Return(&domain.RoomMembership{Role: domain.RoleOwner}, nil)
+
+	err := roomService.BanMember("room1", "admin1", "owner1", "", nil)
+	assert.Equal(t, ErrInsufficientRank, err)
+	assert.True(t, errors.Is(err, ErrForbidden))
+	membershipRepoMock.AssertNotCalled(t, "UpdateMemberRole", mock.Anything, mock.Anything, mock.Anything)
+}
+
+//Test 22 Admin cannot kick another admin
+func TestRemoveMemberAdminCannotRemoveAdmin(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	membershipRepoMock.On("GetMembership", "room1", "admin1").Return(&domain.RoomMembership{Role: domain.RoleAdmin, Permissions: domain.DefaultAdminPermissions}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "admin2").Return(&domain.RoomMembership{Role: domain.RoleAdmin}, nil)
+
+	err := roomService.RemoveMember("room1", "admin1", "admin2")
+	assert.Equal(t, ErrInsufficientRank, err)
+	membershipRepoMock.AssertNotCalled(t, "RemoveMember", mock.Anything, mock.Anything)
+}
+
+//Test 23 Unban a user who was never banned
+func TestUnbanMemberNotBanned(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	membershipRepoMock.On("GetMembership", "room1", "admin1").Return(&domain.RoomMembership{Role: domain.RoleAdmin, Permissions: domain.DefaultAdminPermissions}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user2").Return(&domain.RoomMembership{Role: domain.RoleMember}, nil)
+
+	err := roomService.UnbanMember("room1", "admin1", "user2")
+	assert.True(t, errors.Is(err, ErrInvalidRoleTransition))
+	assert.EqualError(t, err, "invalid role transition: member to member")
+	membershipRepoMock.AssertNotCalled(t, "UpdateMemberRole", mock.Anything, mock.Anything, mock.Anything)
+}
+
+//Test 24 Unban a user who is not in the room
+func TestUnbanMemberNotInRoom(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	membershipRepoMock.On("GetMembership", "room1", "admin1").Return(&domain.RoomMembership{Role: domain.RoleAdmin, Permissions: domain.DefaultAdminPermissions}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "stranger").Return(nil, nil)
+
+	err := roomService.UnbanMember("room1", "admin1", "stranger")
+	assert.Equal(t, ErrNotRoomMember, err)
+}
+
+//Test 25 Admin bans a member
+func TestBanMemberSuccess(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	membershipRepoMock.On("GetMembership", "room1", "admin1").Return(&domain.RoomMembership{Role: domain.RoleAdmin, Permissions: domain.DefaultAdminPermissions}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user2").Return(&domain.RoomMembership{Role: domain.RoleMember}, nil)
+	restrictionRepoMock.On("Upsert", mock.MatchedBy(func(r *domain.RoomRestriction) bool {
+		return r.UserID == "user2" && r.Kind == domain.RestrictionBan && r.PriorRole == domain.RoleMember && r.ExpiresAt == nil
+	})).Return(nil)
+	membershipRepoMock.On("UpdateMemberRole", "room1", "user2", domain.RoleBanned).Return(nil)
+	auditRepoMock.On("Append", mock.AnythingOfType("*domain.RoomAuditEntry")).Return(nil)
+
+	err := roomService.BanMember("room1", "admin1", "user2", "", nil)
+	assert.Nil(t, err)
+	membershipRepoMock.AssertExpectations(t)
+	restrictionRepoMock.AssertExpectations(t)
+}
+
+//Test 26 Banned user cannot lift their ban by leaving
+func TestRemoveMemberBannedSelf(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	membershipRepoMock.On("GetMemberRole", "room1", "user2").Return(domain.RoleBanned, nil)
+
+	err := roomService.RemoveMember("room1", "user2", "user2")
+	assert.True(t, errors.Is(err, ErrInvalidRoleTransition))
+	membershipRepoMock.AssertNotCalled(t, "RemoveMember", mock.Anything, mock.Anything)
+}
+
+//Test 27 Owner cannot promote themselves
+func TestPromoteMemberSelf(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	membershipRepoMock.On("GetMembership", "room1", "owner1").Return(&domain.RoomMembership{Role: domain.RoleOwner}, nil)
+
+	err := roomService.PromoteMember("room1", "owner1", "owner1", domain.DefaultAdminPermissions)
+	assert.Equal(t, ErrInsufficientRank, err)
+	membershipRepoMock.AssertNotCalled(t, "SetAdmin", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test 28: Promote member with explicit permissions.
+func TestPromoteMemberWithPermissions(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	perms := domain.PermDeleteMessages | domain.PermPinMessages
+	membershipRepoMock.On("GetMembership", "room1", "owner1").Return(&domain.RoomMembership{Role: domain.RoleOwner}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user2").Return(&domain.RoomMembership{Role: domain.RoleMember}, nil)
+	membershipRepoMock.On("SetAdmin", "room1", "user2", perms, "owner1").Return(nil)
+	auditRepoMock.On("Append", mock.AnythingOfType("*domain.RoomAuditEntry")).Return(nil)
+
+	err := roomService.PromoteMember("room1", "owner1", "user2", perms)
+	assert.Nil(t, err)
+	membershipRepoMock.AssertExpectations(t)
+}
+
+// Test 29: An admin cannot grant permissions they do not hold.
+func TestPromoteMemberCannotEscalate(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	admin := &domain.RoomMembership{Role: domain.RoleAdmin, Permissions: domain.PermManageAdmins | domain.PermPinMessages}
+	membershipRepoMock.On("GetMembership", "room1", "admin1").Return(admin, nil)
+
+	err := roomService.PromoteMember("room1", "admin1", "user2", domain.PermPinMessages|domain.PermBanUsers)
+	assert.Equal(t, ErrPermissionNotHeld, err)
+	membershipRepoMock.AssertNotCalled(t, "SetAdmin", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test 30: An admin without the change-info permission cannot update the room.
+func TestUpdateRoomAdminWithoutPermission(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	room := &domain.Room{ID: "room1", Name: "Room", OwnerID: "owner1"}
+	roomRepoMock.On("FindByID", "room1").Return(room, nil)
+	admin := &domain.RoomMembership{Role: domain.RoleAdmin, Permissions: domain.PermBanUsers}
+	membershipRepoMock.On("GetMembership", "room1", "admin1").Return(admin, nil)
+
+	_, err := roomService.UpdateRoom("room1", "admin1", RoomUpdate{Name: ptr("New")})
+	assert.True(t, errors.Is(err, ErrForbidden))
+	roomRepoMock.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+// Test 31: Admins cannot delete the room.
+func TestDeleteRoomAdmin(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	room := &domain.Room{ID: "room1", Name: "Room", OwnerID: "owner1"}
+	roomRepoMock.On("FindByID", "room1").Return(room, nil)
+	membershipRepoMock.On("GetMemberRole", "room1", "admin1").Return(domain.RoleAdmin, nil)
+
+	err := roomService.DeleteRoom("room1", "admin1")
+	assert.EqualError(t, err, "not authorized to delete room")
+	roomRepoMock.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+// Test 32: Transfer ownership with the wrong password.
+func TestTransferOwnershipWrongPassword(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	roomRepoMock.On("FindByID", "room1").Return(&domain.Room{ID: "room1", OwnerID: "owner1"}, nil)
+	membershipRepoMock.On("GetMemberRole", "room1", "owner1").Return(domain.RoleOwner, nil)
+	userRepoMock.On("FindByID", "owner1").Return(&domain.User{ID: "owner1", Password: string(hashed)}, nil)
+
+	err := roomService.TransferOwnership("room1", "owner1", "user2", "wrong")
+	assert.Equal(t, ErrInvalidPassword, err)
+	roomRepoMock.AssertNotCalled(t, "TransferOwnership", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test 33: Transfer ownership successfully.
+func TestTransferOwnershipSuccess(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	roomRepoMock.On("FindByID", "room1").Return(&domain.Room{ID: "room1", OwnerID: "owner1"}, nil)
+	membershipRepoMock.On("GetMemberRole", "room1", "owner1").Return(domain.RoleOwner, nil)
+	membershipRepoMock.On("GetMemberRole", "room1", "user2").Return(domain.RoleMember, nil)
+	userRepoMock.On("FindByID", "owner1").Return(&domain.User{ID: "owner1", Password: string(hashed)}, nil)
+	roomRepoMock.On("TransferOwnership", "room1", "owner1", "user2").Return(nil)
+	auditRepoMock.On("Append", mock.AnythingOfType("*domain.RoomAuditEntry")).Return(nil)
+
+	err := roomService.TransferOwnership("room1", "owner1", "user2", "secret")
+	assert.Nil(t, err)
+	roomRepoMock.AssertExpectations(t)
+}
+
+// Test 34: The owner cannot leave while no admin can take over.
+func TestRemoveMemberOwnerLeavesWithoutAdmins(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	membershipRepoMock.On("GetMemberRole", "room1", "owner1").Return(domain.RoleOwner, nil)
+	membershipRepoMock.On("GetMembers", "room1").Return([]*domain.RoomMembership{
+		{RoomID: "room1", UserID: "owner1", Role: domain.RoleOwner},
+		{RoomID: "room1", UserID: "user2", Role: domain.RoleMember},
+	}, nil)
+
+	err := roomService.RemoveMember("room1", "owner1", "owner1")
+	assert.Equal(t, ErrOwnerMustTransfer, err)
+	membershipRepoMock.AssertNotCalled(t, "RemoveMember", mock.Anything, mock.Anything)
+}
+
+// Test 35: A leaving owner hands the room to the oldest admin.
+func TestRemoveMemberOwnerSuccession(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	now := time.Now()
+	membershipRepoMock.On("GetMemberRole", "room1", "owner1").Return(domain.RoleOwner, nil)
+	membershipRepoMock.On("GetMembers", "room1").Return([]*domain.RoomMembership{
+		{RoomID: "room1", UserID: "owner1", Role: domain.RoleOwner, CreatedAt: now.Add(-3 * time.Hour)},
+		{RoomID: "room1", UserID: "admin2", Role: domain.RoleAdmin, CreatedAt: now.Add(-time.Hour)},
+		{RoomID: "room1", UserID: "admin1", Role: domain.RoleAdmin, CreatedAt: now.Add(-2 * time.Hour)},
+	}, nil)
+	roomRepoMock.On("TransferOwnership", "room1", "owner1", "admin1").Return(nil)
+	auditRepoMock.On("Append", mock.AnythingOfType("*domain.RoomAuditEntry")).Return(nil)
+	membershipRepoMock.On("RemoveMember", "room1", "owner1").Return(nil)
+
+	err := roomService.RemoveMember("room1", "owner1", "owner1")
+	assert.Nil(t, err)
+	roomRepoMock.AssertExpectations(t)
+	membershipRepoMock.AssertExpectations(t)
+}
+
+// Test 36: The owner demotes an admin.
+func TestDemoteMemberByOwner(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	membershipRepoMock.On("GetMembership", "room1", "owner1").Return(&domain.RoomMembership{Role: domain.RoleOwner}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "admin1").Return(&domain.RoomMembership{Role: domain.RoleAdmin, PromotedBy: ptr("admin2")}, nil)
+	membershipRepoMock.On("UpdateMemberRole", "room1", "admin1", domain.RoleMember).Return(nil)
+	auditRepoMock.On("Append", mock.MatchedBy(func(e *domain.RoomAuditEntry) bool {
+		return e.Action == domain.AuditDemote && *e.TargetID == "admin1"
+	})).Return(nil)
+
+	err := roomService.DemoteMember("room1", "owner1", "admin1")
+	assert.Nil(t, err)
+	membershipRepoMock.AssertExpectations(t)
+}
+
+// Test 37: An admin cannot demote an admin someone else promoted.
+func TestDemoteMemberNotPromotedByRequester(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	manager := &domain.RoomMembership{Role: domain.RoleAdmin, Permissions: domain.AllAdminPermissions}
+	membershipRepoMock.On("GetMembership", "room1", "admin1").Return(manager, nil)
+	membershipRepoMock.On("GetMembership", "room1", "admin2").Return(&domain.RoomMembership{Role: domain.RoleAdmin, PromotedBy: ptr("owner1")}, nil)
+
+	err := roomService.DemoteMember("room1", "admin1", "admin2")
+	assert.EqualError(t, err, "can only demote admins you promoted")
+	membershipRepoMock.AssertNotCalled(t, "UpdateMemberRole", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test 38: Demoting someone who is not an admin is an invalid transition.
+func TestDemoteMemberNotAdmin(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	membershipRepoMock.On("GetMembership", "room1", "owner1").Return(&domain.RoomMembership{Role: domain.RoleOwner}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user2").Return(&domain.RoomMembership{Role: domain.RoleMember}, nil)
+
+	err := roomService.DemoteMember("room1", "owner1", "user2")
+	assert.True(t, errors.Is(err, ErrInvalidRoleTransition))
+}
+
+// Test 39: A ban cannot end in the past.
+func TestBanMemberPastDeadline(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	membershipRepoMock.On("GetMembership", "room1", "owner1").Return(&domain.RoomMembership{Role: domain.RoleOwner}, nil)
+
+	past := time.Now().Add(-time.Minute)
+	err := roomService.BanMember("room1", "owner1", "user2", "spam", &past)
+	assert.EqualError(t, err, "ban end must be in the future")
+	restrictionRepoMock.AssertNotCalled(t, "Upsert", mock.Anything)
+}
+
+// Test 40: A muted member cannot send messages.
+func TestSendRoomMessageMuted(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	until := time.Now().Add(time.Hour)
+	membershipRepoMock.On("GetMembership", "room1", "user1").Return(&domain.RoomMembership{RoomID: "room1", UserID: "user1", Role: domain.RoleMember}, nil)
+	restrictionRepoMock.On("Find", "room1", "user1", domain.RestrictionMute).Return(
+		&domain.RoomRestriction{RoomID: "room1", UserID: "user1", Kind: domain.RestrictionMute, ExpiresAt: &until}, nil)
+
+	msg, err := roomService.SendMessage("room1", "user1", "Hello", nil)
+	assert.Nil(t, msg)
+	assert.Equal(t, ErrMuted, err)
+	roomMessageRepoMock.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// Test 41: An expired mute is lifted when the member next sends a message.
+func TestSendRoomMessageExpiredMute(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	expired := time.Now().Add(-time.Minute)
+	membershipRepoMock.On("GetMembership", "room1", "user1").Return(&domain.RoomMembership{RoomID: "room1", UserID: "user1", Role: domain.RoleMember}, nil)
+	restrictionRepoMock.On("Find", "room1", "user1", domain.RestrictionMute).Return(
+		&domain.RoomRestriction{RoomID: "room1", UserID: "user1", Kind: domain.RestrictionMute, ExpiresAt: &expired}, nil)
+	restrictionRepoMock.On("Delete", "room1", "user1", domain.RestrictionMute).Return(nil)
+	roomRepoMock.On("FindByID", "room1").Return(&domain.Room{ID: "room1", Type: domain.RoomTypeGroup}, nil)
+	roomMessageRepoMock.On("Create", mock.AnythingOfType("*domain.RoomMessage")).Return(nil)
+
+	msg, err := roomService.SendMessage("room1", "user1", "Hello", nil)
+	assert.Nil(t, err)
+	assert.NotNil(t, msg)
+	restrictionRepoMock.AssertExpectations(t)
+}
+
+// Test 42: The sweeper restores an admin's role and permissions when their ban expires.
+func TestExpireRestrictionsRestoresPriorRole(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	expired := time.Now().Add(-time.Minute)
+	ban := &domain.RoomRestriction{
+		RoomID: "room1", UserID: "admin1", Kind: domain.RestrictionBan,
+		PriorRole: domain.RoleAdmin, PriorPermissions: domain.PermPinMessages, ExpiresAt: &expired,
+	}
+	restrictionRepoMock.On("FindExpired", mock.AnythingOfType("time.Time"), expiredRestrictionBatch).Return([]*domain.RoomRestriction{ban}, nil)
+	membershipRepoMock.On("UpdateMemberRole", "room1", "admin1", domain.RoleAdmin).Return(nil)
+	membershipRepoMock.On("UpdateMemberPermissions", "room1", "admin1", domain.PermPinMessages).Return(nil)
+	restrictionRepoMock.On("Delete", "room1", "admin1", domain.RestrictionBan).Return(nil)
+
+	lifted, err := roomService.ExpireRestrictions()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, lifted)
+	membershipRepoMock.AssertExpectations(t)
+	restrictionRepoMock.AssertExpectations(t)
+}
+
+// Test 43: Users whose group invite privacy excludes the requester cannot be added.
+func TestAddMemberGroupInvitesRestricted(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, new(mocks.RoomMessageRepositoryMock), userRepoMock, new(mocks.RoomAuditRepositoryMock), new(mocks.RoomRestrictionRepositoryMock), new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	privacy := domain.DefaultPrivacySettings()
+	privacy.GroupInvites = domain.AudienceNobody
+	roomRepoMock.On("FindByID", "room1").Return(&domain.Room{ID: "room1", Type: domain.RoomTypeGroup}, nil)
+	membershipRepoMock.On("GetMemberRole", "room1", "user2").Return(domain.RoomMembershipRole(""), nil)
+	userRepoMock.On("FindByID", "user2").Return(&domain.User{ID: "user2", Privacy: privacy}, nil)
+
+	err := roomService.AddMember("room1", "owner1", "user2")
+	assert.ErrorIs(t, err, ErrForbidden)
+	membershipRepoMock.AssertNotCalled(t, "AddMember", mock.Anything)
+}
+
+// Test 44: Mentioning a member stores a mention entity with UTF-16 offsets and notifies them;
+// mentions of non-members stay plain text.
+func TestSendRoomMessageMentions(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	notificationRepoMock := new(mocks.NotificationRepositoryMock)
+	chatStateRepoMock := new(mocks.ChatStateRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, new(mocks.RoomAuditRepositoryMock), restrictionRepoMock, new(mocks.ContactRepositoryMock), notificationRepoMock, chatStateRepoMock)
+
+	membershipRepoMock.On("GetMembership", "room1", "user1").Return(&domain.RoomMembership{RoomID: "room1", UserID: "user1", Role: domain.RoleMember}, nil)
+	restrictionRepoMock.On("Find", "room1", "user1", domain.RestrictionMute).Return(nil, nil)
+	roomRepoMock.On("FindByID", "room1").Return(&domain.Room{ID: "room1", Type: domain.RoomTypeGroup}, nil)
+	userRepoMock.On("FindByUsername", "@bob").Return(&domain.User{ID: "user2"}, nil)
+	userRepoMock.On("FindByUsername", "@carol").Return(&domain.User{ID: "user3"}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user2").Return(&domain.RoomMembership{RoomID: "room1", UserID: "user2", Role: domain.RoleMember}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user3").Return(nil, nil)
+	roomMessageRepoMock.On("Create", mock.MatchedBy(func(m *domain.RoomMessage) bool {
+		return len(m.Mentioned) == 1 && m.Mentioned[0] == "user2"
+	})).Return(nil)
+	chatStateRepoMock.On("FindMuted", domain.ChatRef{Kind: domain.ChatRoom, ID: "room1"}, []string{"user2"}, mock.AnythingOfType("time.Time")).Return(nil, nil)
+	notificationRepoMock.On("CreateBatch", mock.MatchedBy(func(n []*domain.Notification) bool {
+		return len(n) == 1 && n[0].UserID == "user2" && n[0].Type == domain.NotificationMention
+	})).Return(nil)
+
+	msg, err := roomService.SendMessage("room1", "user1", "👋 @bob, ask @carol", nil)
+	assert.Nil(t, err)
+	assert.Len(t, msg.Entities, 1)
+	assert.Equal(t, domain.EntityMention, msg.Entities[0].Type)
+	assert.Equal(t, 3, msg.Entities[0].Offset) // the emoji is a surrogate pair
+	assert.Equal(t, 4, msg.Entities[0].Length)
+	assert.Equal(t, "user2", *msg.Entities[0].UserID)
+	notificationRepoMock.AssertExpectations(t)
+}
+
+// Test 45: @admins notifies the owner and admins who have not muted the room, but @all from a
+// regular member of a group is ignored.
+func TestSendRoomMessageMentionAdminsAndAll(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	notificationRepoMock := new(mocks.NotificationRepositoryMock)
+	chatStateRepoMock := new(mocks.ChatStateRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, new(mocks.UserRepositoryMock), new(mocks.RoomAuditRepositoryMock), restrictionRepoMock, new(mocks.ContactRepositoryMock), notificationRepoMock, chatStateRepoMock)
+
+	membershipRepoMock.On("GetMembership", "room1", "user1").Return(&domain.RoomMembership{RoomID: "room1", UserID: "user1", Role: domain.RoleMember}, nil)
+	restrictionRepoMock.On("Find", "room1", "user1", domain.RestrictionMute).Return(nil, nil)
+	roomRepoMock.On("FindByID", "room1").Return(&domain.Room{ID: "room1", Type: domain.RoomTypeGroup}, nil)
+	membershipRepoMock.On("GetMembers", "room1").Return([]*domain.RoomMembership{
+		{UserID: "owner1", Role: domain.RoleOwner},
+		{UserID: "admin1", Role: domain.RoleAdmin},
+		{UserID: "user1", Role: domain.RoleMember},
+		{UserID: "user2", Role: domain.RoleMember},
+	}, nil)
+	roomMessageRepoMock.On("Create", mock.MatchedBy(func(m *domain.RoomMessage) bool {
+		return len(m.Mentioned) == 2
+	})).Return(nil)
+	chatStateRepoMock.On("FindMuted", domain.ChatRef{Kind: domain.ChatRoom, ID: "room1"}, []string{"owner1", "admin1"}, mock.AnythingOfType("time.Time")).Return([]string{"admin1"}, nil)
+	notificationRepoMock.On("CreateBatch", mock.MatchedBy(func(n []*domain.Notification) bool {
+		return len(n) == 1 && n[0].UserID == "owner1"
+	})).Return(nil)
+
+	msg, err := roomService.SendMessage("room1", "user1", "@all spam here, @admins please help", nil)
+	assert.Nil(t, err)
+	assert.Len(t, msg.Entities, 1)
+	assert.Equal(t, domain.EntityMentionAdmins, msg.Entities[0].Type)
+	notificationRepoMock.AssertExpectations(t)
+}
+
+// Test 46: Formatting entities are kept alongside resolved mentions, ordered by offset.
+func TestSendRoomMessageFormattingWithMentions(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	notificationRepoMock := new(mocks.NotificationRepositoryMock)
+	chatStateRepoMock := new(mocks.ChatStateRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, new(mocks.RoomAuditRepositoryMock), restrictionRepoMock, new(mocks.ContactRepositoryMock), notificationRepoMock, chatStateRepoMock)
+
+	membershipRepoMock.On("GetMembership", "room1", "user1").Return(&domain.RoomMembership{RoomID: "room1", UserID: "user1", Role: domain.RoleMember}, nil)
+	restrictionRepoMock.On("Find", "room1", "user1", domain.RestrictionMute).Return(nil, nil)
+	roomRepoMock.On("FindByID", "room1").Return(&domain.Room{ID: "room1", Type: domain.RoomTypeGroup}, nil)
+	userRepoMock.On("FindByUsername", "@bob").Return(&domain.User{ID: "user2"}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user2").Return(&domain.RoomMembership{RoomID: "room1", UserID: "user2", Role: domain.RoleMember}, nil)
+	roomMessageRepoMock.On("Create", mock.AnythingOfType("*domain.RoomMessage")).Return(nil)
+	chatStateRepoMock.On("FindMuted", domain.ChatRef{Kind: domain.ChatRoom, ID: "room1"}, []string{"user2"}, mock.AnythingOfType("time.Time")).Return(nil, nil)
+	notificationRepoMock.On("CreateBatch", mock.AnythingOfType("[]*domain.Notification")).Return(nil)
+
+	msg, err := roomService.SendMessage("room1", "user1", "Deploy is done @bob", []domain.MessageEntity{
+		{Type: domain.EntitySpoiler, Offset: 10, Length: 4},
+		{Type: domain.EntityBold, Offset: 0, Length: 6},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, msg.Entities, 3)
+	assert.Equal(t, domain.EntityBold, msg.Entities[0].Type)
+	assert.Equal(t, domain.EntitySpoiler, msg.Entities[1].Type)
+	assert.Equal(t, domain.EntityMention, msg.Entities[2].Type)
+	assert.Equal(t, 15, msg.Entities[2].Offset)
+}
+
+// Test 47: A failure to deliver mention notifications does not fail the send,
+// since the message is already stored.
+func TestSendRoomMessageNotificationFailure(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	notificationRepoMock := new(mocks.NotificationRepositoryMock)
+	chatStateRepoMock := new(mocks.ChatStateRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, new(mocks.RoomAuditRepositoryMock), restrictionRepoMock, new(mocks.ContactRepositoryMock), notificationRepoMock, chatStateRepoMock)
+
+	membershipRepoMock.On("GetMembership", "room1", "user1").Return(&domain.RoomMembership{RoomID: "room1", UserID: "user1", Role: domain.RoleMember}, nil)
+	restrictionRepoMock.On("Find", "room1", "user1", domain.RestrictionMute).Return(nil, nil)
+	roomRepoMock.On("FindByID", "room1").Return(&domain.Room{ID: "room1", Type: domain.RoomTypeGroup}, nil)
+	userRepoMock.On("FindByUsername", "@bob").Return(&domain.User{ID: "user2"}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user2").Return(&domain.RoomMembership{RoomID: "room1", UserID: "user2", Role: domain.RoleMember}, nil)
+	roomMessageRepoMock.On("Create", mock.AnythingOfType("*domain.RoomMessage")).Return(nil)
+	chatStateRepoMock.On("FindMuted", domain.ChatRef{Kind: domain.ChatRoom, ID: "room1"}, []string{"user2"}, mock.AnythingOfType("time.Time")).Return(nil, nil)
+	notificationRepoMock.On("CreateBatch", mock.AnythingOfType("[]*domain.Notification")).Return(errors.New("connection reset"))
+
+	msg, err := roomService.SendMessage("room1", "user1", "Ping @bob", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"user2"}, msg.Mentioned)
+	roomMessageRepoMock.AssertNumberOfCalls(t, "Create", 1)
+}
+
+// Test 48: A private room card is hidden from non-members, while a public one is shown to anyone.
+func TestGetRoomPrivateNonMember(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, new(mocks.RoomMessageRepositoryMock), new(mocks.UserRepositoryMock), new(mocks.RoomAuditRepositoryMock), new(mocks.RoomRestrictionRepositoryMock), new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	username := "public_room"
+	roomRepoMock.On("FindByID", "private1").Return(&domain.Room{ID: "private1", Type: domain.RoomTypeGroup}, nil)
+	roomRepoMock.On("FindByID", "public1").Return(&domain.Room{ID: "public1", Type: domain.RoomTypeGroup, Username: &username}, nil)
+	roomRepoMock.On("FindByID", "broken").Return(nil, errors.New("connection reset"))
+	membershipRepoMock.On("GetMembership", "private1", "user2").Return(nil, nil)
+	membershipRepoMock.On("CountMembers", "public1").Return(10, nil)
+
+	details, err := roomService.GetRoom("private1", "user2")
+	assert.Nil(t, details)
+	assert.ErrorIs(t, err, ErrRoomNotFound)
+	membershipRepoMock.AssertNotCalled(t, "CountMembers", "private1")
+
+	details, err = roomService.GetRoom("public1", "user2")
+	assert.Nil(t, err)
+	assert.Equal(t, 10, details.MemberCount)
+
+	// Lookup failures are not reported as a missing room.
+	_, err = roomService.GetRoom("broken", "user2")
+	assert.EqualError(t, err, "connection reset")
+}
+
+// Test 49: Unbanning an admin or a member who was never banned fails and leaves their role unchanged.
+func TestUnbanMemberNotBannedKeepsRole(t *testing.T) {
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	auditRepoMock := new(mocks.RoomAuditRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	roomService := NewRoomService(new(mocks.RoomRepositoryMock), membershipRepoMock, new(mocks.RoomMessageRepositoryMock), new(mocks.UserRepositoryMock), auditRepoMock, restrictionRepoMock, new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	membershipRepoMock.On("GetMembership", "room1", "owner1").Return(&domain.RoomMembership{Role: domain.RoleOwner}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "admin1").Return(&domain.RoomMembership{Role: domain.RoleAdmin, Permissions: domain.DefaultAdminPermissions}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user2").Return(&domain.RoomMembership{Role: domain.RoleMember}, nil)
+
+	err := roomService.UnbanMember("room1", "owner1", "admin1")
+	assert.ErrorIs(t, err, ErrInvalidRoleTransition)
+	err = roomService.UnbanMember("room1", "owner1", "user2")
+	assert.ErrorIs(t, err, ErrInvalidRoleTransition)
+
+	membershipRepoMock.AssertNotCalled(t, "UpdateMemberRole", mock.Anything, mock.Anything, mock.Anything)
+	restrictionRepoMock.AssertNotCalled(t, "Find", mock.Anything, mock.Anything, mock.Anything)
+	auditRepoMock.AssertNotCalled(t, "Append", mock.Anything)
+}
+
+// Test 50: Room history is only readable by members of a private room, and
+// quizzes keep their answer for a viewer who already answered.
+func TestGetRoomMessagesReadAccess(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, new(mocks.UserRepositoryMock), new(mocks.RoomAuditRepositoryMock), new(mocks.RoomRestrictionRepositoryMock), new(mocks.ContactRepositoryMock), new(mocks.NotificationRepositoryMock), new(mocks.ChatStateRepositoryMock))
+
+	correct := 1
+	roomRepoMock.On("FindByID", "room1").Return(&domain.Room{ID: "room1", Type: domain.RoomTypeGroup}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "stranger").Return(nil, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user1").Return(&domain.RoomMembership{RoomID: "room1", UserID: "user1", Role: domain.RoleMember}, nil)
+	roomMessageRepoMock.On("FindByRoom", "room1", "user1").Return([]*domain.RoomMessage{{
+		ID:   "msg1",
+		Kind: domain.MessageKindPoll,
+		Poll: &domain.Poll{MessageID: "msg1", Quiz: true, CorrectOption: &correct, MyVotes: []int{0}},
+	}}, nil)
+
+	messages, err := roomService.GetMessages("room1", "stranger")
+	assert.Nil(t, messages)
+	assert.ErrorIs(t, err, ErrChatNotFound)
+	roomMessageRepoMock.AssertNotCalled(t, "FindByRoom", "room1", "stranger")
+
+	messages, err = roomService.GetMessages("room1", "user1")
+	assert.Nil(t, err)
+	assert.Equal(t, &correct, messages[0].Poll.CorrectOption)
+}
+
+// Test 51: Names in code spans, or crossing a formatting entity, are not mentions;
+// a name inside a formatting entity still is.
+func TestSendRoomMessageMentionsRespectFormatting(t *testing.T) {
+	roomRepoMock := new(mocks.RoomRepositoryMock)
+	membershipRepoMock := new(mocks.RoomMembershipRepositoryMock)
+	roomMessageRepoMock := new(mocks.RoomMessageRepositoryMock)
+	userRepoMock := new(mocks.UserRepositoryMock)
+	restrictionRepoMock := new(mocks.RoomRestrictionRepositoryMock)
+	notificationRepoMock := new(mocks.NotificationRepositoryMock)
+	chatStateRepoMock := new(mocks.ChatStateRepositoryMock)
+	roomService := NewRoomService(roomRepoMock, membershipRepoMock, roomMessageRepoMock, userRepoMock, new(mocks.RoomAuditRepositoryMock), restrictionRepoMock, new(mocks.ContactRepositoryMock), notificationRepoMock, chatStateRepoMock)
+
+	membershipRepoMock.On("GetMembership", "room1", "user1").Return(&domain.RoomMembership{RoomID: "room1", UserID: "user1", Role: domain.RoleMember}, nil)
+	restrictionRepoMock.On("Find", "room1", "user1", domain.RestrictionMute).Return(nil, nil)
+	roomRepoMock.On("FindByID", "room1").Return(&domain.Room{ID: "room1", Type: domain.RoomTypeGroup}, nil)
+	userRepoMock.On("FindByUsername", "@dave").Return(&domain.User{ID: "user4"}, nil)
+	membershipRepoMock.On("GetMembership", "room1", "user4").Return(&domain.RoomMembership{RoomID: "room1", UserID: "user4", Role: domain.RoleMember}, nil)
+	roomMessageRepoMock.On("Create", mock.AnythingOfType("*domain.RoomMessage")).Return(nil)
+	chatStateRepoMock.On("FindMuted", domain.ChatRef{Kind: domain.ChatRoom, ID: "room1"}, []string{"user4"}, mock.AnythingOfType("time.Time")).Return(nil, nil)
+	notificationRepoMock.On("CreateBatch", mock.AnythingOfType("[]*domain.Notification")).Return(nil)
+
+	msg, err := roomService.SendMessage("room1", "user1", "Ask @bob, @carol and @dave", []domain.MessageEntity{
+		{Type: domain.EntityCode, Offset: 4, Length: 4},    // @bob
+		{Type: domain.EntityBold, Offset: 12, Length: 5},   // "arol "
+		{Type: domain.EntityItalic, Offset: 17, Length: 9}, // "and @dave"
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"user4"}, msg.Mentioned)
+	assert.Len(t, msg.Entities, 4)
+	assert.Equal(t, domain.EntityMention, msg.Entities[3].Type)
+	assert.Equal(t, 21, msg.Entities[3].Offset)
+	userRepoMock.AssertNotCalled(t, "FindByUsername", "@bob")
+	userRepoMock.AssertNotCalled(t, "FindByUsername", "@carol")
+}
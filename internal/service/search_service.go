@@ -0,0 +1,39 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"social_media/internal/domain"
+)
+
+// SearchService defines full-text search over the caller's message history.
+type SearchService interface {
+	SearchMessages(userID string, filter domain.MessageSearchFilter) ([]*domain.MessageSearchHit, error)
+}
+
+type searchService struct {
+	searchRepo domain.MessageSearchRepository
+}
+
+// NewSearchService creates a new instance of SearchService.
+func NewSearchService(searchRepo domain.MessageSearchRepository) SearchService {
+	return &searchService{searchRepo: searchRepo}
+}
+
+// SearchMessages validates the filter and returns a page of hits ordered by rank.
+// Access control (participation and non-banned membership) is applied by the repository.
+func (s *searchService) SearchMessages(userID string, filter domain.MessageSearchFilter) ([]*domain.MessageSearchHit, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.Query == "" {
+		return nil, errors.New("search query is required")
+	}
+	if filter.ConversationID != "" && filter.RoomID != "" {
+		return nil, errors.New("cannot filter by both conversation and room")
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, errors.New("invalid date range")
+	}
+	filter.Limit, filter.Offset = normalizePage(filter.Limit, filter.Offset)
+	return s.searchRepo.Search(userID, filter)
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"social_media/internal/domain"
+	"social_media/internal/mocks"
+)
+
+// Test 1: Search without a query.
+func TestSearchMessagesEmptyQuery(t *testing.T) {
+	searchRepoMock := new(mocks.MessageSearchRepositoryMock)
+	searchService := NewSearchService(searchRepoMock)
+
+	hits, err := searchService.SearchMessages("user1", domain.MessageSearchFilter{Query: "   "})
+	assert.Nil(t, hits)
+	assert.EqualError(t, err, "search query is required")
+	searchRepoMock.AssertNotCalled(t, "Search")
+}
+
+// Test 2: Search with both a conversation and a room filter.
+func TestSearchMessagesConflictingScope(t *testing.T) {
+	searchRepoMock := new(mocks.MessageSearchRepositoryMock)
+	searchService := NewSearchService(searchRepoMock)
+
+	filter := domain.MessageSearchFilter{Query: "hello", ConversationID: "convo1", RoomID: "room1"}
+	hits, err := searchService.SearchMessages("user1", filter)
+	assert.Nil(t, hits)
+	assert.EqualError(t, err, "cannot filter by both conversation and room")
+}
+
+// Test 3: Search with an inverted date range.
+func TestSearchMessagesInvalidDateRange(t *testing.T) {
+	searchRepoMock := new(mocks.MessageSearchRepositoryMock)
+	searchService := NewSearchService(searchRepoMock)
+
+	from := time.Now()
+	to := from.Add(-time.Hour)
+	hits, err := searchService.SearchMessages("user1", domain.MessageSearchFilter{Query: "hello", From: &from, To: &to})
+	assert.Nil(t, hits)
+	assert.EqualError(t, err, "invalid date range")
+}
+
+// Test 4: Successful search normalizes the filter.
+func TestSearchMessagesSuccess(t *testing.T) {
+	searchRepoMock := new(mocks.MessageSearchRepositoryMock)
+	searchService := NewSearchService(searchRepoMock)
+
+	roomID := "room1"
+	expected := []*domain.MessageSearchHit{{MessageID: "msg1", RoomID: &roomID, Snippet: "<mark>hello</mark> world"}}
+	searchRepoMock.On("Search", "user1", domain.MessageSearchFilter{
+		Query: "hello", RoomID: "room1", Limit: defaultSearchLimit,
+	}).Return(expected, nil)
+
+	hits, err := searchService.SearchMessages("user1", domain.MessageSearchFilter{Query: " hello ", RoomID: "room1"})
+	assert.Nil(t, err)
+	assert.Equal(t, expected, hits)
+	searchRepoMock.AssertExpectations(t)
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"social_media/internal/domain"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+)
+
+// UserService defines the user directory operations.
+type UserService interface {
+	// SearchUsers finds discoverable users by username or display name.
+	// A leading '@' in the query is ignored.
+	SearchUsers(requesterID, query string, limit, offset int) ([]*domain.User, error)
+	// GetPublicProfile returns the profile of userID as seen by requesterID.
+	GetPublicProfile(requesterID, userID string) (*domain.PublicProfile, error)
+}
+
+type userService struct {
+	userRepo    domain.UserRepository
+	contactRepo domain.ContactRepository
+	blockRepo   domain.BlockRepository
+}
+
+// NewUserService creates a new instance of UserService.
+func NewUserService(userRepo domain.UserRepository, contactRepo domain.ContactRepository, blockRepo domain.BlockRepository) UserService {
+	return &userService{userRepo: userRepo, contactRepo: contactRepo, blockRepo: blockRepo}
+}
+
+// SearchUsers returns a page of matching users, excluding the requester.
+func (s *userService) SearchUsers(requesterID, query string, limit, offset int) ([]*domain.User, error) {
+	query = strings.TrimPrefix(strings.TrimSpace(query), "@")
+	if query == "" {
+		return nil, errors.New("search query is required")
+	}
+	limit, offset = normalizePage(limit, offset)
+	return s.userRepo.Search(query, requesterID, limit, offset)
+}
+
+// GetPublicProfile hides the phone number unless the user's privacy settings admit the requester.
+// Users who blocked each other, in either direction, are not found, as in search.
+func (s *userService) GetPublicProfile(requesterID, userID string) (*domain.PublicProfile, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		return nil, errors.New("user not found")
+	}
+	if requesterID != userID {
+		blocked, err := blockedEitherWay(s.blockRepo, userID, requesterID)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			return nil, errors.New("user not found")
+		}
+	}
+	profile := &domain.PublicProfile{
+		ID:       user.ID,
+		Name:     user.Name,
+		Username: user.Username,
+	}
+	showPhone, err := audienceAllows(s.contactRepo, user.Privacy.PhoneVisibility, user.ID, requesterID)
+	if err != nil {
+		return nil, err
+	}
+	if showPhone {
+		profile.Phone = &user.Phone
+	}
+	return profile, nil
+}
+
+// audienceAllows reports whether viewerID falls within the audience ownerID chose.
+// Users always pass their own checks; contacts are the users in ownerID's contact list.
+func audienceAllows(contactRepo domain.ContactRepository, audience domain.PrivacyAudience, ownerID, viewerID string) (bool, error) {
+	switch {
+	case ownerID == viewerID, audience == domain.AudienceEveryone:
+		return true, nil
+	case audience == domain.AudienceContacts:
+		return contactRepo.IsContact(ownerID, viewerID)
+	default:
+		return false, nil
+	}
+}
+
+// normalizePage clamps pagination parameters to sane bounds.
+func normalizePage(limit, offset int) (int, int) {
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"social_media/internal/domain"
+	"social_media/internal/mocks"
+)
+
+// Test 1: Search with an empty query.
+func TestSearchUsersEmptyQuery(t *testing.T) {
+	userRepoMock := new(mocks.UserRepositoryMock)
+	userService := NewUserService(userRepoMock, new(mocks.ContactRepositoryMock), new(mocks.BlockRepositoryMock))
+
+	users, err := userService.SearchUsers("user1", "  @ ", 0, 0)
+	assert.Nil(t, users)
+	assert.EqualError(t, err, "search query is required")
+	userRepoMock.AssertNotCalled(t, "Search")
+}
+
+// Test 2: Search strips the '@' prefix and applies default pagination.
+func TestSearchUsersSuccess(t *testing.T) {
+	userRepoMock := new(mocks.UserRepositoryMock)
+	userService := NewUserService(userRepoMock, new(mocks.ContactRepositoryMock), new(mocks.BlockRepositoryMock))
+
+	found := []*domain.User{{ID: "user2", Name: "John", Username: ptr("@john")}}
+	userRepoMock.On("Search", "jo", "user1", defaultSearchLimit, 0).Return(found, nil)
+
+	users, err := userService.SearchUsers("user1", "@jo", 0, -5)
+	assert.Nil(t, err)
+	assert.Equal(t, found, users)
+	userRepoMock.AssertExpectations(t)
+}
+
+// Test 3: Search clamps an oversized page.
+func TestSearchUsersClampsLimit(t *testing.T) {
+	userRepoMock := new(mocks.UserRepositoryMock)
+	userService := NewUserService(userRepoMock, new(mocks.ContactRepositoryMock), new(mocks.BlockRepositoryMock))
+
+	userRepoMock.On("Search", "john", "user1", maxSearchLimit, 40).Return([]*domain.User{}, nil)
+
+	_, err := userService.SearchUsers("user1", "john", 1000, 40)
+	assert.Nil(t, err)
+	userRepoMock.AssertExpectations(t)
+}
+
+// Test 4: Public profiles hide the phone number unless it is visible to everyone.
+func TestGetPublicProfileHidesPhone(t *testing.T) {
+	userRepoMock := new(mocks.UserRepositoryMock)
+	blockRepoMock := new(mocks.BlockRepositoryMock)
+	userService := NewUserService(userRepoMock, new(mocks.ContactRepositoryMock), blockRepoMock)
+
+	user := &domain.User{ID: "user2", Name: "John", Phone: "5551234567", Privacy: domain.DefaultPrivacySettings()}
+	userRepoMock.On("FindByID", "user2").Return(user, nil)
+	blockRepoMock.On("IsBlocked", mock.Anything, mock.Anything).Return(false, nil)
+
+	profile, err := userService.GetPublicProfile("user1", "user2")
+	assert.Nil(t, err)
+	assert.Nil(t, profile.Phone)
+
+	user.Privacy.PhoneVisibility = domain.AudienceEveryone
+	profile, err = userService.GetPublicProfile("user1", "user2")
+	assert.Nil(t, err)
+	assert.Equal(t, "5551234567", *profile.Phone)
+}
+
+// Test 5: Profiles are hidden between users who blocked each other, in either direction.
+func TestGetPublicProfileBlocked(t *testing.T) {
+	userRepoMock := new(mocks.UserRepositoryMock)
+	blockRepoMock := new(mocks.BlockRepositoryMock)
+	userService := NewUserService(userRepoMock, new(mocks.ContactRepositoryMock), blockRepoMock)
+
+	userRepoMock.On("FindByID", "user2").Return(&domain.User{ID: "user2", Name: "John"}, nil)
+	userRepoMock.On("FindByID", "user3").Return(&domain.User{ID: "user3", Name: "Jane"}, nil)
+	blockRepoMock.On("IsBlocked", "user2", "user1").Return(true, nil)
+	blockRepoMock.On("IsBlocked", "user3", "user1").Return(false, nil)
+	blockRepoMock.On("IsBlocked", "user1", "user3").Return(true, nil)
+
+	profile, err := userService.GetPublicProfile("user1", "user2")
+	assert.Nil(t, profile)
+	assert.EqualError(t, err, "user not found")
+
+	profile, err = userService.GetPublicProfile("user1", "user3")
+	assert.Nil(t, profile)
+	assert.EqualError(t, err, "user not found")
+}
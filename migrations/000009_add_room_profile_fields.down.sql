@@ -0,0 +1,4 @@
+ALTER TABLE rooms
+DROP COLUMN IF EXISTS description,
+DROP COLUMN IF EXISTS photo_url,
+DROP COLUMN IF EXISTS rules;
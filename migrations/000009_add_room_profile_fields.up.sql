@@ -0,0 +1,4 @@
+ALTER TABLE rooms
+ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS photo_url TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS rules TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_room_messages_content_tsv;
+ALTER TABLE room_messages
+DROP COLUMN IF EXISTS content_tsv;
+
+DROP INDEX IF EXISTS idx_messages_content_tsv;
+ALTER TABLE messages
+DROP COLUMN IF EXISTS content_tsv;
@@ -0,0 +1,13 @@
+-- Full-text search over direct and room messages. The 'simple' configuration
+-- avoids language-specific stemming since chats mix languages.
+ALTER TABLE messages
+ADD COLUMN IF NOT EXISTS content_tsv tsvector
+GENERATED ALWAYS AS (to_tsvector('simple', content)) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_messages_content_tsv ON messages USING GIN (content_tsv);
+
+ALTER TABLE room_messages
+ADD COLUMN IF NOT EXISTS content_tsv tsvector
+GENERATED ALWAYS AS (to_tsvector('simple', content)) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_room_messages_content_tsv ON room_messages USING GIN (content_tsv);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_rooms_name_trgm;
+DROP INDEX IF EXISTS idx_rooms_username_trgm;
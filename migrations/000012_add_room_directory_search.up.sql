@@ -0,0 +1,5 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Public rooms (those with a username) are searchable by name and username.
+CREATE INDEX IF NOT EXISTS idx_rooms_username_trgm ON rooms USING GIN (username gin_trgm_ops) WHERE username IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_rooms_name_trgm ON rooms USING GIN (name gin_trgm_ops) WHERE username IS NOT NULL;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS room_invites (
+    token VARCHAR(64) PRIMARY KEY,
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    creator_id UUID NOT NULL,
+    expires_at TIMESTAMPTZ,          -- NULL means the link never expires
+    max_uses INTEGER,                -- NULL means unlimited uses
+    use_count INTEGER NOT NULL DEFAULT 0,
+    requires_approval BOOLEAN NOT NULL DEFAULT FALSE,
+    revoked BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_room_invites_room_id ON room_invites (room_id);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS room_join_requests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    invite_token VARCHAR(64),        -- set when the request came through an invite link
+    message TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A user can have at most one pending request per room.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_room_join_requests_pending
+ON room_join_requests (room_id, user_id) WHERE status = 'pending';
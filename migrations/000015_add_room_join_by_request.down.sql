@@ -0,0 +1,6 @@
+ALTER TABLE room_join_requests
+DROP COLUMN IF EXISTS decided_by,
+DROP COLUMN IF EXISTS decided_at;
+
+ALTER TABLE rooms
+DROP COLUMN IF EXISTS join_by_request;
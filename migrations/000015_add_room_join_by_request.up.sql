@@ -0,0 +1,7 @@
+-- Rooms in request-to-join mode only admit new members after approval.
+ALTER TABLE rooms
+ADD COLUMN IF NOT EXISTS join_by_request BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE room_join_requests
+ADD COLUMN IF NOT EXISTS decided_by UUID,
+ADD COLUMN IF NOT EXISTS decided_at TIMESTAMPTZ;
@@ -0,0 +1,5 @@
+ALTER TABLE room_memberships ADD COLUMN IF NOT EXISTS permissions INTEGER NOT NULL DEFAULT 0;
+
+-- Existing admins keep everything they could do before, which excludes managing other admins.
+-- Only admins without permissions are backfilled, so a re-run keeps later changes.
+UPDATE room_memberships SET permissions = 95 WHERE role = 'admin' AND permissions = 0;
@@ -0,0 +1,2 @@
+ALTER TABLE room_memberships
+DROP COLUMN IF EXISTS promoted_by;
@@ -0,0 +1,3 @@
+-- Admins allowed to manage admins may only demote the admins they promoted.
+ALTER TABLE room_memberships
+ADD COLUMN IF NOT EXISTS promoted_by UUID;
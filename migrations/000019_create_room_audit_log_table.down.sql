@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS room_audit_log_no_update_delete ON room_audit_log;
+DROP FUNCTION IF EXISTS room_audit_log_immutable();
+DROP TABLE IF EXISTS room_audit_log;
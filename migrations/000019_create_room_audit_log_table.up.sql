@@ -0,0 +1,24 @@
+-- room_id has no foreign key so that the log outlives the room it describes.
+CREATE TABLE IF NOT EXISTS room_audit_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    room_id UUID NOT NULL,
+    actor_id UUID NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    target_id TEXT,                  -- user id, message id or invite token
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_room_audit_log_room_id ON room_audit_log (room_id, created_at DESC);
+
+-- The log is append-only.
+CREATE OR REPLACE FUNCTION room_audit_log_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'room_audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER room_audit_log_no_update_delete
+BEFORE UPDATE OR DELETE ON room_audit_log
+FOR EACH ROW EXECUTE FUNCTION room_audit_log_immutable();
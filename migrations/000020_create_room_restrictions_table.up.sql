@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS room_restrictions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,       -- ban or mute
+    reason TEXT NOT NULL DEFAULT '',
+    prior_role VARCHAR(20) NOT NULL, -- role restored when a ban is lifted
+    prior_permissions INTEGER NOT NULL DEFAULT 0,
+    actor_id UUID NOT NULL,
+    expires_at TIMESTAMPTZ,          -- NULL means until lifted manually
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (room_id, user_id, kind)
+);
+
+-- Used by the sweeper that lifts expired restrictions.
+CREATE INDEX IF NOT EXISTS idx_room_restrictions_expires_at ON room_restrictions (expires_at) WHERE expires_at IS NOT NULL;
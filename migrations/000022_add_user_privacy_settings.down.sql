@@ -0,0 +1,5 @@
+ALTER TABLE users
+DROP COLUMN IF EXISTS group_invite_privacy,
+DROP COLUMN IF EXISTS message_privacy,
+DROP COLUMN IF EXISTS find_by_phone,
+DROP COLUMN IF EXISTS phone_visibility;
@@ -0,0 +1,10 @@
+-- Per-user privacy settings; each holds an audience: everyone, contacts or nobody.
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS phone_visibility VARCHAR(10) NOT NULL DEFAULT 'nobody'
+    CHECK (phone_visibility IN ('everyone', 'contacts', 'nobody')),
+ADD COLUMN IF NOT EXISTS find_by_phone VARCHAR(10) NOT NULL DEFAULT 'everyone'
+    CHECK (find_by_phone IN ('everyone', 'contacts', 'nobody')),
+ADD COLUMN IF NOT EXISTS message_privacy VARCHAR(10) NOT NULL DEFAULT 'everyone'
+    CHECK (message_privacy IN ('everyone', 'contacts', 'nobody')),
+ADD COLUMN IF NOT EXISTS group_invite_privacy VARCHAR(10) NOT NULL DEFAULT 'everyone'
+    CHECK (group_invite_privacy IN ('everyone', 'contacts', 'nobody'));
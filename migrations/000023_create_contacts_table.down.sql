@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_phone_hash;
+ALTER TABLE users
+DROP COLUMN IF EXISTS phone_hash;
+DROP TABLE IF EXISTS contacts;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS contacts (
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    display_name VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (owner_id, user_id)
+);
+
+-- Salted phone hashes let clients match their phone book without uploading raw numbers.
+-- The salt must match domain.HashPhone.
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS phone_hash CHAR(64)
+    GENERATED ALWAYS AS (encode(sha256(convert_to('social_media:' || phone, 'UTF8')), 'hex')) STORED;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_phone_hash ON users (phone_hash);
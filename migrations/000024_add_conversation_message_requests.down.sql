@@ -0,0 +1,3 @@
+ALTER TABLE conversations
+DROP COLUMN IF EXISTS initiator_id,
+DROP COLUMN IF EXISTS status;
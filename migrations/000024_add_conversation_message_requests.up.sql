@@ -0,0 +1,5 @@
+-- First messages from non-contacts open a conversation as a message request.
+ALTER TABLE conversations
+ADD COLUMN IF NOT EXISTS status VARCHAR(10) NOT NULL DEFAULT 'active'
+    CHECK (status IN ('active', 'request')),
+ADD COLUMN IF NOT EXISTS initiator_id UUID REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS conversation_participants;
+DELETE FROM conversations WHERE kind = 'group';
+ALTER TABLE conversations
+ALTER COLUMN participant1 SET NOT NULL,
+ALTER COLUMN participant2 SET NOT NULL,
+DROP COLUMN IF EXISTS title,
+DROP COLUMN IF EXISTS kind;
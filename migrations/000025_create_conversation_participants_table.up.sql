@@ -0,0 +1,24 @@
+-- Conversations can have more than two participants. Direct conversations keep
+-- participant1/participant2 for the per-pair lookup; group conversations leave them NULL.
+ALTER TABLE conversations
+ADD COLUMN IF NOT EXISTS kind VARCHAR(10) NOT NULL DEFAULT 'direct' CHECK (kind IN ('direct', 'group')),
+ADD COLUMN IF NOT EXISTS title VARCHAR(255),
+ALTER COLUMN participant1 DROP NOT NULL,
+ALTER COLUMN participant2 DROP NOT NULL;
+
+CREATE TABLE IF NOT EXISTS conversation_participants (
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    joined_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (conversation_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversation_participants_user_id ON conversation_participants (user_id);
+
+-- Backfill existing direct conversations.
+INSERT INTO conversation_participants (conversation_id, user_id, joined_at)
+SELECT c.id, p.user_id, COALESCE(c.created_at, CURRENT_TIMESTAMP)
+FROM conversations c
+CROSS JOIN LATERAL (VALUES (c.participant1), (c.participant2)) AS p(user_id)
+JOIN users u ON u.id = p.user_id
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,22 @@
+-- Per-user organization of conversations and rooms. chat_id references either
+-- table depending on chat_kind, so it has no foreign key.
+CREATE TABLE IF NOT EXISTS chat_states (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chat_kind VARCHAR(20) NOT NULL CHECK (chat_kind IN ('conversation', 'room')),
+    chat_id UUID NOT NULL,
+    archived BOOLEAN NOT NULL DEFAULT FALSE,
+    pin_order INT,
+    muted_until TIMESTAMPTZ,
+    last_read_at TIMESTAMPTZ,
+    PRIMARY KEY (user_id, chat_kind, chat_id)
+);
+
+CREATE TABLE IF NOT EXISTS chat_folders (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,
+    rules JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_chat_folders_user_id ON chat_folders (user_id, created_at);
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS room_pinned_messages;
+DROP TABLE IF EXISTS conversation_pinned_messages;
+
+ALTER TABLE room_messages
+DROP COLUMN IF EXISTS reference_id,
+DROP COLUMN IF EXISTS kind;
+
+ALTER TABLE messages
+DROP COLUMN IF EXISTS reference_id,
+DROP COLUMN IF EXISTS kind;
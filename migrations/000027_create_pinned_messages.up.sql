@@ -0,0 +1,28 @@
+-- Service messages record chat events, such as a pin, in the history.
+-- reference_id points at the message the event is about.
+ALTER TABLE messages
+ADD COLUMN IF NOT EXISTS kind VARCHAR(20) NOT NULL DEFAULT 'text',
+ADD COLUMN IF NOT EXISTS reference_id UUID REFERENCES messages(id) ON DELETE SET NULL;
+
+ALTER TABLE room_messages
+ADD COLUMN IF NOT EXISTS kind VARCHAR(20) NOT NULL DEFAULT 'text',
+ADD COLUMN IF NOT EXISTS reference_id UUID REFERENCES room_messages(id) ON DELETE SET NULL;
+
+-- Pins live in one table per chat kind so they disappear with their message.
+CREATE TABLE IF NOT EXISTS conversation_pinned_messages (
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    pinned_by UUID NOT NULL,
+    position INT NOT NULL,
+    pinned_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (conversation_id, message_id)
+);
+
+CREATE TABLE IF NOT EXISTS room_pinned_messages (
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    message_id UUID NOT NULL REFERENCES room_messages(id) ON DELETE CASCADE,
+    pinned_by UUID NOT NULL,
+    position INT NOT NULL,
+    pinned_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (room_id, message_id)
+);
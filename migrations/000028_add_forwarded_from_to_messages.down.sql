@@ -0,0 +1,5 @@
+ALTER TABLE room_messages
+DROP COLUMN IF EXISTS forwarded_from;
+
+ALTER TABLE messages
+DROP COLUMN IF EXISTS forwarded_from;
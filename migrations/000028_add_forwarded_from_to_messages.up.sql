@@ -0,0 +1,7 @@
+-- Attribution of forwarded messages: the original sender and date, plus the
+-- source channel and post for forwards from channels. NULL for original messages.
+ALTER TABLE messages
+ADD COLUMN IF NOT EXISTS forwarded_from JSONB;
+
+ALTER TABLE room_messages
+ADD COLUMN IF NOT EXISTS forwarded_from JSONB;
@@ -0,0 +1,20 @@
+-- Saved messages. message_id and source_chat_id deliberately have no foreign
+-- keys: the snapshot columns keep a bookmark intact after its message is gone.
+CREATE TABLE IF NOT EXISTS bookmarks (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source_kind VARCHAR(20) NOT NULL CHECK (source_kind IN ('conversation', 'room')),
+    source_chat_id UUID NOT NULL,
+    message_id UUID NOT NULL,
+    sender_id UUID NOT NULL,
+    content TEXT NOT NULL,
+    forwarded_from JSONB,
+    sent_at TIMESTAMPTZ NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, source_kind, message_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bookmarks_user_id ON bookmarks (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_bookmarks_tags ON bookmarks USING GIN (tags);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS room_mentions;
+
+ALTER TABLE room_messages
+DROP COLUMN IF EXISTS entities;
@@ -0,0 +1,14 @@
+-- Message entities (mentions for now) as a JSON array with UTF-16 offsets.
+ALTER TABLE room_messages
+ADD COLUMN IF NOT EXISTS entities JSONB NOT NULL DEFAULT '[]';
+
+-- Users notified by a room message, for per-room unread mention counters.
+CREATE TABLE IF NOT EXISTS room_mentions (
+    message_id UUID NOT NULL REFERENCES room_messages(id) ON DELETE CASCADE,
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (message_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_room_mentions_user_id ON room_mentions (user_id, room_id, created_at);
@@ -0,0 +1,3 @@
+-- Formatting entities of conversation messages, as a JSON array with UTF-16 offsets.
+ALTER TABLE messages
+ADD COLUMN IF NOT EXISTS entities JSONB NOT NULL DEFAULT '[]';
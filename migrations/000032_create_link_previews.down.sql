@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_room_messages_pending_preview;
+DROP INDEX IF EXISTS idx_messages_pending_preview;
+
+ALTER TABLE room_messages
+DROP COLUMN IF EXISTS link_preview,
+DROP COLUMN IF EXISTS preview_url;
+
+ALTER TABLE messages
+DROP COLUMN IF EXISTS link_preview,
+DROP COLUMN IF EXISTS preview_url;
+
+DROP TABLE IF EXISTS link_previews;
@@ -0,0 +1,23 @@
+-- Previews fetched for links in messages, cached by URL. Failed fetches are
+-- cached as empty rows so they are not retried until they expire.
+CREATE TABLE IF NOT EXISTS link_previews (
+    url TEXT PRIMARY KEY,
+    title TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL DEFAULT '',
+    site_name TEXT NOT NULL DEFAULT '',
+    fetched_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- preview_url is the link still awaiting a preview; it is cleared once the
+-- preview job has run and link_preview holds the result (NULL when there is none).
+ALTER TABLE messages
+ADD COLUMN IF NOT EXISTS preview_url TEXT,
+ADD COLUMN IF NOT EXISTS link_preview JSONB;
+
+ALTER TABLE room_messages
+ADD COLUMN IF NOT EXISTS preview_url TEXT,
+ADD COLUMN IF NOT EXISTS link_preview JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_messages_pending_preview ON messages (created_at) WHERE preview_url IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_room_messages_pending_preview ON room_messages (created_at) WHERE preview_url IS NOT NULL;
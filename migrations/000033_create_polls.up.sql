@@ -0,0 +1,35 @@
+-- Polls posted as room messages of kind 'poll'. Tallies are kept on the options
+-- and updated in the same transaction as the votes.
+CREATE TABLE IF NOT EXISTS polls (
+    message_id UUID PRIMARY KEY REFERENCES room_messages(id) ON DELETE CASCADE,
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    question TEXT NOT NULL,
+    multiple_choice BOOLEAN NOT NULL DEFAULT FALSE,
+    anonymous BOOLEAN NOT NULL DEFAULT TRUE,
+    quiz BOOLEAN NOT NULL DEFAULT FALSE,
+    correct_option INT,
+    total_voters INT NOT NULL DEFAULT 0,
+    close_at TIMESTAMPTZ,
+    closed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (NOT quiz OR (correct_option IS NOT NULL AND NOT multiple_choice))
+);
+
+CREATE TABLE IF NOT EXISTS poll_options (
+    message_id UUID NOT NULL REFERENCES polls(message_id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    text TEXT NOT NULL,
+    votes INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (message_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS poll_votes (
+    message_id UUID NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    voted_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (message_id, user_id, position),
+    FOREIGN KEY (message_id, position) REFERENCES poll_options(message_id, position) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_polls_close_at ON polls (close_at) WHERE closed_at IS NULL AND close_at IS NOT NULL;
@@ -0,0 +1,233 @@
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"social_media/internal/domain"
+)
+
+// Limits applied to every fetch.
+const (
+	fetchTimeout        = 5 * time.Second
+	maxBodyBytes        = 512 << 10 // meta tags live in the head, so the rest of the page is not read
+	maxRedirects        = 5
+	maxTitleRunes       = 256
+	maxDescriptionRunes = 1024
+)
+
+// ErrForbiddenAddress is returned for links that resolve to loopback, private or
+// otherwise internal addresses.
+var ErrForbiddenAddress = errors.New("link points to a non-public address")
+
+// Fetcher builds the preview of a web page.
+type Fetcher interface {
+	Fetch(ctx context.Context, rawURL string) (*domain.LinkPreview, error)
+}
+
+type httpFetcher struct {
+	client *http.Client
+}
+
+// NewFetcher returns a Fetcher that reads OpenGraph and HTML meta tags over HTTP.
+// It only connects to public addresses, checked after DNS resolution and on
+// every redirect, and it limits the time and bytes spent per page.
+func NewFetcher() Fetcher {
+	return newHTTPFetcher(publicOnly)
+}
+
+// newHTTPFetcher returns a fetcher whose dialer runs control before connecting.
+func newHTTPFetcher(control func(network, address string, c syscall.RawConn) error) *httpFetcher {
+	dialer := &net.Dialer{Timeout: fetchTimeout, Control: control}
+	transport := &http.Transport{
+		Proxy:                 nil, // a proxy would connect on our behalf and bypass the address check
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   fetchTimeout,
+		ResponseHeaderTimeout: fetchTimeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+	return &httpFetcher{client: &http.Client{
+		Transport: transport,
+		Timeout:   fetchTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return errors.New("too many redirects")
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+			}
+			return nil
+		},
+	}}
+}
+
+func (f *httpFetcher) Fetch(ctx context.Context, rawURL string) (*domain.LinkPreview, error) {
+	link, err := url.Parse(rawURL)
+	if err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" {
+		return nil, fmt.Errorf("invalid link %q", rawURL)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	req.Header.Set("User-Agent", "social_media-link-preview/1.0")
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", rawURL, resp.Status)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("fetching %s: not an html page", rawURL)
+	}
+	preview := parse(io.LimitReader(resp.Body, maxBodyBytes), resp.Request.URL)
+	preview.URL = rawURL
+	return preview, nil
+}
+
+// parse reads the meta tags of an HTML document. OpenGraph properties win over
+// Twitter cards, which win over the plain title and description.
+func parse(r io.Reader, base *url.URL) *domain.LinkPreview {
+	meta := make(map[string]string)
+	var title string
+	tokenizer := html.NewTokenizer(r)
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return buildPreview(meta, title, base)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			switch string(name) {
+			case "body":
+				return buildPreview(meta, title, base)
+			case "title":
+				if title == "" && tokenizer.Next() == html.TextToken {
+					title = string(tokenizer.Text())
+				}
+			case "meta":
+				var key, content string
+				for hasAttr {
+					var attr, value []byte
+					attr, value, hasAttr = tokenizer.TagAttr()
+					switch string(attr) {
+					case "property", "name":
+						key = strings.ToLower(string(value))
+					case "content":
+						content = string(value)
+					}
+				}
+				if _, seen := meta[key]; key != "" && !seen {
+					meta[key] = content
+				}
+			}
+		case html.EndTagToken:
+			if name, _ := tokenizer.TagName(); string(name) == "head" {
+				return buildPreview(meta, title, base)
+			}
+		}
+	}
+}
+
+func buildPreview(meta map[string]string, title string, base *url.URL) *domain.LinkPreview {
+	first := func(keys ...string) string {
+		for _, key := range keys {
+			if value := clean(meta[key]); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+	preview := &domain.LinkPreview{
+		Title:       truncate(first("og:title", "twitter:title"), maxTitleRunes),
+		Description: truncate(first("og:description", "twitter:description", "description"), maxDescriptionRunes),
+		SiteName:    truncate(first("og:site_name"), maxTitleRunes),
+	}
+	if preview.Title == "" {
+		preview.Title = truncate(clean(title), maxTitleRunes)
+	}
+	if image := first("og:image", "og:image:url", "twitter:image"); image != "" {
+		// Images are often given relative to the page.
+		if ref, err := base.Parse(image); err == nil && (ref.Scheme == "http" || ref.Scheme == "https") {
+			preview.ImageURL = ref.String()
+		}
+	}
+	return preview
+}
+
+// clean collapses whitespace and drops invalid UTF-8.
+func clean(s string) string {
+	return strings.Join(strings.Fields(strings.ToValidUTF8(s, "")), " ")
+}
+
+func truncate(s string, maxRunes int) string {
+	if utf8.RuneCountInString(s) <= maxRunes {
+		return s
+	}
+	return string([]rune(s)[:maxRunes-1]) + "…"
+}
+
+// publicOnly is a dialer control that refuses connections to non-public
+// addresses. It runs on the resolved address, so DNS names pointing inside the
+// network are caught too.
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublic(ip) {
+		return ErrForbiddenAddress
+	}
+	return nil
+}
+
+// reservedNetworks are special-purpose ranges the net.IP methods do not cover.
+var reservedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",     // "this" network
+	"100.64.0.0/10", // carrier-grade NAT
+	"192.0.0.0/24",  // IETF protocol assignments
+	"198.18.0.0/15", // benchmarking
+	"240.0.0.0/4",   // reserved, including broadcast
+	"64:ff9b::/96",  // NAT64, can reach IPv4 private ranges
+)
+
+func isPublic(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range reservedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+	return networks
+}
@@ -0,0 +1,82 @@
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const page = `<!DOCTYPE html>
+<html><head>
+<title>Fallback title</title>
+<meta property="og:title" content="  Release   notes ">
+<meta property="og:site_name" content="Example">
+<meta name="description" content="Plain description">
+<meta name="twitter:description" content="Card description">
+<meta property="og:image" content="/static/cover.png">
+</head><body><meta property="og:title" content="Ignored"></body></html>`
+
+// Test 1: OpenGraph tags are preferred, whitespace is collapsed and relative images are resolved.
+func TestFetchOpenGraph(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, page)
+	}))
+	defer server.Close()
+
+	preview, err := newHTTPFetcher(nil).Fetch(context.Background(), server.URL+"/blog")
+	assert.Nil(t, err)
+	assert.Equal(t, server.URL+"/blog", preview.URL)
+	assert.Equal(t, "Release notes", preview.Title)
+	assert.Equal(t, "Card description", preview.Description)
+	assert.Equal(t, "Example", preview.SiteName)
+	assert.Equal(t, server.URL+"/static/cover.png", preview.ImageURL)
+}
+
+// Test 2: The default fetcher refuses to connect to loopback and private addresses.
+func TestFetchBlocksPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request should not reach the server")
+	}))
+	defer server.Close()
+
+	preview, err := NewFetcher().Fetch(context.Background(), server.URL)
+	assert.ErrorIs(t, err, ErrForbiddenAddress)
+	assert.Nil(t, preview)
+
+	for _, ip := range []string{"10.0.0.1", "172.16.5.4", "192.168.1.1", "169.254.169.254", "100.64.0.1", "::1", "fd00::1"} {
+		assert.ErrorIs(t, publicOnly("tcp", net.JoinHostPort(ip, "80"), nil), ErrForbiddenAddress, ip)
+	}
+	assert.Nil(t, publicOnly("tcp", "93.184.216.34:443", nil))
+}
+
+// Test 3: Only the first part of a page is read, and non-HTML responses are rejected.
+func TestFetchLimits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/image.png" {
+			w.Header().Set("Content-Type", "image/png")
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<html><head><title>Big</title><!--"+strings.Repeat("x", maxBodyBytes)+"-->")
+		fmt.Fprint(w, `<meta name="description" content="Too far"></head></html>`)
+	}))
+	defer server.Close()
+
+	fetcher := newHTTPFetcher(nil)
+	preview, err := fetcher.Fetch(context.Background(), server.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, "Big", preview.Title)
+	assert.Empty(t, preview.Description)
+
+	_, err = fetcher.Fetch(context.Background(), server.URL+"/image.png")
+	assert.NotNil(t, err)
+	assert.False(t, errors.Is(err, ErrForbiddenAddress))
+}
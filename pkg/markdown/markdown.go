@@ -0,0 +1,236 @@
+package markdown
+
+import (
+	"strings"
+	"unicode"
+
+	"social_media/internal/domain"
+)
+
+// Parse converts markdown into plain text and the formatting entities the
+// message API expects, with offsets in UTF-16 code units. It understands
+//
+//	**bold**  *italic*  _italic_  ***bold italic***  `code`  ```pre```  [text](url)  ||spoiler||
+//
+// A backslash escapes a markup character. Markup that is not closed is kept as
+// literal text, and code and pre spans are not parsed further. A first line of a
+// pre block that is a single word is taken as the language hint and dropped.
+func Parse(text string) (string, []domain.MessageEntity) {
+	p := &parser{}
+	p.parse([]rune(text))
+	return string(p.out), p.entities
+}
+
+type parser struct {
+	out      []rune
+	offset   int // length of out in UTF-16 code units
+	entities []domain.MessageEntity
+}
+
+func (p *parser) parse(src []rune) {
+	for i := 0; i < len(src); {
+		if next, ok := p.markup(src, i); ok {
+			i = next
+			continue
+		}
+		p.write(src[i])
+		i++
+	}
+}
+
+// markup parses the markup starting at src[i], if any, and returns the index
+// after it.
+func (p *parser) markup(src []rune, i int) (int, bool) {
+	switch r := src[i]; {
+	case r == '\\' && i+1 < len(src) && strings.ContainsRune(markupChars, src[i+1]):
+		p.write(src[i+1])
+		return i + 2, true
+	case hasPrefix(src[i:], "```"):
+		start := i + 3
+		end := closing(src[start:], "```")
+		if end < 0 {
+			return i, false
+		}
+		p.verbatim(domain.EntityPre, fence(src[start:start+end]))
+		return start + end + 3, true
+	case r == '`':
+		end := closing(src[i+1:], "`")
+		if end <= 0 {
+			return i, false
+		}
+		p.verbatim(domain.EntityCode, src[i+1:i+1+end])
+		return i + end + 2, true
+	case hasPrefix(src[i:], "***"):
+		return p.boldItalic(src, i)
+	case hasPrefix(src[i:], "**"):
+		return p.span(domain.EntityBold, src, i, "**")
+	case hasPrefix(src[i:], "||"):
+		return p.span(domain.EntitySpoiler, src, i, "||")
+	case r == '*':
+		return p.span(domain.EntityItalic, src, i, "*")
+	case r == '_' && (i == 0 || !isWordRune(src[i-1])):
+		return p.span(domain.EntityItalic, src, i, "_")
+	case r == '[':
+		return p.link(src, i)
+	}
+	return i, false
+}
+
+// span parses text between delim pairs, which may contain further markup.
+func (p *parser) span(typ domain.EntityType, src []rune, i int, delim string) (int, bool) {
+	start := i + len(delim)
+	end := closing(src[start:], delim)
+	if end <= 0 {
+		return i, false
+	}
+	p.wrap(typ, "", src[start:start+end])
+	return start + end + len(delim), true
+}
+
+// boldItalic parses a span opened by "***", which starts both a bold and an
+// italic span. When they do not close together, the one closing last is the
+// outer span.
+func (p *parser) boldItalic(src []rune, i int) (int, bool) {
+	start := i + 3
+	bold, italic := closing(src[start:], "**"), closing(src[start:], "*")
+	if bold > 0 && italic > bold && bold == closing(src[start:], "***") {
+		p.enclose(domain.EntityBold, "", func() { p.wrap(domain.EntityItalic, "", src[start:start+bold]) })
+		return start + bold + 3, true
+	}
+	if bold >= 0 && (italic < 0 || bold < italic) {
+		return p.span(domain.EntityItalic, src, i, "*")
+	}
+	return p.span(domain.EntityBold, src, i, "**")
+}
+
+// link parses [text](url).
+func (p *parser) link(src []rune, i int) (int, bool) {
+	textEnd := closing(src[i+1:], "]")
+	if textEnd <= 0 {
+		return i, false
+	}
+	urlStart := i + 1 + textEnd + 1
+	if !hasPrefix(src[urlStart:], "(") {
+		return i, false
+	}
+	urlEnd := closing(src[urlStart+1:], ")")
+	if urlEnd <= 0 {
+		return i, false
+	}
+	url := strings.TrimSpace(string(src[urlStart+1 : urlStart+1+urlEnd]))
+	p.wrap(domain.EntityTextLink, url, src[i+1:i+1+textEnd])
+	return urlStart + 1 + urlEnd + 1, true
+}
+
+// wrap parses inner and marks the text it produced with an entity.
+func (p *parser) wrap(typ domain.EntityType, url string, inner []rune) {
+	p.enclose(typ, url, func() { p.parse(inner) })
+}
+
+// enclose marks the text written by write with an entity, dropping it if write
+// produced no text.
+func (p *parser) enclose(typ domain.EntityType, url string, write func()) {
+	index := len(p.entities)
+	p.entities = append(p.entities, domain.MessageEntity{Type: typ, Offset: p.offset, URL: url})
+	write()
+	if length := p.offset - p.entities[index].Offset; length > 0 {
+		p.entities[index].Length = length
+	} else {
+		p.entities = append(p.entities[:index], p.entities[index+1:]...)
+	}
+}
+
+// verbatim writes text as is and marks it with an entity.
+func (p *parser) verbatim(typ domain.EntityType, text []rune) {
+	if len(text) == 0 {
+		return
+	}
+	offset := p.offset
+	for _, r := range text {
+		p.write(r)
+	}
+	p.entities = append(p.entities, domain.MessageEntity{Type: typ, Offset: offset, Length: p.offset - offset})
+}
+
+func (p *parser) write(r rune) {
+	p.out = append(p.out, r)
+	if r >= 0x10000 {
+		p.offset += 2 // encoded as a surrogate pair
+	} else {
+		p.offset++
+	}
+}
+
+// markupChars are the characters a backslash escapes.
+const markupChars = "\\*_`[]()|"
+
+// closing returns the index in src of the delimiter closing a span, or -1. It
+// skips escaped characters, and a single '*' does not match half of a "**".
+// An '_' only closes a span when it does not continue a word.
+func closing(src []rune, delim string) int {
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\\' {
+			i++
+			continue
+		}
+		if !hasPrefix(src[i:], delim) {
+			continue
+		}
+		if delim == "*" && hasPrefix(src[i:], "**") {
+			i++
+			continue
+		}
+		if delim == "_" && i+1 < len(src) && isWordRune(src[i+1]) {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+// fence strips the language hint and the newlines around a pre block.
+func fence(body []rune) []rune {
+	if newline := indexRune(body, '\n'); newline > 0 && isWord(body[:newline]) {
+		body = body[newline:]
+	}
+	if len(body) > 0 && body[0] == '\n' {
+		body = body[1:]
+	}
+	if len(body) > 0 && body[len(body)-1] == '\n' {
+		body = body[:len(body)-1]
+	}
+	return body
+}
+
+func hasPrefix(src []rune, prefix string) bool {
+	i := 0
+	for _, r := range prefix {
+		if i >= len(src) || src[i] != r {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+func indexRune(src []rune, r rune) int {
+	for i, c := range src {
+		if c == r {
+			return i
+		}
+	}
+	return -1
+}
+
+func isWord(src []rune) bool {
+	for _, r := range src {
+		if !isWordRune(r) && r != '+' && r != '-' && r != '#' {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,48 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"social_media/internal/domain"
+)
+
+// Test 1: Nested spans, links and code produce entities with UTF-16 offsets.
+func TestParse(t *testing.T) {
+	text, entities := Parse("🚀 **Launch _today_** at [the site](https://example.com), run `make`")
+	assert.Equal(t, "🚀 Launch today at the site, run make", text)
+	assert.Equal(t, []domain.MessageEntity{
+		{Type: domain.EntityBold, Offset: 3, Length: 12},
+		{Type: domain.EntityItalic, Offset: 10, Length: 5},
+		{Type: domain.EntityTextLink, Offset: 19, Length: 8, URL: "https://example.com"},
+		{Type: domain.EntityCode, Offset: 33, Length: 4},
+	}, entities)
+}
+
+// Test 2: Pre blocks drop the language hint and are not parsed further.
+func TestParsePre(t *testing.T) {
+	text, entities := Parse("```go\nx := a**b**\n```")
+	assert.Equal(t, "x := a**b**", text)
+	assert.Equal(t, []domain.MessageEntity{{Type: domain.EntityPre, Offset: 0, Length: 11}}, entities)
+}
+
+// Test 3: Escaped, unclosed and intraword markup is kept as text.
+func TestParseLiteralMarkup(t *testing.T) {
+	text, entities := Parse(`2 \* 3 = 6, **open, snake_case_name and ||secret||`)
+	assert.Equal(t, "2 * 3 = 6, **open, snake_case_name and secret", text)
+	assert.Equal(t, []domain.MessageEntity{{Type: domain.EntitySpoiler, Offset: 39, Length: 6}}, entities)
+}
+
+// Test 4: "***" opens both bold and italic; when they close apart, the span closing last is outside.
+func TestParseBoldItalic(t *testing.T) {
+	text, entities := Parse("***a*** ***b** c* ***d* e**")
+	assert.Equal(t, "a b c d e", text)
+	assert.Equal(t, []domain.MessageEntity{
+		{Type: domain.EntityBold, Offset: 0, Length: 1},
+		{Type: domain.EntityItalic, Offset: 0, Length: 1},
+		{Type: domain.EntityItalic, Offset: 2, Length: 3},
+		{Type: domain.EntityBold, Offset: 2, Length: 1},
+		{Type: domain.EntityBold, Offset: 6, Length: 3},
+		{Type: domain.EntityItalic, Offset: 6, Length: 1},
+	}, entities)
+}
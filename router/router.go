@@ -8,7 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter(authHandler *handler.AuthHandler, profileHandler *handler.ProfileHandler, convoHandler *handler.ConversationHandler, roomHandler *handler.RoomHandler, jwtManager *jwt.JWTManager) *gin.Engine {
+func SetupRouter(authHandler *handler.AuthHandler, profileHandler *handler.ProfileHandler, userHandler *handler.UserHandler, convoHandler *handler.ConversationHandler, roomHandler *handler.RoomHandler, searchHandler *handler.SearchHandler, inviteHandler *handler.InviteHandler, joinRequestHandler *handler.JoinRequestHandler, notificationHandler *handler.NotificationHandler, auditHandler *handler.AuditHandler, blockHandler *handler.BlockHandler, contactHandler *handler.ContactHandler, chatHandler *handler.ChatHandler, pinHandler *handler.PinHandler, forwardHandler *handler.ForwardHandler, bookmarkHandler *handler.BookmarkHandler, pollHandler *handler.PollHandler, jwtManager *jwt.JWTManager) *gin.Engine {
 	r := gin.Default()
 
 	// Public routes.
@@ -26,27 +26,117 @@ func SetupRouter(authHandler *handler.AuthHandler, profileHandler *handler.Profi
 		protected.GET("/profile", profileHandler.GetProfile)
 		protected.PUT("/profile", profileHandler.UpdateProfile)
 		protected.DELETE("/profile", profileHandler.DeleteProfile)
+		protected.PUT("/profile/discoverability", profileHandler.SetDiscoverability)
+		protected.PUT("/profile/privacy", profileHandler.UpdatePrivacy)
+
+		// User directory endpoints.
+		protected.GET("/users/search", userHandler.SearchUsers)
+		protected.GET("/users/:userID", userHandler.GetUser)
+
+		// Block list endpoints.
+		protected.GET("/blocks", blockHandler.ListBlocked)
+		protected.POST("/blocks/:userID", blockHandler.BlockUser)
+		protected.DELETE("/blocks/:userID", blockHandler.UnblockUser)
+
+		// Contact endpoints.
+		protected.GET("/contacts", contactHandler.ListContacts)
+		protected.POST("/contacts/import", contactHandler.ImportContacts)
+		protected.PUT("/contacts/:userID", contactHandler.SaveContact)
+		protected.DELETE("/contacts/:userID", contactHandler.RemoveContact)
 
 		// Conversation endpoints.
 		protected.POST("/conversations/send", convoHandler.SendMessageEndpoint)
-		protected.GET("/conversations", convoHandler.ListConversations)
+		protected.GET("/conversations", chatHandler.ListConversations)
+		protected.POST("/conversations/groups", convoHandler.CreateGroupConversation)
 		protected.GET("/conversations/:id/messages", convoHandler.GetMessages)
+		protected.POST("/conversations/:id/messages", convoHandler.SendGroupMessage)
+		protected.POST("/conversations/:id/participants", convoHandler.AddParticipant)
+		protected.POST("/conversations/:id/leave", convoHandler.LeaveConversation)
+		protected.POST("/conversations/:id/forward", forwardHandler.ForwardToConversation)
+		protected.GET("/conversations/requests", convoHandler.ListMessageRequests)
+		protected.POST("/conversations/requests/:id/accept", convoHandler.AcceptMessageRequest)
+		protected.POST("/conversations/requests/:id/block", convoHandler.BlockMessageRequest)
+		protected.DELETE("/conversations/requests/:id", convoHandler.DeleteMessageRequest)
 		protected.PUT("/messages/:id", convoHandler.UpdateMessage)
 		protected.DELETE("/messages/:id", convoHandler.DeleteMessage)
 
+		// Chat list organization endpoints.
+		protected.PUT("/chats/pinned", chatHandler.ReorderPinnedChats)
+		protected.PUT("/chats/:kind/:chatID", chatHandler.UpdateChatState)
+		protected.POST("/chats/:kind/:chatID/read", chatHandler.MarkRead)
+		protected.GET("/chat-folders", chatHandler.ListFolders)
+		protected.POST("/chat-folders", chatHandler.CreateFolder)
+		protected.PUT("/chat-folders/:folderID", chatHandler.UpdateFolder)
+		protected.DELETE("/chat-folders/:folderID", chatHandler.DeleteFolder)
+
 		// Room endpoints.
+		protected.GET("/rooms", chatHandler.ListRooms)
 		protected.POST("/rooms", roomHandler.CreateRoom)
 		protected.PUT("/rooms", roomHandler.UpdateRoom)
 		protected.DELETE("/rooms", roomHandler.DeleteRoom)
 		protected.POST("/rooms/add-member", roomHandler.AddMember)
 		protected.POST("/rooms/remove-member", roomHandler.RemoveMember)
 		protected.POST("/rooms/promote-member", roomHandler.PromoteMember)
+		protected.POST("/rooms/demote-member", roomHandler.DemoteMember)
+		protected.PUT("/rooms/admin-permissions", roomHandler.SetAdminPermissions)
 		protected.POST("/rooms/ban-member", roomHandler.BanMember)
 		protected.POST("/rooms/unban-member", roomHandler.UnbanMember)
+		protected.POST("/rooms/mute-member", roomHandler.MuteMember)
+		protected.POST("/rooms/unmute-member", roomHandler.UnmuteMember)
 		protected.POST("/rooms/send-message", roomHandler.SendMessage)
 		protected.DELETE("/rooms/delete-message", roomHandler.DeleteMessage)
+		protected.GET("/rooms/search", roomHandler.SearchRooms)
+		protected.GET("/rooms/by-username/:username", roomHandler.GetRoomByUsername)
+		protected.GET("/rooms/:roomID", roomHandler.GetRoom)
+		protected.POST("/rooms/:roomID/join", roomHandler.JoinRoom)
+		protected.POST("/rooms/:roomID/transfer-ownership", roomHandler.TransferOwnership)
 		protected.GET("/rooms/:roomID/messages", roomHandler.GetMessages)
+		protected.POST("/rooms/:roomID/forward", forwardHandler.ForwardToRoom)
 		protected.GET("/rooms/:roomID/members", roomHandler.GetMembers)
+		protected.GET("/rooms/:roomID/audit-log", auditHandler.GetAuditLog)
+
+		// Pinned message endpoints.
+		protected.GET("/conversations/:id/pins", pinHandler.ListPins)
+		protected.POST("/conversations/:id/pins", pinHandler.PinMessage)
+		protected.PUT("/conversations/:id/pins", pinHandler.ReorderPins)
+		protected.DELETE("/conversations/:id/pins/:messageID", pinHandler.UnpinMessage)
+		protected.GET("/rooms/:roomID/pins", pinHandler.ListPins)
+		protected.POST("/rooms/:roomID/pins", pinHandler.PinMessage)
+		protected.PUT("/rooms/:roomID/pins", pinHandler.ReorderPins)
+		protected.DELETE("/rooms/:roomID/pins/:messageID", pinHandler.UnpinMessage)
+
+		// Poll endpoints.
+		protected.POST("/rooms/:roomID/polls", pollHandler.CreatePoll)
+		protected.GET("/rooms/:roomID/polls/updates", pollHandler.StreamUpdates)
+		protected.GET("/rooms/:roomID/polls/:messageID", pollHandler.GetPoll)
+		protected.PUT("/rooms/:roomID/polls/:messageID/votes", pollHandler.Vote)
+		protected.DELETE("/rooms/:roomID/polls/:messageID/votes", pollHandler.RetractVote)
+		protected.POST("/rooms/:roomID/polls/:messageID/close", pollHandler.ClosePoll)
+
+		// Invite link endpoints.
+		protected.POST("/rooms/:roomID/invites", inviteHandler.CreateInvite)
+		protected.GET("/rooms/:roomID/invites", inviteHandler.ListInvites)
+		protected.DELETE("/rooms/:roomID/invites/:token", inviteHandler.RevokeInvite)
+		protected.POST("/invites/:token/accept", inviteHandler.AcceptInvite)
+
+		// Join request endpoints.
+		protected.POST("/rooms/:roomID/join-requests", joinRequestHandler.RequestToJoin)
+		protected.GET("/rooms/:roomID/join-requests", joinRequestHandler.ListPending)
+		protected.POST("/rooms/:roomID/join-requests/:requestID/approve", joinRequestHandler.Approve)
+		protected.POST("/rooms/:roomID/join-requests/:requestID/decline", joinRequestHandler.Decline)
+
+		// Saved message endpoints.
+		protected.GET("/bookmarks", bookmarkHandler.ListBookmarks)
+		protected.POST("/bookmarks", bookmarkHandler.AddBookmark)
+		protected.PUT("/bookmarks/:bookmarkID", bookmarkHandler.UpdateBookmark)
+		protected.DELETE("/bookmarks/:bookmarkID", bookmarkHandler.RemoveBookmark)
+
+		// Notification endpoints.
+		protected.GET("/notifications", notificationHandler.ListNotifications)
+		protected.POST("/notifications/:id/read", notificationHandler.MarkRead)
+
+		// Search endpoints.
+		protected.GET("/search/messages", searchHandler.SearchMessages)
 	}
 
 	return r